@@ -0,0 +1,222 @@
+package audiofile
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/lehatrutenb/goresampler"
+)
+
+// aiffMetaChunks maps text chunks of AIFF to Header.Metadata keys
+var aiffMetaChunks = map[string]string{
+	"NAME": MetaName,
+	"AUTH": MetaAuthor,
+	"(c) ": MetaCopyright,
+	"ANNO": MetaComment,
+}
+
+// aifcCompressions maps sample formats AIFF-C stores to their compression type
+var aifcCompressions = map[goresampler.SampleFormat]string{
+	goresampler.SampleFormatS16LE: "sowt",
+	goresampler.SampleFormatMuLaw: "ulaw",
+	goresampler.SampleFormatALaw:  "alaw",
+}
+
+// aifcVersion is timestamp of AIFF-C version 1 (FVER chunk)
+const aifcVersion = 0xA2805140
+
+func (d *Decoder) readAIFF(r io.Reader) error {
+	var form [12]byte
+	if _, err := io.ReadFull(r, form[:]); err != nil {
+		return err
+	}
+	isAIFC := string(form[8:12]) == "AIFC"
+
+	commRead := false
+	for {
+		c, err := readChunk(r, binary.BigEndian)
+		if err != nil {
+			return err
+		}
+		switch {
+		case c.id == "COMM":
+			body, err := c.readBody(r)
+			if err != nil {
+				return err
+			}
+			if err = d.parseAIFFComm(body, isAIFC); err != nil {
+				return err
+			}
+			commRead = true
+		case aiffMetaChunks[c.id] != "":
+			body, err := c.readBody(r)
+			if err != nil {
+				return err
+			}
+			key := aiffMetaChunks[c.id]
+			if prev, ok := d.Metadata[key]; ok { // there can be several annotations
+				body = []byte(prev + "\n" + string(body))
+			}
+			d.Metadata[key] = cString(body)
+		case c.id == "SSND":
+			if !commRead || c.size < 8 {
+				return ErrBadHeader
+			}
+			var hdr [8]byte
+			if _, err := io.ReadFull(r, hdr[:]); err != nil {
+				return err
+			}
+			offset := binary.BigEndian.Uint32(hdr[:])
+			if offset > c.size-8 {
+				return ErrBadHeader
+			}
+			if _, err := io.CopyN(io.Discard, r, int64(offset)); err != nil {
+				return err
+			}
+			d.setData(r, int64(c.size-8-offset))
+			return nil
+		default:
+			if err := c.skip(r); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (d *Decoder) parseAIFFComm(body []byte, isAIFC bool) error {
+	if len(body) < 18 || (isAIFC && len(body) < 22) {
+		return ErrBadHeader
+	}
+	d.Channels = int(binary.BigEndian.Uint16(body))
+	bits := binary.BigEndian.Uint16(body[6:])
+	rate := decodeExtended(body[8:18])
+	if !(rate >= 1 && rate <= math.MaxInt32) { // NaN is not int
+		return ErrBadHeader
+	}
+	d.Rate = int(math.Round(rate))
+
+	compression := "NONE"
+	if isAIFC {
+		compression = string(body[18:22])
+	}
+	switch strings.ToLower(compression) {
+	case "none", "twos":
+		if bits != 16 {
+			return ErrUnsupportedFormat
+		}
+		d.Format = goresampler.SampleFormatS16BE
+	case "sowt":
+		if bits != 16 {
+			return ErrUnsupportedFormat
+		}
+		d.Format = goresampler.SampleFormatS16LE
+	case "ulaw":
+		d.Format = goresampler.SampleFormatMuLaw
+	case "alaw":
+		d.Format = goresampler.SampleFormatALaw
+	default:
+		return ErrUnsupportedFormat
+	}
+	return nil
+}
+
+func (e *Encoder) writeAIFF() (func() error, error) {
+	compression, isAIFC := aifcCompressions[e.h.Format]
+	if !isAIFC && e.h.Format != goresampler.SampleFormatS16BE {
+		return nil, ErrUnsupportedFormat
+	}
+	be := binary.BigEndian
+
+	form := []byte("FORM\x00\x00\x00\x00AIFF")
+	if isAIFC {
+		copy(form[8:], "AIFC")
+	}
+	if _, err := e.w.Write(form); err != nil {
+		return nil, err
+	}
+	if isAIFC {
+		if err := writeChunk(e.w, be, "FVER", be.AppendUint32(nil, aifcVersion)); err != nil {
+			return nil, err
+		}
+	}
+
+	comm := make([]byte, 18, 24)
+	be.PutUint16(comm, uint16(e.h.Channels))
+	be.PutUint16(comm[6:], 16)
+	encodeExtended(comm[8:], float64(e.h.Rate))
+	if isAIFC {
+		comm = append(comm, compression...)
+		comm = append(comm, 0, 0) // empty name of compression (pascal string padded to even len)
+	}
+	commPos, err := e.pos()
+	if err != nil {
+		return nil, err
+	}
+	if err := writeChunk(e.w, be, "COMM", comm); err != nil {
+		return nil, err
+	}
+
+	for _, id := range []string{"NAME", "AUTH", "(c) ", "ANNO"} {
+		if text, ok := e.h.Metadata[aiffMetaChunks[id]]; ok {
+			if err := writeChunk(e.w, be, id, []byte(text)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	ssndPos, err := e.pos()
+	if err != nil {
+		return nil, err
+	}
+	if err := writeChunk(e.w, be, "SSND", make([]byte, 8)); err != nil { // offset and block size are 0
+		return nil, err
+	}
+
+	return func() error {
+		if err := e.padData(); err != nil {
+			return err
+		}
+		end, err := e.pos()
+		if err != nil {
+			return err
+		}
+		if err := e.patchAt(4, be, uint32(end-8)); err != nil {
+			return err
+		}
+		if err := e.patchAt(commPos+8+2, be, uint32(e.frames())); err != nil {
+			return err
+		}
+		return e.patchAt(ssndPos+4, be, uint32(e.written+8))
+	}, nil
+}
+
+// decodeExtended decodes 80 bit IEEE 754 extended precision float (sample rate of AIFF)
+func decodeExtended(b []byte) float64 {
+	exp := int(binary.BigEndian.Uint16(b) & 0x7FFF)
+	mantissa := binary.BigEndian.Uint64(b[2:])
+	res := math.Ldexp(float64(mantissa), exp-16383-63)
+	if b[0]&0x80 != 0 {
+		return -res
+	}
+	return res
+}
+
+// encodeExtended encodes not negative integer x to 80 bit IEEE 754 extended precision float
+func encodeExtended(b []byte, x float64) {
+	for i := range b[:10] {
+		b[i] = 0
+	}
+	mantissa := uint64(x)
+	if mantissa == 0 {
+		return
+	}
+	exp := 16383 + 63
+	for mantissa&(1<<63) == 0 {
+		mantissa <<= 1
+		exp--
+	}
+	binary.BigEndian.PutUint16(b, uint16(exp))
+	binary.BigEndian.PutUint64(b[2:], mantissa)
+}
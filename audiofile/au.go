@@ -0,0 +1,83 @@
+package audiofile
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/lehatrutenb/goresampler"
+)
+
+const (
+	auHeaderSize  = 24
+	auUnknownSize = 0xFFFFFFFF
+)
+
+// auEncodings maps sample formats .au stores to their encoding
+var auEncodings = map[goresampler.SampleFormat]uint32{
+	goresampler.SampleFormatMuLaw: 1,
+	goresampler.SampleFormatS16BE: 3,
+	goresampler.SampleFormatALaw:  27,
+}
+
+func (d *Decoder) readAU(r io.Reader) error {
+	var hdr [auHeaderSize]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return err
+	}
+	be := binary.BigEndian
+	offset, size, encoding := be.Uint32(hdr[4:]), be.Uint32(hdr[8:]), be.Uint32(hdr[12:])
+	d.Rate, d.Channels = int(be.Uint32(hdr[16:])), int(be.Uint32(hdr[20:]))
+	if offset < auHeaderSize || offset-auHeaderSize > maxMetaChunkSize {
+		return ErrBadHeader
+	}
+
+	annotation := make([]byte, offset-auHeaderSize)
+	if _, err := io.ReadFull(r, annotation); err != nil {
+		return err
+	}
+	if text := cString(annotation); text != "" {
+		d.Metadata[MetaComment] = text
+	}
+
+	d.Format = -1
+	for f, enc := range auEncodings {
+		if enc == encoding {
+			d.Format = f
+		}
+	}
+	if d.Format < 0 {
+		return ErrUnsupportedFormat
+	}
+
+	if size == auUnknownSize {
+		d.setData(r, -1)
+	} else {
+		d.setData(r, int64(size))
+	}
+	return nil
+}
+
+func (e *Encoder) writeAU() (func() error, error) {
+	encoding, ok := auEncodings[e.h.Format]
+	if !ok {
+		return nil, ErrUnsupportedFormat
+	}
+	annotation := []byte(e.h.Metadata[MetaComment])
+	annotation = append(annotation, make([]byte, 8-len(annotation)%8)...) // zero terminated, header len is multiple of 8
+
+	be := binary.BigEndian
+	hdr := make([]byte, auHeaderSize, auHeaderSize+len(annotation))
+	copy(hdr, ".snd")
+	be.PutUint32(hdr[4:], uint32(auHeaderSize+len(annotation)))
+	be.PutUint32(hdr[8:], auUnknownSize)
+	be.PutUint32(hdr[12:], encoding)
+	be.PutUint32(hdr[16:], uint32(e.h.Rate))
+	be.PutUint32(hdr[20:], uint32(e.h.Channels))
+	if _, err := e.w.Write(append(hdr, annotation...)); err != nil {
+		return nil, err
+	}
+
+	return func() error {
+		return e.patchAt(8, be, uint32(e.written))
+	}, nil
+}
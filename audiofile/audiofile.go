@@ -0,0 +1,388 @@
+// Package audiofile reads and writes audio containers (WAV, AIFF, Sun .au) as streams of sample bytes
+// in goresampler.SampleFormat - so they can be resampled by goresampler.NewReader/NewWriter
+//
+//	dec, err := audiofile.NewDecoder(in) // container is detected by magic bytes
+//	rsm, _, err := goresampler.New(dec.Rate, 16000, goresampler.WithChannels(dec.Channels))
+//	r, err := goresampler.NewReader(dec, goresampler.NewResampleBatch(rsm, dec.Rate, 16000), dec.Format, dec.Format)
+//	h := dec.Header
+//	h.Rate = 16000
+//	enc, err := audiofile.NewEncoder(out, h)
+//	_, err = io.Copy(enc, r)
+//	err = enc.Close()
+package audiofile
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"path/filepath"
+	"strings"
+
+	"github.com/lehatrutenb/goresampler"
+)
+
+var (
+	// ErrUnknownContainer indicates that stream doesn't start with magic bytes of known container
+	ErrUnknownContainer = errors.New("got unknown audio container")
+	// ErrUnsupportedFormat indicates that container stores samples in format that has no goresampler.SampleFormat
+	// (or container can't store samples in given format)
+	ErrUnsupportedFormat = errors.New("got unsupported sample format of audio container")
+	// ErrBadHeader indicates that container header is malformed
+	ErrBadHeader = errors.New("got malformed audio container header")
+	// ErrClosed indicates that Encoder is written after Close
+	ErrClosed = errors.New("audio file encoder is closed")
+)
+
+// Container describes type of audio file
+type Container int
+
+const (
+	ContainerWAV  Container = iota + 1 // RIFF WAVE
+	ContainerAIFF                      // AIFF or AIFF-C
+	ContainerAU                        // Sun/NeXT .au (.snd)
+)
+
+func (c Container) String() string {
+	switch c {
+	case ContainerWAV:
+		return "wav"
+	case ContainerAIFF:
+		return "aiff"
+	case ContainerAU:
+		return "au"
+	default:
+		return "Undefined"
+	}
+}
+
+// Supports returns true if c can store samples in format f
+func (c Container) Supports(f goresampler.SampleFormat) bool {
+	switch c {
+	case ContainerWAV:
+		_, ok := wavFormats[f]
+		return ok
+	case ContainerAIFF:
+		_, ok := aifcCompressions[f]
+		return ok || f == goresampler.SampleFormatS16BE
+	case ContainerAU:
+		_, ok := auEncodings[f]
+		return ok
+	}
+	return false
+}
+
+// Keys of Header.Metadata that are mapped to own fields of every container
+const (
+	MetaName      = "name"
+	MetaAuthor    = "author"
+	MetaCopyright = "copyright"
+	MetaComment   = "comment"
+)
+
+// Header describes audio file
+type Header struct {
+	Container Container
+	Rate      int
+	Channels  int
+	Format    goresampler.SampleFormat // format of sample bytes (Decoder.Read gives them, Encoder.Write gets them)
+	DataSize  int64                    // amt of bytes of samples (-1 if container doesn't tell it)
+	Metadata  map[string]string        // text fields (see Meta consts) ; fields container can't store are dropped on write
+
+	ChannelMask uint32 // speaker positions of channels (WAVE_FORMAT_EXTENSIBLE dwChannelMask, 0 - not set) ; only WAV stores it
+	Cues        []Cue  // markers ; only WAV stores them
+}
+
+// Cue is marker at frame of audio (WAV cue point with its label)
+type Cue struct {
+	ID    uint32
+	Frame int64 // ind of sample frame (sample of every channel) marker points to
+	Label string
+}
+
+// Resampled returns h of same audio resampled to rate - cue points are moved to same time in frames of rate
+// (DataSize is set to -1 as it's not known)
+func (h Header) Resampled(rate int) Header {
+	res := h
+	res.Rate, res.DataSize = rate, -1
+	if h.Cues != nil && h.Rate > 0 {
+		res.Cues = make([]Cue, len(h.Cues))
+		for i, c := range h.Cues {
+			c.Frame = int64(math.Round(float64(c.Frame) * float64(rate) / float64(h.Rate)))
+			res.Cues[i] = c
+		}
+	}
+	return res
+}
+
+// WithChannels returns h of same audio converted to chAmt channels - channel mask is kept only if chAmt is same
+// (for mono and stereo standard one is set then)
+func (h Header) WithChannels(chAmt int) Header {
+	if chAmt == h.Channels {
+		return h
+	}
+	res := h
+	res.Channels, res.ChannelMask = chAmt, 0
+	if h.ChannelMask != 0 && chAmt <= 2 {
+		res.ChannelMask = standardChannelMasks[chAmt]
+	}
+	return res
+}
+
+// standardChannelMasks are masks of mono (front center) and stereo (front left and right) waves
+var standardChannelMasks = map[int]uint32{1: 0x4, 2: 0x3}
+
+// headSize is amt of bytes Detect needs
+const headSize = 12
+
+// Detect returns container of file that starts with head (at least 12 bytes are needed)
+func Detect(head []byte) (Container, error) {
+	if len(head) < headSize {
+		return 0, ErrUnknownContainer
+	}
+	switch {
+	case string(head[:4]) == "RIFF" && string(head[8:12]) == "WAVE":
+		return ContainerWAV, nil
+	case string(head[:4]) == "FORM" && (string(head[8:12]) == "AIFF" || string(head[8:12]) == "AIFC"):
+		return ContainerAIFF, nil
+	case string(head[:4]) == ".snd":
+		return ContainerAU, nil
+	}
+	return 0, ErrUnknownContainer
+}
+
+// ContainerOfExt returns container by extension of file name (like "out.aif")
+func ContainerOfExt(fName string) (Container, error) {
+	switch strings.ToLower(filepath.Ext(fName)) {
+	case ".wav", ".wave":
+		return ContainerWAV, nil
+	case ".aif", ".aiff", ".aifc":
+		return ContainerAIFF, nil
+	case ".au", ".snd":
+		return ContainerAU, nil
+	}
+	return 0, ErrUnknownContainer
+}
+
+// Decoder reads header of audio file and then gives its sample bytes by Read
+type Decoder struct {
+	Header
+	data io.Reader
+}
+
+// NewDecoder detects container of r by magic bytes and reads its header
+//
+// returns ErrUnknownContainer, ErrUnsupportedFormat or ErrBadHeader if r can't be decoded
+//
+// if r is io.ReadSeeker chunks after samples are read too (like WAV cue points that are often written at the end)
+func NewDecoder(r io.Reader) (*Decoder, error) {
+	head, src, err := peekHead(r)
+	if err != nil {
+		return nil, err
+	}
+	c, err := Detect(head)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Decoder{Header: Header{Container: c, Metadata: map[string]string{}}}
+	switch c {
+	case ContainerWAV:
+		err = d.readWAV(src)
+	case ContainerAIFF:
+		err = d.readAIFF(src)
+	case ContainerAU:
+		err = d.readAU(src)
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, ErrBadHeader
+	}
+	if err == nil && (d.Channels <= 0 || d.Rate <= 0) { // users divide by them
+		return nil, ErrBadHeader
+	}
+	return d, err
+}
+
+// peekHead returns first bytes of r for Detect and reader to read r from start
+func peekHead(r io.Reader) ([]byte, io.Reader, error) {
+	if rs, ok := r.(io.ReadSeeker); ok {
+		head := make([]byte, headSize)
+		n, err := io.ReadFull(rs, head)
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, nil, err
+		}
+		_, err = rs.Seek(int64(-n), io.SeekCurrent)
+		return head[:n], rs, err
+	}
+
+	br := bufio.NewReader(r)
+	head, err := br.Peek(headSize)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, nil, err
+	}
+	return head, br, nil
+}
+
+// Read reads sample bytes (interleaved channels in Format)
+func (d *Decoder) Read(p []byte) (int, error) {
+	return d.data.Read(p)
+}
+
+// setData sets data of d to size bytes of r (rest of r if size < 0)
+func (d *Decoder) setData(r io.Reader, size int64) {
+	d.DataSize, d.data = size, r
+	if size >= 0 {
+		d.data = io.LimitReader(r, size)
+	}
+}
+
+// Encoder writes header of audio file and then sample bytes given to Write
+//
+// sizes in header are set by Close - so it must be called at the end
+type Encoder struct {
+	h       Header
+	w       io.WriteSeeker
+	start   int64 // offset of file start in w
+	written int64 // amt of written sample bytes
+	finish  func() error
+	closed  bool
+}
+
+// NewEncoder writes header of file h describes to w (h.DataSize is ignored)
+//
+// returns ErrUnsupportedFormat if container of h can't store h.Format, ErrBadHeader if h has wrong values
+func NewEncoder(w io.WriteSeeker, h Header) (*Encoder, error) {
+	if h.Rate <= 0 || h.Channels <= 0 || h.Channels > 0xFFFF {
+		return nil, ErrBadHeader
+	}
+	start, err := w.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	e := &Encoder{h: h, w: w, start: start}
+	switch h.Container {
+	case ContainerWAV:
+		e.finish, err = e.writeWAV()
+	case ContainerAIFF:
+		e.finish, err = e.writeAIFF()
+	case ContainerAU:
+		e.finish, err = e.writeAU()
+	default:
+		return nil, ErrUnknownContainer
+	}
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Write writes sample bytes (interleaved channels in Format of header)
+func (e *Encoder) Write(p []byte) (int, error) {
+	if e.closed {
+		return 0, ErrClosed
+	}
+	n, err := e.w.Write(p)
+	e.written += int64(n)
+	return n, err
+}
+
+// Close sets sizes in header (w is not closed)
+func (e *Encoder) Close() error {
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+	return e.finish()
+}
+
+// frames returns amt of written frames
+func (e *Encoder) frames() int64 {
+	return e.written / int64(e.h.Channels*e.h.Format.Size())
+}
+
+// pos returns current offset from file start
+func (e *Encoder) pos() (int64, error) {
+	cur, err := e.w.Seek(0, io.SeekCurrent)
+	return cur - e.start, err
+}
+
+// patchAt writes v to w at pos (from file start) and returns to current offset
+func (e *Encoder) patchAt(pos int64, order binary.ByteOrder, v uint32) error {
+	cur, err := e.w.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := e.w.Seek(e.start+pos, io.SeekStart); err != nil {
+		return err
+	}
+	if err := binary.Write(e.w, order, v); err != nil {
+		return err
+	}
+	_, err = e.w.Seek(cur, io.SeekStart)
+	return err
+}
+
+// padData writes zero byte if written data has odd len (chunks of RIFF and IFF have even len)
+func (e *Encoder) padData() error {
+	if e.written%2 == 0 {
+		return nil
+	}
+	_, err := e.w.Write([]byte{0})
+	return err
+}
+
+// chunk is RIFF (WAV) or IFF (AIFF) chunk
+type chunk struct {
+	id   string
+	size uint32
+}
+
+func readChunk(r io.Reader, order binary.ByteOrder) (chunk, error) {
+	var hdr [8]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return chunk{}, err
+	}
+	return chunk{string(hdr[:4]), order.Uint32(hdr[4:])}, nil
+}
+
+// readBody reads body of c (if it's not too large for metadata chunk) skipping its padding
+func (c chunk) readBody(r io.Reader) ([]byte, error) {
+	if c.size > maxMetaChunkSize {
+		return nil, ErrBadHeader
+	}
+	body := make([]byte, c.size)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	_, err := io.CopyN(io.Discard, r, int64(c.size&1))
+	return body, err
+}
+
+func (c chunk) skip(r io.Reader) error {
+	_, err := io.CopyN(io.Discard, r, int64(c.size)+int64(c.size&1))
+	return err
+}
+
+// maxMetaChunkSize is max size of chunks read to memory (format and metadata ones)
+const maxMetaChunkSize = 1 << 20
+
+func writeChunk(w io.Writer, order binary.ByteOrder, id string, body []byte) error {
+	hdr := make([]byte, 8, 8+len(body)+1)
+	copy(hdr, id)
+	order.PutUint32(hdr[4:], uint32(len(body)))
+	hdr = append(hdr, body...)
+	if len(body)%2 == 1 {
+		hdr = append(hdr, 0)
+	}
+	_, err := w.Write(hdr)
+	return err
+}
+
+// cString returns b till first zero byte
+func cString(b []byte) string {
+	if i := strings.IndexByte(string(b), 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(b)
+}
@@ -0,0 +1,294 @@
+package audiofile_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/lehatrutenb/goresampler"
+	"github.com/lehatrutenb/goresampler/audiofile"
+
+	"github.com/go-audio/wav"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeSeeker is in memory io.WriteSeeker
+type writeSeeker struct {
+	buf []byte
+	pos int
+}
+
+func (ws *writeSeeker) Write(p []byte) (int, error) {
+	if need := ws.pos + len(p); need > len(ws.buf) {
+		ws.buf = append(ws.buf, make([]byte, need-len(ws.buf))...)
+	}
+	ws.pos += copy(ws.buf[ws.pos:], p)
+	return len(p), nil
+}
+
+func (ws *writeSeeker) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+		ws.pos = int(offset)
+	case io.SeekCurrent:
+		ws.pos += int(offset)
+	case io.SeekEnd:
+		ws.pos = len(ws.buf) + int(offset)
+	}
+	if ws.pos < 0 {
+		return 0, errors.New("negative position")
+	}
+	return int64(ws.pos), nil
+}
+
+func sinWave(amt int) []int16 {
+	res := make([]int16, amt)
+	for i := range res {
+		res[i] = int16(10000 * math.Sin(float64(i)/10))
+	}
+	return res
+}
+
+func encode(t *testing.T, h audiofile.Header, data []byte) []byte {
+	ws := &writeSeeker{}
+	enc, err := audiofile.NewEncoder(ws, h)
+	if !assert.NoError(t, err) {
+		return nil
+	}
+	_, err = enc.Write(data)
+	assert.NoError(t, err)
+	assert.NoError(t, enc.Close())
+	assert.NoError(t, enc.Close())
+	_, err = enc.Write(data)
+	assert.ErrorIs(t, err, audiofile.ErrClosed)
+	return ws.buf
+}
+
+// written file must be read same
+func TestEncoderDecoder(t *testing.T) {
+	for _, tt := range []struct {
+		c audiofile.Container
+		f goresampler.SampleFormat
+	}{
+		{audiofile.ContainerWAV, goresampler.SampleFormatS16LE},
+		{audiofile.ContainerWAV, goresampler.SampleFormatF32LE},
+		{audiofile.ContainerWAV, goresampler.SampleFormatMuLaw},
+		{audiofile.ContainerWAV, goresampler.SampleFormatALaw},
+		{audiofile.ContainerAIFF, goresampler.SampleFormatS16BE},
+		{audiofile.ContainerAIFF, goresampler.SampleFormatS16LE},
+		{audiofile.ContainerAIFF, goresampler.SampleFormatMuLaw},
+		{audiofile.ContainerAIFF, goresampler.SampleFormatALaw},
+		{audiofile.ContainerAU, goresampler.SampleFormatS16BE},
+		{audiofile.ContainerAU, goresampler.SampleFormatMuLaw},
+		{audiofile.ContainerAU, goresampler.SampleFormatALaw},
+	} {
+		for _, chAmt := range []int{1, 2} {
+			meta := map[string]string{audiofile.MetaComment: "test wave"}
+			if tt.c == audiofile.ContainerAIFF {
+				meta[audiofile.MetaName], meta[audiofile.MetaAuthor], meta[audiofile.MetaCopyright] = "sin", "me", "2025"
+			}
+			h := audiofile.Header{Container: tt.c, Rate: 44100, Channels: chAmt, Format: tt.f, Metadata: meta}
+			data := make([]byte, 1001*chAmt*tt.f.Size()) // odd amt of frames
+			assert.NoError(t, tt.f.Encode(data, sinWave(1001*chAmt)))
+			assert.True(t, tt.c.Supports(tt.f))
+			file := encode(t, h, data)
+
+			c, err := audiofile.Detect(file)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.c, c)
+
+			dec, err := audiofile.NewDecoder(bytes.NewReader(file))
+			if !assert.NoError(t, err, "%s %s", tt.c, tt.f) {
+				continue
+			}
+			got, err := io.ReadAll(dec)
+			assert.NoError(t, err)
+			assert.Equal(t, data, got, "%s %s", tt.c, tt.f)
+
+			h.DataSize = int64(len(data))
+			assert.Equal(t, h, dec.Header, "%s %s", tt.c, tt.f)
+		}
+	}
+}
+
+// WAV INFO texts, cue points with labels and channel mask must be kept - also if chunks are written after samples
+func TestEncoderDecoder_WAVMeta(t *testing.T) {
+	h := audiofile.Header{Container: audiofile.ContainerWAV, Rate: 48000, Channels: 6, Format: goresampler.SampleFormatS16LE,
+		Metadata:    map[string]string{audiofile.MetaName: "sin", audiofile.MetaComment: "5.1", "ICRD": "2025"},
+		ChannelMask: 0x3F, Cues: []audiofile.Cue{{ID: 1, Frame: 10, Label: "start"}, {ID: 2, Frame: 99}}}
+	data := make([]byte, 100*6*2)
+	assert.NoError(t, h.Format.Encode(data, sinWave(100*6)))
+	file := encode(t, h, data)
+	h.DataSize = int64(len(data))
+
+	cuePos, dataPos := bytes.Index(file, []byte("cue ")), bytes.Index(file, []byte("data\xb0\x04\x00\x00")) // cue points have "data" too
+	if !assert.True(t, cuePos > 0 && dataPos > cuePos) {
+		return
+	}
+	tail := append(append(append([]byte{}, file[:cuePos]...), file[dataPos:]...), file[cuePos:dataPos]...)
+
+	for name, r := range map[string]io.Reader{"meta before data": bytes.NewReader(file), "meta after data": bytes.NewReader(tail)} {
+		dec, err := audiofile.NewDecoder(r)
+		if !assert.NoError(t, err, name) {
+			continue
+		}
+		assert.Equal(t, h, dec.Header, name)
+		got, err := io.ReadAll(dec)
+		assert.NoError(t, err, name)
+		assert.Equal(t, data, got, name)
+	}
+
+	dec, err := audiofile.NewDecoder(io.MultiReader(bytes.NewReader(tail))) // not seeker - chunks after data are not read
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Nil(t, dec.Cues)
+	assert.Equal(t, uint32(0x3F), dec.ChannelMask)
+	got, err := io.ReadAll(dec)
+	assert.NoError(t, err)
+	assert.Equal(t, data, got)
+}
+
+func TestHeader_ResampledWithChannels(t *testing.T) {
+	h := audiofile.Header{Container: audiofile.ContainerWAV, Rate: 44100, Channels: 2, DataSize: 100, ChannelMask: 0x3,
+		Cues: []audiofile.Cue{{ID: 1, Frame: 44100, Label: "1s"}, {ID: 2, Frame: 3}}}
+	got := h.Resampled(16000)
+	assert.Equal(t, []audiofile.Cue{{ID: 1, Frame: 16000, Label: "1s"}, {ID: 2, Frame: 1}}, got.Cues)
+	assert.Equal(t, int64(-1), got.DataSize)
+	assert.Equal(t, int64(44100), h.Cues[0].Frame, "h must not be changed")
+
+	assert.Equal(t, h, h.WithChannels(2))
+	assert.Equal(t, uint32(0x4), h.WithChannels(1).ChannelMask)
+	assert.Equal(t, uint32(0), h.WithChannels(4).ChannelMask)
+	h.ChannelMask = 0
+	assert.Equal(t, uint32(0), h.WithChannels(1).ChannelMask)
+}
+
+// WAV files must be readable by other libs
+func TestEncoder_WAVCompatible(t *testing.T) {
+	wave := sinWave(2 * 4410)
+	data := make([]byte, 2*len(wave))
+	assert.NoError(t, goresampler.SampleFormatS16LE.Encode(data, wave))
+	file := encode(t, audiofile.Header{Container: audiofile.ContainerWAV, Rate: 44100, Channels: 2, Format: goresampler.SampleFormatS16LE}, data)
+
+	dec := wav.NewDecoder(bytes.NewReader(file))
+	buf, err := dec.FullPCMBuffer()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 44100, buf.Format.SampleRate)
+	assert.Equal(t, 2, buf.Format.NumChannels)
+	got := make([]int16, len(buf.Data))
+	for i, x := range buf.Data {
+		got[i] = int16(x)
+	}
+	assert.Equal(t, wave, got)
+}
+
+func TestEncoder_AIFFHeader(t *testing.T) {
+	file := encode(t, audiofile.Header{Container: audiofile.ContainerAIFF, Rate: 44100, Channels: 1, Format: goresampler.SampleFormatS16BE}, []byte{1, 2, 3, 4})
+	assert.Equal(t, []byte("FORM\x00\x00\x00\x32AIFFCOMM\x00\x00\x00\x12"), file[:20])
+	assert.Equal(t, []byte{0, 1, 0, 0, 0, 2, 0, 16, 0x40, 0x0E, 0xAC, 0x44, 0, 0, 0, 0, 0, 0}, file[20:38], "1 channel, 2 frames, 16 bit, 44100 rate")
+	assert.Equal(t, []byte("SSND\x00\x00\x00\x0c\x00\x00\x00\x00\x00\x00\x00\x00\x01\x02\x03\x04"), file[38:])
+}
+
+// .au written by streams without size must be read till end
+func TestDecoder_AUUnknownSize(t *testing.T) {
+	file := []byte(".snd\x00\x00\x00\x18\xff\xff\xff\xff\x00\x00\x00\x03\x00\x00\x1f\x40\x00\x00\x00\x01\x01\x02\x03\x04")
+	dec, err := audiofile.NewDecoder(bytes.NewReader(file))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, audiofile.Header{Container: audiofile.ContainerAU, Rate: 8000, Channels: 1, Format: goresampler.SampleFormatS16BE,
+		DataSize: -1, Metadata: map[string]string{}}, dec.Header)
+	got, err := io.ReadAll(dec)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{1, 2, 3, 4}, got)
+}
+
+func TestDecoderEncoder_Errors(t *testing.T) {
+	_, err := audiofile.NewDecoder(bytes.NewReader([]byte("OggS\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00")))
+	assert.ErrorIs(t, err, audiofile.ErrUnknownContainer)
+	_, err = audiofile.NewDecoder(bytes.NewReader([]byte("RIFF")))
+	assert.ErrorIs(t, err, audiofile.ErrUnknownContainer)
+
+	file := encode(t, audiofile.Header{Container: audiofile.ContainerWAV, Rate: 8000, Channels: 1, Format: goresampler.SampleFormatS16LE}, make([]byte, 10))
+	for l := 12; l < 44; l++ { // cut header
+		_, err = audiofile.NewDecoder(bytes.NewReader(file[:l]))
+		assert.ErrorIs(t, err, audiofile.ErrBadHeader, "len %d", l)
+	}
+	file[20] = 2 // ADPCM
+	_, err = audiofile.NewDecoder(bytes.NewReader(file))
+	assert.ErrorIs(t, err, audiofile.ErrUnsupportedFormat)
+
+	for _, tc := range []struct {
+		c        audiofile.Container
+		f        goresampler.SampleFormat
+		from, to int // bytes of header field zeroed
+		field    string
+	}{
+		{audiofile.ContainerWAV, goresampler.SampleFormatS16LE, 22, 24, "channels"},
+		{audiofile.ContainerWAV, goresampler.SampleFormatS16LE, 24, 28, "rate"},
+		{audiofile.ContainerAIFF, goresampler.SampleFormatS16BE, 20, 22, "channels"},
+		{audiofile.ContainerAIFF, goresampler.SampleFormatS16BE, 28, 38, "rate"},
+		{audiofile.ContainerAU, goresampler.SampleFormatS16BE, 16, 20, "rate"},
+		{audiofile.ContainerAU, goresampler.SampleFormatS16BE, 20, 24, "channels"},
+	} {
+		file := encode(t, audiofile.Header{Container: tc.c, Rate: 8000, Channels: 1, Format: tc.f}, make([]byte, 10))
+		clear(file[tc.from:tc.to])
+		_, err = audiofile.NewDecoder(bytes.NewReader(file))
+		assert.ErrorIs(t, err, audiofile.ErrBadHeader, "%v with zero %s", tc.c, tc.field)
+	}
+
+	for _, h := range []audiofile.Header{
+		{Container: audiofile.ContainerAU, Rate: 8000, Channels: 1, Format: goresampler.SampleFormatS16LE},
+		{Container: audiofile.ContainerAIFF, Rate: 8000, Channels: 1, Format: goresampler.SampleFormatF32LE},
+		{Container: audiofile.ContainerWAV, Rate: 8000, Channels: 1, Format: goresampler.SampleFormatS16BE},
+	} {
+		_, err = audiofile.NewEncoder(&writeSeeker{}, h)
+		assert.ErrorIs(t, err, audiofile.ErrUnsupportedFormat)
+		assert.False(t, h.Container.Supports(h.Format))
+	}
+	_, err = audiofile.NewEncoder(&writeSeeker{}, audiofile.Header{Container: audiofile.ContainerAU, Rate: 0, Channels: 1})
+	assert.ErrorIs(t, err, audiofile.ErrBadHeader)
+	_, err = audiofile.NewEncoder(&writeSeeker{}, audiofile.Header{Rate: 8000, Channels: 1})
+	assert.ErrorIs(t, err, audiofile.ErrUnknownContainer)
+}
+
+func TestContainerOfExt(t *testing.T) {
+	for name, want := range map[string]audiofile.Container{"a.wav": audiofile.ContainerWAV, "b/c.AIFF": audiofile.ContainerAIFF,
+		"d.aif": audiofile.ContainerAIFF, "e.au": audiofile.ContainerAU, "f.snd": audiofile.ContainerAU} {
+		c, err := audiofile.ContainerOfExt(name)
+		assert.NoError(t, err)
+		assert.Equal(t, want, c, name)
+	}
+	_, err := audiofile.ContainerOfExt("g.mp3")
+	assert.ErrorIs(t, err, audiofile.ErrUnknownContainer)
+}
+
+func ExampleNewDecoder() {
+	aiff := &writeSeeker{}
+	enc, _ := audiofile.NewEncoder(aiff, audiofile.Header{Container: audiofile.ContainerAIFF, Rate: 48000, Channels: 2, Format: goresampler.SampleFormatS16BE})
+	_, _ = enc.Write(make([]byte, 48000*2*2)) // 1 sec of stereo silence
+	_ = enc.Close()
+
+	dec, err := audiofile.NewDecoder(bytes.NewReader(aiff.buf))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	rsm, _, _ := goresampler.New(dec.Rate, 16000, goresampler.WithChannels(dec.Channels))
+	r, _ := goresampler.NewReader(dec, goresampler.NewResampleBatch(rsm, dec.Rate, 16000), dec.Format, goresampler.SampleFormatS16LE)
+
+	wave := &writeSeeker{}
+	h := dec.Header
+	h.Container, h.Rate, h.Format = audiofile.ContainerWAV, 16000, goresampler.SampleFormatS16LE
+	enc, _ = audiofile.NewEncoder(wave, h)
+	n, _ := io.Copy(enc, r)
+	_ = enc.Close()
+	fmt.Println(dec.Container, "->", h.Container, n)
+	// Output: aiff -> wav 64000
+}
@@ -0,0 +1,332 @@
+package audiofile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"sort"
+
+	"github.com/lehatrutenb/goresampler"
+)
+
+// WAVE_FORMAT tags of fmt chunk
+const (
+	wavFormatPCM        = 1
+	wavFormatFloat      = 3
+	wavFormatALaw       = 6
+	wavFormatMuLaw      = 7
+	wavFormatExtensible = 0xFFFE
+)
+
+// wavSubFormatTail is KSDATAFORMAT_SUBTYPE GUID of extensible fmt without first 2 bytes (WAVE_FORMAT tag)
+const wavSubFormatTail = "\x00\x00\x00\x00\x10\x00\x80\x00\x00\xAA\x00\x38\x9B\x71"
+
+// wavFormats maps sample formats to their WAVE_FORMAT tag and bits per sample
+var wavFormats = map[goresampler.SampleFormat][2]int{
+	goresampler.SampleFormatS16LE: {wavFormatPCM, 16},
+	goresampler.SampleFormatF32LE: {wavFormatFloat, 32},
+	goresampler.SampleFormatALaw:  {wavFormatALaw, 8},
+	goresampler.SampleFormatMuLaw: {wavFormatMuLaw, 8},
+}
+
+// wavInfoChunks maps LIST INFO chunks to Header.Metadata keys, other INFO chunks are kept by their ids (like "ICRD")
+var wavInfoChunks = map[string]string{
+	"INAM": MetaName,
+	"IART": MetaAuthor,
+	"ICOP": MetaCopyright,
+	"ICMT": MetaComment,
+}
+
+func (d *Decoder) readWAV(r io.Reader) error {
+	var riff [12]byte
+	if _, err := io.ReadFull(r, riff[:]); err != nil {
+		return err
+	}
+
+	labels := map[uint32]string{}
+	fmtRead := false
+	for {
+		c, err := readChunk(r, binary.LittleEndian)
+		if err != nil {
+			return err
+		}
+		switch c.id {
+		case "fmt ":
+			body, err := c.readBody(r)
+			if err != nil {
+				return err
+			}
+			if err = d.parseWAVFmt(body); err != nil {
+				return err
+			}
+			fmtRead = true
+		case "data":
+			if !fmtRead {
+				return ErrBadHeader
+			}
+			if rs, ok := r.(io.ReadSeeker); ok {
+				if err := d.readWAVTail(rs, c, labels); err != nil {
+					return err
+				}
+			}
+			for i := range d.Cues {
+				d.Cues[i].Label = labels[d.Cues[i].ID]
+			}
+			d.setData(r, int64(c.size))
+			return nil
+		default:
+			if err := d.readWAVMeta(r, c, labels); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// readWAVTail reads chunks after data chunk and returns to start of data
+//
+// broken chunks at the end of file are ignored (file can be cut while it's written)
+func (d *Decoder) readWAVTail(rs io.ReadSeeker, data chunk, labels map[uint32]string) error {
+	dataStart, err := rs.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := rs.Seek(int64(data.size)+int64(data.size&1), io.SeekCurrent); err != nil {
+		return err
+	}
+	for {
+		c, err := readChunk(rs, binary.LittleEndian)
+		if err != nil || c.id == "fmt " || c.id == "data" {
+			break
+		}
+		if err := d.readWAVMeta(rs, c, labels); err != nil {
+			break
+		}
+	}
+	_, err = rs.Seek(dataStart, io.SeekStart)
+	return err
+}
+
+// readWAVMeta reads metadata chunk c (LIST or cue) or skips it if it's other one
+func (d *Decoder) readWAVMeta(r io.Reader, c chunk, labels map[uint32]string) error {
+	if c.id != "LIST" && c.id != "cue " {
+		return c.skip(r)
+	}
+	body, err := c.readBody(r)
+	if err != nil {
+		return err
+	}
+	if c.id == "cue " {
+		return d.parseWAVCue(body)
+	}
+	if len(body) < 4 {
+		return ErrBadHeader
+	}
+
+	listType, sub := string(body[:4]), bytes.NewReader(body[4:])
+	for {
+		c, err := readChunk(sub, binary.LittleEndian)
+		if err != nil { // end of list (or broken sub chunk - rest is skipped)
+			return nil
+		}
+		text, err := c.readBody(sub)
+		if err != nil {
+			return nil
+		}
+		switch {
+		case listType == "INFO":
+			key, ok := wavInfoChunks[c.id]
+			if !ok {
+				key = c.id
+			}
+			d.Metadata[key] = cString(text)
+		case listType == "adtl" && c.id == "labl" && len(text) >= 4:
+			labels[binary.LittleEndian.Uint32(text)] = cString(text[4:])
+		}
+	}
+}
+
+func (d *Decoder) parseWAVCue(body []byte) error {
+	if len(body) < 4 {
+		return ErrBadHeader
+	}
+	amt := int(binary.LittleEndian.Uint32(body))
+	if len(body) < 4+amt*24 {
+		return ErrBadHeader
+	}
+	d.Cues = make([]Cue, amt)
+	for i := range d.Cues {
+		point := body[4+i*24:]
+		d.Cues[i] = Cue{ID: binary.LittleEndian.Uint32(point), Frame: int64(binary.LittleEndian.Uint32(point[20:]))}
+	}
+	return nil
+}
+
+func (d *Decoder) parseWAVFmt(body []byte) error {
+	if len(body) < 16 {
+		return ErrBadHeader
+	}
+	tag, bits := int(binary.LittleEndian.Uint16(body)), int(binary.LittleEndian.Uint16(body[14:]))
+	d.Channels, d.Rate = int(binary.LittleEndian.Uint16(body[2:])), int(binary.LittleEndian.Uint32(body[4:]))
+	if tag == wavFormatExtensible {
+		if len(body) < 40 {
+			return ErrBadHeader
+		}
+		d.ChannelMask = binary.LittleEndian.Uint32(body[20:])
+		tag = int(binary.LittleEndian.Uint16(body[24:]))
+	}
+
+	for f, tagBits := range wavFormats {
+		if tagBits == [2]int{tag, bits} {
+			d.Format = f
+			return nil
+		}
+	}
+	return ErrUnsupportedFormat
+}
+
+func (e *Encoder) writeWAV() (func() error, error) {
+	tagBits, ok := wavFormats[e.h.Format]
+	if !ok {
+		return nil, ErrUnsupportedFormat
+	}
+	le := binary.LittleEndian
+
+	if _, err := e.w.Write([]byte("RIFF\x00\x00\x00\x00WAVE")); err != nil {
+		return nil, err
+	}
+	fmtBody := e.wavFmt(tagBits[0], tagBits[1])
+	if err := writeChunk(e.w, le, "fmt ", fmtBody); err != nil {
+		return nil, err
+	}
+	factPos := int64(-1)
+	if tagBits[0] != wavFormatPCM { // not PCM files must have fact chunk with amt of frames
+		factPos = 12 + 8 + int64(len(fmtBody)) + 8 // after RIFF header, fmt chunk and fact chunk header
+		if err := writeChunk(e.w, le, "fact", make([]byte, 4)); err != nil {
+			return nil, err
+		}
+	}
+	if err := e.writeWAVMeta(); err != nil {
+		return nil, err
+	}
+
+	dataPos, err := e.pos()
+	if err != nil {
+		return nil, err
+	}
+	if err := writeChunk(e.w, le, "data", nil); err != nil {
+		return nil, err
+	}
+
+	return func() error {
+		if err := e.padData(); err != nil {
+			return err
+		}
+		end, err := e.pos()
+		if err != nil {
+			return err
+		}
+		if err := e.patchAt(4, le, uint32(end-8)); err != nil {
+			return err
+		}
+		if factPos >= 0 {
+			if err := e.patchAt(factPos, le, uint32(e.frames())); err != nil {
+				return err
+			}
+		}
+		return e.patchAt(dataPos+4, le, uint32(e.written))
+	}, nil
+}
+
+// wavFmt returns body of fmt chunk - WAVE_FORMAT_EXTENSIBLE one if header has channel mask or more than 2 channels
+func (e *Encoder) wavFmt(tag, bits int) []byte {
+	le := binary.LittleEndian
+	blockAlign := e.h.Channels * e.h.Format.Size()
+	extensible := e.h.ChannelMask != 0 || e.h.Channels > 2
+
+	body := make([]byte, 16, 40)
+	le.PutUint16(body, uint16(tag))
+	if extensible {
+		le.PutUint16(body, wavFormatExtensible)
+	}
+	le.PutUint16(body[2:], uint16(e.h.Channels))
+	le.PutUint32(body[4:], uint32(e.h.Rate))
+	le.PutUint32(body[8:], uint32(e.h.Rate*blockAlign))
+	le.PutUint16(body[12:], uint16(blockAlign))
+	le.PutUint16(body[14:], uint16(bits))
+	switch {
+	case extensible:
+		body = le.AppendUint16(body, 22) // cbSize
+		body = le.AppendUint16(body, uint16(bits))
+		body = le.AppendUint32(body, e.h.ChannelMask)
+		body = le.AppendUint16(body, uint16(tag))
+		body = append(body, wavSubFormatTail...)
+	case tag != wavFormatPCM:
+		body = le.AppendUint16(body, 0) // cbSize
+	}
+	return body
+}
+
+// writeWAVMeta writes cue points with their labels and LIST INFO chunk with metadata
+func (e *Encoder) writeWAVMeta() error {
+	le := binary.LittleEndian
+	if len(e.h.Cues) != 0 {
+		cue := le.AppendUint32(nil, uint32(len(e.h.Cues)))
+		adtl := bytes.NewBufferString("adtl")
+		for _, c := range e.h.Cues {
+			cue = le.AppendUint32(cue, c.ID)
+			cue = le.AppendUint32(cue, uint32(c.Frame)) // position in play order - same for files without playlist
+			cue = append(cue, "data"...)
+			cue = append(cue, make([]byte, 8)...) // chunk start and block start are 0 for data chunk of PCM
+			cue = le.AppendUint32(cue, uint32(c.Frame))
+			if c.Label != "" {
+				_ = writeChunk(adtl, le, "labl", append(le.AppendUint32(nil, c.ID), c.Label+"\x00"...))
+			}
+		}
+		if err := writeChunk(e.w, le, "cue ", cue); err != nil {
+			return err
+		}
+		if adtl.Len() > 4 {
+			if err := writeChunk(e.w, le, "LIST", adtl.Bytes()); err != nil {
+				return err
+			}
+		}
+	}
+
+	texts := map[string]string{}
+	for key, text := range e.h.Metadata {
+		if isWAVInfoID(key) {
+			texts[key] = text
+		}
+	}
+	for id, key := range wavInfoChunks {
+		if text, ok := e.h.Metadata[key]; ok {
+			texts[id] = text
+		}
+	}
+	if len(texts) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(texts))
+	for id := range texts {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	info := bytes.NewBufferString("INFO")
+	for _, id := range ids {
+		_ = writeChunk(info, le, id, []byte(texts[id]+"\x00"))
+	}
+	return writeChunk(e.w, le, "LIST", info.Bytes())
+}
+
+// isWAVInfoID returns true if key of Header.Metadata is id of LIST INFO chunk (like "ICRD")
+func isWAVInfoID(key string) bool {
+	if len(key) != 4 || key[0] != 'I' {
+		return false
+	}
+	for _, c := range key {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/karrick/godirwalk"
+)
+
+type batchConfig struct {
+	convertConfig
+	srcDir, dstDir string
+	pattern        string
+	workers        int
+	force          bool
+}
+
+// batchFile is summary of 1 file of batch
+type batchFile struct {
+	In        string  `json:"in"`
+	Out       string  `json:"out"`
+	Status    string  `json:"status"` // converted, skipped (output is up to date) or failed
+	Error     string  `json:"error,omitempty"`
+	InRate    int     `json:"in_rate,omitempty"`
+	OutRate   int     `json:"out_rate,omitempty"`
+	Resampler string  `json:"resampler,omitempty"` // type resampler is built with
+	Algorithm string  `json:"algorithm,omitempty"` // what really resamples
+	InSec     float64 `json:"in_sec,omitempty"`
+	OutSec    float64 `json:"out_sec,omitempty"`
+	Clipped   int64   `json:"clipped_samples,omitempty"`
+	ElapsedMs float64 `json:"elapsed_ms,omitempty"`
+
+	rel string // path relative to -dst (key of batch manifest)
+}
+
+// batchManifestName is file in -dst that keeps settings every output was converted with
+const batchManifestName = ".goresampler-batch.json"
+
+type batchSummary struct {
+	Converted int         `json:"converted"`
+	Skipped   int         `json:"skipped"`
+	Failed    int         `json:"failed"`
+	Clipped   int         `json:"clipped"` // amt of converted files with clipped samples
+	ElapsedMs float64     `json:"elapsed_ms"`
+	Files     []batchFile `json:"files"`
+}
+
+func runBatch(args []string) error {
+	fs := flag.NewFlagSet("batch", flag.ExitOnError)
+	cfg := batchConfig{}
+	fs.StringVar(&cfg.srcDir, "src", "", "dir to convert files of (walked recursively)")
+	fs.StringVar(&cfg.dstDir, "dst", "", "dir to write converted files to (structure of -src is mirrored)")
+	fs.StringVar(&cfg.pattern, "pattern", "*.wav", "glob of file names to convert")
+	fs.IntVar(&cfg.workers, "workers", runtime.NumCPU(), "amt of files converted in parallel")
+	fs.BoolVar(&cfg.force, "force", false, "convert files even if outputs are up to date (not older than inputs and converted with same settings)")
+	fs.IntVar(&cfg.rate, "rate", 16000, "output rate (0 - same as input)")
+	fs.IntVar(&cfg.channels, "channels", 0, "output channels amt (0 - same as input)")
+	fs.StringVar(&cfg.format, "format", "", "output sample format: s16le, s16be, f32le, mulaw, alaw (input one if container supports it)")
+	rsmT := fs.String("rsm", "bestfit", "resampler: const, spline, fft, bestfit, notsafe")
+	fs.BoolVar(&cfg.strict, "strict", true, "allow only well tested rate conversions")
+	calibPath := fs.String("calib", "", "calibration profile to choose resampler by (overrides -rsm ; rates it has not are measured and saved to it)")
+	fs.Float64Var(&cfg.minSNRDB, "minsnr", 60, "min snr in dB resampler chosen by -calib must have")
+	summaryPath := fs.String("summary", "-", "file to write json summary to ('-' for stdout, '' to skip)")
+	fs.Parse(args)
+
+	if cfg.srcDir == "" || cfg.dstDir == "" {
+		return errors.New("-src and -dst must be set")
+	}
+	if cfg.workers <= 0 {
+		return fmt.Errorf("workers must be positive, got %d", cfg.workers)
+	}
+	if _, err := filepath.Match(cfg.pattern, ""); err != nil {
+		return fmt.Errorf("bad pattern %q: %w", cfg.pattern, err)
+	}
+	var err error
+	if cfg.rsmT, err = parseRsmT(*rsmT); err != nil {
+		return err
+	}
+	if *calibPath != "" {
+		if cfg.calib, err = loadCalibration(*calibPath); err != nil {
+			return err
+		}
+	}
+
+	summary, err := convertDir(cfg)
+	if err != nil {
+		return err
+	}
+	if err = saveCalibration(*calibPath, cfg.calib); err != nil {
+		return err
+	}
+	if err = writeTo(*summaryPath, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(summary)
+	}); err != nil {
+		return err
+	}
+	if summary.Failed != 0 {
+		return fmt.Errorf("failed to convert %d of %d files", summary.Failed, len(summary.Files))
+	}
+	return nil
+}
+
+// convertDir converts files of cfg.srcDir matching cfg.pattern by cfg.workers goroutines
+func convertDir(cfg batchConfig) (batchSummary, error) {
+	start := time.Now()
+	summary := batchSummary{Files: make([]batchFile, 0)}
+	dstAbs, err := filepath.Abs(cfg.dstDir)
+	if err != nil {
+		return summary, err
+	}
+	err = godirwalk.Walk(cfg.srcDir, &godirwalk.Options{
+		Callback: func(path string, de *godirwalk.Dirent) error {
+			if de.IsDir() {
+				if abs, _ := filepath.Abs(path); abs == dstAbs { // dst can be inside src
+					return godirwalk.SkipThis
+				}
+				return nil
+			}
+			if ok, _ := filepath.Match(cfg.pattern, de.Name()); !ok || !de.IsRegular() {
+				return nil
+			}
+			rel, err := filepath.Rel(cfg.srcDir, path)
+			if err != nil {
+				return err
+			}
+			summary.Files = append(summary.Files, batchFile{In: path, Out: filepath.Join(cfg.dstDir, rel), rel: filepath.ToSlash(rel)})
+			return nil
+		},
+	})
+	if err != nil {
+		return summary, err
+	}
+	manifestPath := filepath.Join(cfg.dstDir, batchManifestName)
+	manifest, err := readBatchManifest(manifestPath)
+	if err != nil {
+		return summary, err
+	}
+	settings := cfg.settings()
+
+	jobs := make(chan *batchFile)
+	wg := sync.WaitGroup{}
+	for range cfg.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for f := range jobs {
+				cfg.convert(f, manifest[f.rel] == settings)
+			}
+		}()
+	}
+	for i := range summary.Files {
+		jobs <- &summary.Files[i]
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, f := range summary.Files {
+		switch f.Status {
+		case "converted":
+			manifest[f.rel] = settings
+			summary.Converted++
+			if f.Clipped != 0 {
+				summary.Clipped++
+			}
+		case "skipped":
+			summary.Skipped++
+		default:
+			summary.Failed++
+		}
+	}
+	if summary.Converted != 0 {
+		if err = writeBatchManifest(manifestPath, manifest); err != nil {
+			return summary, err
+		}
+	}
+	summary.ElapsedMs = float64(time.Since(start).Microseconds()) / 1000
+	return summary, nil
+}
+
+// settings returns description of settings output depends on - output converted with other ones is not up to date
+func (cfg convertConfig) settings() string {
+	res := fmt.Sprintf("rate=%d channels=%d container=%s format=%s rsm=%s strict=%t", cfg.rate, cfg.channels, cfg.container, cfg.format, cfg.rsmT, cfg.strict)
+	if cfg.calib != nil {
+		res += fmt.Sprintf(" calib minsnr=%g", cfg.minSNRDB)
+	}
+	return res
+}
+
+// readBatchManifest reads settings of outputs converted by previous batch runs (empty if there were no ones)
+func readBatchManifest(fName string) (map[string]string, error) {
+	manifest := make(map[string]string)
+	data, err := os.ReadFile(fName)
+	if errors.Is(err, os.ErrNotExist) {
+		return manifest, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse batch manifest %s (remove it to convert all files again): %w", fName, err)
+	}
+	return manifest, nil
+}
+
+func writeBatchManifest(fName string, manifest map[string]string) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fName, data, 0o644)
+}
+
+// convert converts f.In to f.Out (if it's not up to date) and fills f
+//
+// sameSettings tells that existing output was converted with same settings (it's not checked otherwise)
+// ; file is written under temporary name first - so broken outputs are never treated as up to date ones
+func (cfg batchConfig) convert(f *batchFile, sameSettings bool) {
+	start := time.Now()
+	if !cfg.force && sameSettings && upToDate(f.In, f.Out) {
+		f.Status = "skipped"
+		return
+	}
+
+	tmpPath := filepath.Join(filepath.Dir(f.Out), ".partial-"+filepath.Base(f.Out)) // same extension - same container
+	err := os.MkdirAll(filepath.Dir(f.Out), 0o755)
+	if err == nil {
+		convCfg := cfg.convertConfig
+		convCfg.inPath, convCfg.outPath = f.In, tmpPath
+		var stats convertStats
+		if stats, err = convertFile(convCfg); err == nil {
+			f.InRate, f.OutRate = stats.inRate, stats.outRate
+			f.Resampler, f.Algorithm = stats.info.Type.String(), stats.info.Algorithm.String()
+			f.InSec, f.OutSec, f.Clipped = stats.inSec, stats.outSec, stats.clipped
+			err = os.Rename(tmpPath, f.Out)
+		}
+	}
+	f.ElapsedMs = float64(time.Since(start).Microseconds()) / 1000
+	if err != nil {
+		os.Remove(tmpPath)
+		f.Status, f.Error = "failed", err.Error()
+		return
+	}
+	f.Status = "converted"
+}
+
+// upToDate returns true if out exists and is not older than in
+func upToDate(in, out string) bool {
+	inSt, err := os.Stat(in)
+	if err != nil {
+		return false
+	}
+	outSt, err := os.Stat(out)
+	return err == nil && !outSt.ModTime().Before(inSt.ModTime())
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lehatrutenb/goresampler"
+	"github.com/lehatrutenb/goresampler/audiofile"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeWav writes 0.1s of silence of rate to fName
+func writeWav(t *testing.T, fName string, rate int) {
+	assert.NoError(t, os.MkdirAll(filepath.Dir(fName), 0o755))
+	f, err := os.Create(fName)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer f.Close()
+	enc, err := audiofile.NewEncoder(f, audiofile.Header{Container: audiofile.ContainerWAV, Rate: rate, Channels: 1, Format: goresampler.SampleFormatS16LE})
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = enc.Write(make([]byte, rate/10*2))
+	assert.NoError(t, err)
+	assert.NoError(t, enc.Close())
+}
+
+// runs of batch on same dirs must convert only files that are not up to date
+func TestConvertDir(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	writeWav(t, filepath.Join(src, "a.wav"), 48000)
+	writeWav(t, filepath.Join(src, "sub", "b.wav"), 44100)
+	writeWav(t, filepath.Join(src, "broken.wav"), 48000) // cut in the middle of sample - fails after output is created
+	info, err := os.Stat(filepath.Join(src, "broken.wav"))
+	if assert.NoError(t, err) {
+		assert.NoError(t, os.Truncate(filepath.Join(src, "broken.wav"), info.Size()-101))
+	}
+	assert.NoError(t, os.WriteFile(filepath.Join(src, "notes.txt"), []byte("not matched"), 0o644))
+
+	cfg := batchConfig{convertConfig: convertConfig{rate: 16000, rsmT: goresampler.ResamplerBestFitT, strict: true},
+		srcDir: src, dstDir: dst, pattern: "*.wav", workers: 2}
+	for _, tt := range []struct {
+		name                       string
+		change                     func()
+		converted, skipped, failed int
+	}{
+		{"first run converts", func() {}, 2, 0, 1},
+		{"second run skips", func() {}, 0, 2, 1},
+		{"changed rate reconverts", func() { cfg.rate = 8000 }, 2, 0, 1},
+		{"same rate skips", func() {}, 0, 2, 1},
+		{"force reconverts", func() { cfg.force = true }, 2, 0, 1},
+		{"newer input reconverts", func() {
+			cfg.force = false
+			future := time.Now().Add(time.Hour)
+			assert.NoError(t, os.Chtimes(filepath.Join(src, "a.wav"), future, future))
+		}, 1, 1, 1},
+	} {
+		tt.change()
+		summary, err := convertDir(cfg)
+		if !assert.NoError(t, err, tt.name) {
+			continue
+		}
+		assert.Len(t, summary.Files, 3, tt.name)
+		assert.Equal(t, tt.converted, summary.Converted, tt.name)
+		assert.Equal(t, tt.skipped, summary.Skipped, tt.name)
+		assert.Equal(t, tt.failed, summary.Failed, tt.name)
+		for _, f := range summary.Files {
+			if f.Status == "failed" {
+				assert.Equal(t, filepath.Join(src, "broken.wav"), f.In, tt.name)
+				assert.NotEmpty(t, f.Error, tt.name)
+				assert.NoFileExists(t, f.Out, tt.name)
+			}
+		}
+
+		partials, err := filepath.Glob(filepath.Join(dst, "*", ".partial-*"))
+		assert.NoError(t, err)
+		partialsTop, err := filepath.Glob(filepath.Join(dst, ".partial-*"))
+		assert.NoError(t, err)
+		assert.Empty(t, append(partials, partialsTop...), tt.name)
+
+		manifest, err := readBatchManifest(filepath.Join(dst, batchManifestName))
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"a.wav": cfg.settings(), "sub/b.wav": cfg.settings()}, manifest, tt.name)
+	}
+
+	for _, fName := range []string{"a.wav", "sub/b.wav"} {
+		f, err := os.Open(filepath.Join(dst, fName))
+		if !assert.NoError(t, err) {
+			continue
+		}
+		dec, err := audiofile.NewDecoder(f)
+		if assert.NoError(t, err) {
+			assert.Equal(t, 8000, dec.Rate, "last settings are kept")
+		}
+		f.Close()
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/lehatrutenb/goresampler/audiofile"
+	"github.com/lehatrutenb/goresampler/internal/bench"
+	"github.com/lehatrutenb/goresampler/internal/utils"
+)
+
+func runBench(args []string) error {
+	cfg := bench.Config{}.NewDefault()
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	rsmTs := fs.String("rsm", "const,spline,fft", "comma separated resamplers to time (const, spline, fft)")
+	rsm2Waves := fs.Bool("2waves", true, "time resamplers to 8000 and 16000 simult too (if -out has both)")
+	inRates := fs.String("in", "8000,11000,11025,16000,44000,44100,48000", "comma separated input rates (ignored if -file is set)")
+	outRates := fs.String("out", "8000,16000", "comma separated output rates")
+	fs.Float64Var(&cfg.DurationS, "dur", cfg.DurationS, "duration of synthetic wave in secs")
+	fs.DurationVar(&cfg.MinTime, "time", cfg.MinTime, "min time to spend per resampler and rate pair (0 - resample once)")
+	file := fs.String("file", "", "audio file (wav, aiff, au) to resample instead of synthetic wave - only its first channel is used")
+	format := fs.String("format", "table", "output format: table or json")
+	outPath := fs.String("o", "-", "file to write results to ('-' for stdout)")
+	quality := fs.Bool("quality", false, "measure snr of every resampler too (sweeps tones as freqresp does)")
+	commit := fs.String("commit", os.Getenv("last_commit_hash"), "commit hash results are keyed by (exported by Makefile)")
+	compare := fs.Bool("compare", false, "compare 2 json results: goresampler bench -compare old.json new.json")
+	tol := bench.Tolerance{}
+	fs.Float64Var(&tol.Speed, "speedtol", 0.1, "relative growth of ns/sample treated as regression by -compare")
+	fs.Float64Var(&tol.SNRDB, "snrtol", 1, "drop of snr in dB treated as regression by -compare")
+	anyPlatform := fs.Bool("anyplatform", false, "let -compare results got on different platforms or amt of cpus (speed is not comparable then)")
+	fs.Parse(args)
+
+	if *compare {
+		if fs.NArg() != 2 {
+			return errors.New("-compare expects 2 result files: old and new")
+		}
+		return compareReports(fs.Arg(0), fs.Arg(1), tol, *anyPlatform, *outPath)
+	}
+
+	var err error
+	if cfg.RsmTs, err = parseRsmTs(*rsmTs); err != nil {
+		return err
+	}
+	if !*rsm2Waves {
+		cfg.Rsm2WavesTs = nil
+	}
+	if cfg.InRates, err = parseInts(*inRates); err != nil {
+		return err
+	}
+	if cfg.OutRates, err = parseInts(*outRates); err != nil {
+		return err
+	}
+	if *file != "" {
+		var rate int
+		if cfg.Wave, rate, err = loadFirstChannel(*file); err != nil {
+			return err
+		}
+		cfg.InRates = []int{rate}
+	}
+
+	var write func(w io.Writer, rep bench.Report) error
+	switch *format {
+	case "table":
+		write = bench.WriteTable
+	case "json":
+		write = bench.WriteJSON
+	default:
+		return fmt.Errorf("unknown output format %q (expected table or json)", *format)
+	}
+
+	res, err := bench.Run(cfg)
+	if err != nil {
+		return err
+	}
+	if *quality {
+		if err = bench.AddQuality(res); err != nil {
+			return err
+		}
+	}
+	rep := bench.NewReport(*commit, res)
+	return writeTo(*outPath, func(w io.Writer) error { return write(w, rep) })
+}
+
+// compareReports writes diff of 2 reports and returns error if there are regressions
+//
+// reports got on different machines are not compared if anyPlatform is false
+func compareReports(oldPath, newPath string, tol bench.Tolerance, anyPlatform bool, outPath string) error {
+	oldRep, err := bench.ReadReport(oldPath)
+	if err != nil {
+		return err
+	}
+	newRep, err := bench.ReadReport(newPath)
+	if err != nil {
+		return err
+	}
+	if err = bench.Comparable(oldRep, newRep); err != nil {
+		if !anyPlatform {
+			return fmt.Errorf("%w (set -anyplatform to compare them anyway)", err)
+		}
+		fmt.Fprintln(os.Stderr, "warning:", err)
+	}
+	diffs := bench.Compare(oldRep, newRep, tol)
+	if err = writeTo(outPath, func(w io.Writer) error { return bench.WriteDiffTable(w, oldRep, newRep, diffs) }); err != nil {
+		return err
+	}
+	if n := bench.Regressions(diffs); n != 0 {
+		return fmt.Errorf("got %d regressions of %d compared results", n, len(diffs))
+	}
+	return nil
+}
+
+// loadFirstChannel returns samples of first channel of audio file and its rate
+func loadFirstChannel(fName string) ([]int16, int, error) {
+	all, rate, chAmt, err := loadWave(fName)
+	if err != nil {
+		return nil, 0, err
+	}
+	return utils.GetWithStep(all, 0, chAmt), rate, nil
+}
+
+// loadWave returns interleaved samples of audio file, its rate and amt of channels
+func loadWave(fName string) ([]int16, int, int, error) {
+	f, err := os.Open(fName)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	defer f.Close()
+	dec, err := audiofile.NewDecoder(f)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to read %s: %w", fName, err)
+	}
+	raw, err := io.ReadAll(dec)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	raw = raw[:len(raw)-len(raw)%(dec.Channels*dec.Format.Size())]
+	res := make([]int16, len(raw)/dec.Format.Size())
+	if err = dec.Format.Decode(res, raw); err != nil {
+		return nil, 0, 0, err
+	}
+	return res, dec.Rate, dec.Channels, nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/lehatrutenb/goresampler"
+)
+
+func runCalibrate(args []string) error {
+	fs := flag.NewFlagSet("calibrate", flag.ExitOnError)
+	rsmTs := fs.String("rsm", "const,spline,fft", "comma separated resamplers to measure (const, spline, fft)")
+	inRates := fs.String("in", "8000,11000,11025,16000,44000,44100,48000", "comma separated input rates")
+	outRates := fs.String("out", "8000,16000", "comma separated output rates")
+	measureTime := fs.Duration("time", 0, "min time to time every resampler per rate pair (0 - default one)")
+	profile := fs.String("profile", "", "json profile to load measured rate pairs from and save all of them to ('' - not save)")
+	minSNR := fs.Float64("minsnr", 60, "min snr in dB resampler must have to be chosen")
+	strict := fs.Bool("strict", true, "choose only of resamplers well tested for rate pair")
+	outPath := fs.String("o", "-", "file to write table to ('-' for stdout)")
+	fs.Parse(args)
+
+	calib, err := loadCalibration(*profile)
+	if err != nil {
+		return err
+	}
+	if calib.Candidates, err = parseRsmTs(*rsmTs); err != nil {
+		return err
+	}
+	if *measureTime != 0 {
+		calib.MeasureTime = *measureTime
+	}
+	ins, err := parseInts(*inRates)
+	if err != nil {
+		return err
+	}
+	outs, err := parseInts(*outRates)
+	if err != nil {
+		return err
+	}
+
+	chosen := make(map[[2]int]goresampler.ResamplerT)
+	belowMinSNR := make(map[[2]int]bool)
+	for _, inRate := range ins {
+		for _, outRate := range outs {
+			if inRate == outRate {
+				continue
+			}
+			key := [2]int{inRate, outRate}
+			chosen[key], err = calib.Choose(inRate, outRate, *minSNR, *strict)
+			belowMinSNR[key] = errors.Is(err, goresampler.ErrMinSNRNotMet)
+			if err != nil && !belowMinSNR[key] && !errors.Is(err, goresampler.ErrUnexpResRate) {
+				return err
+			}
+		}
+	}
+	if err = saveCalibration(*profile, calib); err != nil {
+		return err
+	}
+	return writeTo(*outPath, func(w io.Writer) error { return writeCalibrationTable(w, calib, chosen, belowMinSNR) })
+}
+
+// writeCalibrationTable writes entries of calib of rate pairs chosen has - chosen types are marked by *
+// (by *! if they don't reach min snr)
+func writeCalibrationTable(w io.Writer, calib *goresampler.Calibration, chosen map[[2]int]goresampler.ResamplerT, belowMinSNR map[[2]int]bool) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	if _, err := fmt.Fprintln(tw, "in_rate\tout_rate\tresampler\tns/sample\tsnr_db\tchosen\t"); err != nil {
+		return err
+	}
+	for _, e := range calib.Entries() {
+		rsmT, ok := chosen[[2]int{e.InRate, e.OutRate}]
+		if !ok {
+			continue
+		}
+		mark := ""
+		if rsmT == e.RsmT {
+			mark = "*"
+			if belowMinSNR[[2]int{e.InRate, e.OutRate}] {
+				mark = "*!"
+			}
+		}
+		if _, err := fmt.Fprintf(tw, "%d\t%d\t%s\t%.2f\t%.1f\t%s\t\n", e.InRate, e.OutRate, e.RsmT, e.NsPerSample, e.SNRDB, mark); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+// loadCalibration reads calibration profile (new calibration is returned if fName is empty or there is no such file yet)
+func loadCalibration(fName string) (*goresampler.Calibration, error) {
+	if fName == "" {
+		return goresampler.NewCalibration(), nil
+	}
+	f, err := os.Open(fName)
+	if errors.Is(err, os.ErrNotExist) {
+		return goresampler.NewCalibration(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	calib, err := goresampler.LoadCalibration(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read calibration profile %s: %w", fName, err)
+	}
+	return calib, nil
+}
+
+// saveCalibration writes calib profile to fName (skipped if fName is empty)
+func saveCalibration(fName string, calib *goresampler.Calibration) error {
+	if fName == "" {
+		return nil
+	}
+	return writeTo(fName, calib.Save)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/lehatrutenb/goresampler"
+)
+
+// capabilityRow is capability of 1 resampler type on 1 rate pair
+type capabilityRow struct {
+	InRate    int     `json:"in_rate"`
+	OutRate   int     `json:"out_rate"`
+	RsmT      string  `json:"resampler"`
+	Algorithm string  `json:"algorithm"`
+	Safe      bool    `json:"safe"`
+	SNRDB     float64 `json:"expected_snr_db"`
+	RelSpeed  float64 `json:"relative_time"`
+}
+
+func runCapabilities(args []string) error {
+	fs := flag.NewFlagSet("capabilities", flag.ExitOnError)
+	inRates := fs.String("in", "8000,11000,11025,16000,44000,44100,48000", "comma separated input rates")
+	outRates := fs.String("out", "8000,16000", "comma separated output rates")
+	safeOnly := fs.Bool("safe", false, "print only well tested conversions (allowed with -strict of convert)")
+	format := fs.String("format", "table", "output format: table or json")
+	outPath := fs.String("o", "-", "file to write capabilities to ('-' for stdout)")
+	fs.Parse(args)
+
+	ins, err := parseInts(*inRates)
+	if err != nil {
+		return err
+	}
+	outs, err := parseInts(*outRates)
+	if err != nil {
+		return err
+	}
+	rows := make([]capabilityRow, 0)
+	for _, inRate := range ins {
+		for _, outRate := range outs {
+			for _, c := range goresampler.Capabilities(inRate, outRate) {
+				if *safeOnly && !c.Safe {
+					continue
+				}
+				rows = append(rows, capabilityRow{inRate, outRate, c.Type.String(), c.Algorithm.String(), c.Safe, c.SNRDB, c.RelSpeed})
+			}
+		}
+	}
+
+	switch *format {
+	case "table":
+		return writeTo(*outPath, func(w io.Writer) error { return writeCapabilitiesTable(w, rows) })
+	case "json":
+		return writeTo(*outPath, func(w io.Writer) error {
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			return enc.Encode(rows)
+		})
+	default:
+		return fmt.Errorf("unknown output format %q (expected table or json)", *format)
+	}
+}
+
+// writeCapabilitiesTable writes rows as aligned text table
+func writeCapabilitiesTable(w io.Writer, rows []capabilityRow) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	if _, err := fmt.Fprintln(tw, "in_rate\tout_rate\tresampler\talgorithm\tsafe\texpected_snr_db\trelative_time\t"); err != nil {
+		return err
+	}
+	for _, r := range rows {
+		if _, err := fmt.Fprintf(tw, "%d\t%d\t%s\t%s\t%t\t%.0f\t%.0fx\t\n", r.InRate, r.OutRate, r.RsmT, r.Algorithm, r.Safe, r.SNRDB, r.RelSpeed); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"github.com/lehatrutenb/goresampler"
+	"github.com/lehatrutenb/goresampler/audiofile"
+)
+
+type convertConfig struct {
+	inPath, outPath string
+	rate, channels  int
+	container       string // empty - by extension of outPath or same as input one
+	format          string // empty - same as input one if out container supports it
+	rsmT            goresampler.ResamplerT
+	strict          bool
+	calib           *goresampler.Calibration // if not nil - chooses resampler instead of rsmT
+	minSNRDB        float64
+}
+
+func runConvert(args []string) error {
+	fs := flag.NewFlagSet("convert", flag.ExitOnError)
+	cfg := convertConfig{}
+	fs.StringVar(&cfg.inPath, "in", "", "input audio file (wav, aiff or au - detected by magic bytes)")
+	fs.StringVar(&cfg.outPath, "out", "", "output audio file")
+	fs.IntVar(&cfg.rate, "rate", 16000, "output rate (0 - same as input)")
+	fs.IntVar(&cfg.channels, "channels", 0, "output channels amt (0 - same as input)")
+	fs.StringVar(&cfg.container, "container", "", "output container: wav, aiff, au (by -out extension by default)")
+	fs.StringVar(&cfg.format, "format", "", "output sample format: s16le, s16be, f32le, mulaw, alaw (input one if container supports it)")
+	rsmT := fs.String("rsm", "bestfit", "resampler: const, spline, fft, bestfit, notsafe")
+	fs.BoolVar(&cfg.strict, "strict", true, "allow only well tested rate conversions")
+	calibPath := fs.String("calib", "", "calibration profile to choose resampler by (overrides -rsm ; rates it has not are measured and saved to it)")
+	fs.Float64Var(&cfg.minSNRDB, "minsnr", 60, "min snr in dB resampler chosen by -calib must have")
+	fs.Parse(args)
+
+	if cfg.inPath == "" || cfg.outPath == "" {
+		return errors.New("-in and -out must be set")
+	}
+	var err error
+	if cfg.rsmT, err = parseRsmT(*rsmT); err != nil {
+		return err
+	}
+	if *calibPath != "" {
+		if cfg.calib, err = loadCalibration(*calibPath); err != nil {
+			return err
+		}
+	}
+	if _, err = convertFile(cfg); err != nil {
+		return err
+	}
+	return saveCalibration(*calibPath, cfg.calib)
+}
+
+// rsmOpts returns options of resampler type choice
+func (cfg convertConfig) rsmOpts() []goresampler.Option {
+	if cfg.calib != nil {
+		return []goresampler.Option{goresampler.WithCalibration(cfg.calib, cfg.minSNRDB), goresampler.WithStrictRates(cfg.strict)}
+	}
+	return []goresampler.Option{goresampler.WithType(cfg.rsmT), goresampler.WithStrictRates(cfg.strict)}
+}
+
+// outHeader returns header of converted file of in
+func (cfg convertConfig) outHeader(in audiofile.Header) (audiofile.Header, error) {
+	out := in
+	var err error
+	if cfg.container != "" {
+		out.Container, err = audiofile.ContainerOfExt("." + cfg.container)
+	} else if out.Container, err = audiofile.ContainerOfExt(cfg.outPath); err != nil {
+		out.Container, err = in.Container, nil
+	}
+	if err != nil {
+		return out, fmt.Errorf("unknown container %q: %w", cfg.container, err)
+	}
+
+	if cfg.format != "" {
+		if out.Format, err = parseSampleFormat(cfg.format); err != nil {
+			return out, err
+		}
+	} else if !out.Container.Supports(out.Format) {
+		out.Format = goresampler.SampleFormatS16LE
+		if !out.Container.Supports(out.Format) {
+			out.Format = goresampler.SampleFormatS16BE
+		}
+	}
+	if !out.Container.Supports(out.Format) {
+		return out, fmt.Errorf("%s can't store %s samples", out.Container, out.Format)
+	}
+
+	if cfg.rate != 0 {
+		out = out.Resampled(cfg.rate) // cue points are moved to new rate
+	}
+	if cfg.channels != 0 {
+		out = out.WithChannels(cfg.channels)
+	}
+	return out, nil
+}
+
+// convertStats describes converted file
+type convertStats struct {
+	info            goresampler.Info
+	inRate, outRate int
+	inSec, outSec   float64
+	clipped         int64 // amt of output samples at full scale
+}
+
+func convertFile(cfg convertConfig) (convertStats, error) {
+	stats := convertStats{}
+	inF, err := os.Open(cfg.inPath)
+	if err != nil {
+		return stats, err
+	}
+	defer inF.Close()
+	dec, err := audiofile.NewDecoder(inF)
+	if err != nil {
+		return stats, fmt.Errorf("failed to read %s: %w", cfg.inPath, err)
+	}
+	h, err := cfg.outHeader(dec.Header)
+	if err != nil {
+		return stats, err
+	}
+	stats.inRate, stats.outRate = dec.Rate, h.Rate
+
+	rsmIns, info, err := goresampler.New(dec.Rate, h.Rate, append(cfg.rsmOpts(), goresampler.WithChannels(dec.Channels), goresampler.WithOutChannels(h.Channels))...)
+	if err != nil {
+		return stats, err
+	}
+	stats.info = info
+	in := newSampleCounter(dec.Format)
+	r, err := goresampler.NewReader(io.TeeReader(dec, in), goresampler.NewResampleBatch(rsmIns, dec.Rate, h.Rate), dec.Format, h.Format)
+	if err != nil {
+		return stats, err
+	}
+
+	outF, err := os.Create(cfg.outPath)
+	if err != nil {
+		return stats, err
+	}
+	enc, err := audiofile.NewEncoder(outF, h)
+	if err != nil {
+		outF.Close()
+		return stats, err
+	}
+	out := newSampleCounter(h.Format)
+	if _, err = io.Copy(io.MultiWriter(enc, out), r); err != nil {
+		outF.Close()
+		return stats, err
+	}
+	if err = enc.Close(); err != nil {
+		outF.Close()
+		return stats, err
+	}
+	stats.inSec = float64(in.amt) / float64(dec.Channels*dec.Rate)
+	stats.outSec, stats.clipped = float64(out.amt)/float64(h.Channels*h.Rate), out.clipped
+	return stats, outF.Close()
+}
+
+// sampleCounter counts samples in format f written to it and samples of them at full scale
+type sampleCounter struct {
+	maxS, minS   []byte // full scale samples encoded in format
+	amt, clipped int64
+	partial      []byte // bytes of sample split between writes
+}
+
+func newSampleCounter(f goresampler.SampleFormat) *sampleCounter {
+	c := &sampleCounter{maxS: make([]byte, f.Size()), minS: make([]byte, f.Size())}
+	_ = f.Encode(c.maxS, []int16{math.MaxInt16})
+	_ = f.Encode(c.minS, []int16{math.MinInt16})
+	return c
+}
+
+func (c *sampleCounter) Write(p []byte) (int, error) {
+	n, size := len(p), len(c.maxS)
+	if len(c.partial) != 0 {
+		k := min(size-len(c.partial), len(p))
+		c.partial, p = append(c.partial, p[:k]...), p[k:]
+		if len(c.partial) < size {
+			return n, nil
+		}
+		c.count(c.partial)
+		c.partial = c.partial[:0]
+	}
+	for ; len(p) >= size; p = p[size:] {
+		c.count(p[:size])
+	}
+	c.partial = append(c.partial, p...)
+	return n, nil
+}
+
+// count counts 1 sample
+func (c *sampleCounter) count(s []byte) {
+	c.amt++
+	if bytes.Equal(s, c.maxS) || bytes.Equal(s, c.minS) {
+		c.clipped++
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	"github.com/lehatrutenb/goresampler"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// samples split between writes (like io.Copy chunks) must be counted once
+func TestSampleCounter(t *testing.T) {
+	for _, f := range []goresampler.SampleFormat{goresampler.SampleFormatS16LE, goresampler.SampleFormatF32LE, goresampler.SampleFormatMuLaw} {
+		wave := []int16{0, math.MaxInt16, 100, math.MinInt16, -5, math.MaxInt16, 7}
+		data := make([]byte, len(wave)*f.Size())
+		assert.NoError(t, f.Encode(data, wave))
+
+		for _, chunk := range []int{1, 3, len(data)} {
+			c := newSampleCounter(f)
+			for l := 0; l < len(data); l += chunk {
+				n, err := c.Write(data[l:min(l+chunk, len(data))])
+				assert.NoError(t, err)
+				assert.Equal(t, min(chunk, len(data)-l), n)
+			}
+			assert.Equal(t, int64(len(wave)), c.amt, "format %d chunk %d", f, chunk)
+			assert.Equal(t, int64(3), c.clipped, "format %d chunk %d", f, chunk)
+			assert.Empty(t, c.partial)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lehatrutenb/goresampler"
+)
+
+var rsmTNames = map[string]goresampler.ResamplerT{
+	"const":   goresampler.ResamplerConstExprT,
+	"spline":  goresampler.ResamplerSplineT,
+	"fft":     goresampler.ResamplerFFtT,
+	"bestfit": goresampler.ResamplerBestFitT,
+	"notsafe": goresampler.ResamplerBestFitNotSafeT,
+}
+
+func parseInts(s string) ([]int, error) {
+	res := make([]int, 0)
+	for _, cur := range strings.Split(s, ",") {
+		if cur = strings.TrimSpace(cur); cur == "" {
+			continue
+		}
+		v, err := strconv.Atoi(cur)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %q as int list: %w", s, err)
+		}
+		res = append(res, v)
+	}
+	return res, nil
+}
+
+func parseRsmT(s string) (goresampler.ResamplerT, error) {
+	rsmT, ok := rsmTNames[strings.ToLower(strings.TrimSpace(s))]
+	if !ok {
+		return 0, fmt.Errorf("unknown resampler %q (expected one of const, spline, fft, bestfit, notsafe)", s)
+	}
+	return rsmT, nil
+}
+
+func parseRsmTs(s string) ([]goresampler.ResamplerT, error) {
+	res := make([]goresampler.ResamplerT, 0)
+	for _, cur := range strings.Split(s, ",") {
+		if strings.TrimSpace(cur) == "" {
+			continue
+		}
+		rsmT, err := parseRsmT(cur)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, rsmT)
+	}
+	return res, nil
+}
+
+func parseSampleFormat(s string) (goresampler.SampleFormat, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for _, f := range []goresampler.SampleFormat{goresampler.SampleFormatS16LE, goresampler.SampleFormatS16BE, goresampler.SampleFormatF32LE,
+		goresampler.SampleFormatMuLaw, goresampler.SampleFormatALaw} {
+		if f.String() == s {
+			return f, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown sample format %q (expected one of s16le, s16be, f32le, mulaw, alaw)", s)
+}
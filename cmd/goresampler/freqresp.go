@@ -0,0 +1,87 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/lehatrutenb/goresampler/internal/freqresp"
+)
+
+func createOrStdout(fName string) (io.WriteCloser, error) {
+	if fName == "-" {
+		return os.Stdout, nil
+	}
+	return os.Create(fName)
+}
+
+func writeTo(fName string, write func(w io.Writer) error) error {
+	if fName == "" {
+		return nil
+	}
+	f, err := createOrStdout(fName)
+	if err != nil {
+		return err
+	}
+	if err = write(f); err != nil {
+		f.Close()
+		return err
+	}
+	if f == os.Stdout {
+		return nil
+	}
+	return f.Close()
+}
+
+func runFreqResp(args []string) error {
+	cfg := freqresp.Config{}.NewDefault()
+	fs := flag.NewFlagSet("freqresp", flag.ExitOnError)
+	rsmTs := fs.String("rsm", "const,spline,fft", "comma separated resamplers to check (const, spline, fft)")
+	inRates := fs.String("in", "8000,11000,11025,16000,44000,44100,48000", "comma separated input rates")
+	outRates := fs.String("out", "8000,16000", "comma separated output rates")
+	fs.IntVar(&cfg.Steps, "steps", cfg.Steps, "amt of tones per sweep")
+	fs.IntVar(&cfg.FFTSize, "fftsize", cfg.FFTSize, "len of analysed output (power of 2)")
+	fs.Float64Var(&cfg.Amplitude, "amp", cfg.Amplitude, "tone amplitude relative to full scale")
+	csvPath := fs.String("csv", "", "file to write per tone csv to ('-' for stdout)")
+	jsonPath := fs.String("json", "", "file to write json results to ('-' for stdout)")
+	summaryPath := fs.String("summary", "-", "file to write per sweep summary csv to ('-' for stdout, '' to skip)")
+	plotsDir := fs.String("plots", "", "dir to save png plots to (skip plots if empty)")
+	fs.Parse(args)
+
+	var err error
+	if cfg.RsmTs, err = parseRsmTs(*rsmTs); err != nil {
+		return err
+	}
+	if cfg.InRates, err = parseInts(*inRates); err != nil {
+		return err
+	}
+	if cfg.OutRates, err = parseInts(*outRates); err != nil {
+		return err
+	}
+	if cfg.FFTSize <= 0 || cfg.FFTSize&(cfg.FFTSize-1) != 0 {
+		return fmt.Errorf("fftsize must be power of 2, got %d", cfg.FFTSize)
+	}
+
+	res, err := freqresp.Run(cfg)
+	if err != nil {
+		return err
+	}
+
+	if err = writeTo(*csvPath, func(w io.Writer) error { return freqresp.WriteCSV(w, res) }); err != nil {
+		return err
+	}
+	if err = writeTo(*jsonPath, func(w io.Writer) error { return freqresp.WriteJSON(w, res) }); err != nil {
+		return err
+	}
+	if err = writeTo(*summaryPath, func(w io.Writer) error { return freqresp.WriteSummaryCSV(w, res) }); err != nil {
+		return err
+	}
+	if *plotsDir != "" {
+		if err = os.MkdirAll(*plotsDir, 0755); err != nil {
+			return err
+		}
+		return freqresp.SavePlots(*plotsDir, res)
+	}
+	return nil
+}
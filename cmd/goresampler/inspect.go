@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lehatrutenb/goresampler/internal/inspect"
+)
+
+func runInspect(args []string) error {
+	cfg := inspect.Config{}.NewDefault()
+	fs := flag.NewFlagSet("inspect", flag.ExitOnError)
+	refPath := fs.String("ref", "", "reference audio file (how it should sound)")
+	gotPath := fs.String("got", "", "audio file to check (same rate and channels as -ref)")
+	outPath := fs.String("o", "inspect.html", "html report to write (png with plots is written next to it) ; if ends with .png - only plots are written")
+	fs.Float64Var(&cfg.MaxLagS, "maxlag", cfg.MaxLagS, "max shift of -got relative to -ref searched to align them in secs (0 - not align)")
+	fs.Float64Var(&cfg.AlignDurS, "aligndur", cfg.AlignDurS, "secs of waves beginning used to align them")
+	fs.Parse(args)
+
+	if *refPath == "" || *gotPath == "" {
+		return errors.New("both -ref and -got must be set")
+	}
+	ref, refRate, refChs, err := loadWave(*refPath)
+	if err != nil {
+		return err
+	}
+	got, gotRate, gotChs, err := loadWave(*gotPath)
+	if err != nil {
+		return err
+	}
+	if refRate != gotRate {
+		return fmt.Errorf("rates differ: %d of -ref and %d of -got (resample one of them by convert first)", refRate, gotRate)
+	}
+	if refChs != gotChs {
+		return fmt.Errorf("amt of channels differ: %d of -ref and %d of -got", refChs, gotChs)
+	}
+
+	res, err := inspect.Compare(ref, got, refRate, refChs, cfg)
+	if err != nil {
+		return err
+	}
+	res.RefFile, res.GotFile = *refPath, *gotPath
+	if err = inspect.WriteText(os.Stdout, res); err != nil {
+		return err
+	}
+
+	if strings.EqualFold(filepath.Ext(*outPath), ".png") {
+		return inspect.SavePNG(*outPath, res)
+	}
+	png := strings.TrimSuffix(*outPath, filepath.Ext(*outPath)) + ".png"
+	if err = inspect.SavePNG(png, res); err != nil {
+		return err
+	}
+	return writeTo(*outPath, func(w io.Writer) error { return inspect.WriteHTML(w, res, filepath.Base(png)) })
+}
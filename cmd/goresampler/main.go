@@ -0,0 +1,56 @@
+// Command goresampler provides tools around goresampler lib
+//
+// usage: goresampler <command> [flags]
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+)
+
+type command struct {
+	descr string
+	run   func(args []string) error
+}
+
+var commands = map[string]command{
+	"batch":        {"resample audio files of dir tree in parallel", runBatch},
+	"bench":        {"time resamplers (ns/sample, realtime factor, allocs)", runBench},
+	"calibrate":    {"measure speed and snr of resamplers on this machine and choose fastest good enough ones", runCalibrate},
+	"capabilities": {"print what resamplers convert rate pairs (algorithm, safety, expected quality and speed)", runCapabilities},
+	"convert":      {"resample audio file (wav, aiff, au)", runConvert},
+	"freqresp":     {"sweep tones through resamplers and measure gain and aliasing", runFreqResp},
+	"inspect":      {"compare resampled audio file with reference one (html/png report)", runInspect},
+	"plot":         {"draw waveform, error histogram and spectrogram of test reports", runPlot},
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: goresampler <command> [flags]")
+	fmt.Fprintln(os.Stderr, "commands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-13s %s\n", name, commands[name].descr)
+	}
+	fmt.Fprintln(os.Stderr, "run 'goresampler <command> -h' to get command flags")
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+	if err := cmd.run(os.Args[2:]); err != nil {
+		log.Fatalf("%s failed: %v", os.Args[1], err)
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"runtime"
+
+	"github.com/lehatrutenb/goresampler/internal/plots"
+)
+
+func runPlot(args []string) error {
+	fs := flag.NewFlagSet("plot", flag.ExitOnError)
+	inDir := fs.String("in", "./test/reports_large", "dir with subdirs of test reports (':large' files)")
+	outDir := fs.String("out", "./test/plots", "dir to save png plots to (same subdirs)")
+	report := fs.String("report", "", "single report to draw instead of dirs")
+	png := fs.String("png", "", "png file to draw -report to")
+	workers := fs.Int("workers", runtime.NumCPU(), "amt of reports drawn in parallel")
+	fs.Parse(args)
+
+	if *report == "" {
+		return plots.SaveReports(*inDir, *outDir, *workers)
+	}
+	if *png == "" {
+		return errors.New("-png must be set to draw -report")
+	}
+	r, err := plots.ReadReport(*report)
+	if err != nil {
+		return err
+	}
+	return plots.SaveReport(*png, r)
+}
@@ -0,0 +1,9 @@
+package goresampler
+
+// SetBatch replaces resample batch of input - lets tests mix input with broken resampler
+func (in *MixerInput) SetBatch(rsm ResampleBatch) {
+	in.rsm = rsm
+}
+
+// AlgorithmFor exports algorithmFor - lets tests check choosing algorithm doesn't build resamplers
+var AlgorithmFor = algorithmFor
@@ -0,0 +1,246 @@
+package bench
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/lehatrutenb/goresampler"
+	"github.com/lehatrutenb/goresampler/internal/utils"
+)
+
+var (
+	ErrNoWave = errors.New("got too short wave to benchmark")
+)
+
+// Config describes what resamplers to time and on what waves
+type Config struct {
+	RsmTs       []goresampler.ResamplerT
+	Rsm2WavesTs []goresampler.Resampler2WavesT // timed only if OutRates have 8000 and 16000
+	InRates     []int
+	OutRates    []int
+	DurationS   float64       // duration of synthetic wave
+	MinTime     time.Duration // min time to resample wave again and again per 1 resampler and rate pair (wave is resampled at least once)
+	Wave        []int16       // user wave (1 channel) to resample instead of synthetic one ; InRates must be its rate then
+}
+
+func (Config) NewDefault() Config {
+	return Config{
+		RsmTs:       []goresampler.ResamplerT{goresampler.ResamplerConstExprT, goresampler.ResamplerSplineT, goresampler.ResamplerFFtT},
+		Rsm2WavesTs: []goresampler.Resampler2WavesT{goresampler.Resampler2WavesSplineT},
+		InRates:     []int{8000, 11000, 11025, 16000, 44000, 44100, 48000},
+		OutRates:    []int{8000, 16000},
+		DurationS:   10,
+		MinTime:     time.Second,
+	}
+}
+
+// Result of timing of 1 resampler and rate pair
+type Result struct {
+	RsmT           string   `json:"resampler"`
+	InRate         int      `json:"in_rate"`
+	OutRate        int      `json:"out_rate"`
+	OutRate2       int      `json:"out_rate2,omitempty"` // second out rate of Resampler2WavesT
+	InSamples      int      `json:"in_samples"`          // len of resampled wave
+	Runs           int      `json:"runs"`                // amt of times wave is resampled
+	NsPerSample    float64  `json:"ns_per_sample"`       // per input sample
+	RealtimeFactor float64  `json:"realtime_factor"`     // secs of audio resampled per 1 sec
+	AllocsPerRun   float64  `json:"allocs_per_run"`
+	BytesPerRun    float64  `json:"bytes_per_run"`
+	SNRDB          *float64 `json:"snr_db,omitempty"` // -max alias + distortion of passband tones (set by AddQuality)
+}
+
+// outRates returns out rate of r ("8000+16000" for Resampler2WavesT)
+func (r Result) outRates() string {
+	if r.OutRate2 != 0 {
+		return strconv.Itoa(r.OutRate) + "+" + strconv.Itoa(r.OutRate2)
+	}
+	return strconv.Itoa(r.OutRate)
+}
+
+// synthWave returns amt samples of tones with a bit of noise - close to voice by spectrum width
+func synthWave(rate, amt int) []int16 {
+	rnd := rand.New(rand.NewSource(1))
+	res := make([]int16, amt)
+	for i := range res {
+		t := float64(i) / float64(rate)
+		x := 0.3*math.Sin(2*math.Pi*220*t) + 0.15*math.Sin(2*math.Pi*1250*t) + 0.05*math.Sin(2*math.Pi*3100*t)
+		res[i] = utils.Float64ToS16(x + 0.02*(rnd.Float64()*2-1))
+	}
+	return res
+}
+
+// fitLens returns lens of input and outputs not bigger than len of wave that resampler can get at once
+func fitLens(waveLen int, calc func(outAmt int) (inLen int, outLens []int), outAmt int) (int, []int) {
+	for outAmt > 0 {
+		inLen, outLens := calc(outAmt)
+		if inLen <= waveLen {
+			return inLen, outLens
+		}
+		outAmt -= outAmt/100 + 1
+	}
+	return 0, nil
+}
+
+// measure calls resample again and again till minTime is spent (at least once)
+func measure(minTime time.Duration, resample func() error) (runs int, spent time.Duration, allocs, bytes uint64, err error) {
+	if err = resample(); err != nil { // warm up
+		return
+	}
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	for runs == 0 || spent < minTime {
+		if err = resample(); err != nil {
+			return
+		}
+		runs++
+		spent = time.Since(start)
+	}
+	runtime.ReadMemStats(&after)
+	return runs, spent, after.Mallocs - before.Mallocs, after.TotalAlloc - before.TotalAlloc, nil
+}
+
+func newResult(rsmT string, inRate, inLen, runs int, spent time.Duration, allocs, bytes uint64) Result {
+	ns := float64(spent.Nanoseconds()) / float64(runs*inLen)
+	return Result{
+		RsmT: rsmT, InRate: inRate, InSamples: inLen, Runs: runs,
+		NsPerSample:    ns,
+		RealtimeFactor: 1e9 / (ns * float64(inRate)),
+		AllocsPerRun:   float64(allocs) / float64(runs),
+		BytesPerRun:    float64(bytes) / float64(runs),
+	}
+}
+
+// WaveGetter returns wave to resample - minLen is len of input wave resampler needs to get at least 1 batch
+type WaveGetter func(minLen int) []int16
+
+// Time times rsmT resampler converting wave from inRate to outRate
+func Time(rsmT goresampler.ResamplerT, inRate, outRate int, getWave WaveGetter, minTime time.Duration) (Result, error) {
+	rsm, _, err := goresampler.NewResamplerAuto(inRate, outRate, rsmT, nil)
+	if err != nil {
+		return Result{}, err
+	}
+	minLen, _ := rsm.CalcInOutSamplesPerOutAmt(1)
+	wave := getWave(minLen)
+	inLen, outLens := fitLens(len(wave), func(outAmt int) (int, []int) {
+		inLen, outLen := rsm.CalcInOutSamplesPerOutAmt(outAmt)
+		return inLen, []int{outLen}
+	}, len(wave)*outRate/inRate)
+	if inLen == 0 {
+		return Result{}, ErrNoWave
+	}
+
+	in, out := wave[:inLen], make([]int16, outLens[0])
+	runs, spent, allocs, bytes, err := measure(minTime, func() error {
+		rsm.Reset()
+		return rsm.Resample(in, out)
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	res := newResult(rsmT.String(), inRate, inLen, runs, spent, allocs, bytes)
+	res.OutRate = outRate
+	return res, nil
+}
+
+// Time2Waves times rsmT resampler converting wave from inRate to outRate1 and outRate2 simult
+func Time2Waves(rsmT goresampler.Resampler2WavesT, inRate, outRate1, outRate2 int, getWave WaveGetter, minTime time.Duration) (Result, error) {
+	rsm, _, err := goresampler.NewResamplerAuto2Waves(inRate, outRate1, outRate2, rsmT, nil)
+	if err != nil {
+		return Result{}, err
+	}
+	minLen, _, _ := rsm.CalcInOutSamplesPerOutAmt(1, outRate2/outRate1)
+	wave := getWave(minLen)
+	inLen, outLens := fitLens(len(wave), func(outAmt int) (int, []int) {
+		inLen, outLen1, outLen2 := rsm.CalcInOutSamplesPerOutAmt(outAmt, outAmt*outRate2/outRate1)
+		return inLen, []int{outLen1, outLen2}
+	}, len(wave)*outRate1/inRate)
+	if inLen == 0 {
+		return Result{}, ErrNoWave
+	}
+
+	in, out1, out2 := wave[:inLen], make([]int16, outLens[0]), make([]int16, outLens[1])
+	runs, spent, allocs, bytes, err := measure(minTime, func() error {
+		rsm.Reset()
+		return rsm.Resample(in, out1, out2)
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	res := newResult(rsmT.String(), inRate, inLen, runs, spent, allocs, bytes)
+	res.OutRate, res.OutRate2 = outRate1, outRate2
+	return res, nil
+}
+
+// converts returns true if rsmT converts inRate to outRate with strict rates (resamplers are built so)
+//
+// any type converts not changing rates
+func converts(rsmT goresampler.ResamplerT, inRate, outRate int) bool {
+	if inRate == outRate {
+		return true
+	}
+	for _, c := range goresampler.Capabilities(inRate, outRate) {
+		if c.Type == rsmT {
+			return c.Safe
+		}
+	}
+	return false
+}
+
+// Run calls Time (and Time2Waves) for every cfg resampler and rate pair it converts with strict rates (except not changing ones)
+func Run(cfg Config) ([]Result, error) {
+	res := make([]Result, 0)
+	wave := func(rate int) WaveGetter {
+		return func(minLen int) []int16 {
+			if cfg.Wave != nil {
+				return cfg.Wave
+			}
+			return synthWave(rate, max(int(cfg.DurationS*float64(rate)), minLen))
+		}
+	}
+
+	for _, rsmT := range cfg.RsmTs {
+		for _, inRate := range cfg.InRates {
+			for _, outRate := range cfg.OutRates {
+				if inRate == outRate || !converts(rsmT, inRate, outRate) {
+					continue
+				}
+				cur, err := Time(rsmT, inRate, outRate, wave(inRate), cfg.MinTime)
+				if err != nil {
+					return nil, err
+				}
+				res = append(res, cur)
+			}
+		}
+	}
+
+	has8000, has16000 := false, false
+	for _, outRate := range cfg.OutRates {
+		has8000, has16000 = has8000 || outRate == 8000, has16000 || outRate == 16000
+	}
+	if !has8000 || !has16000 {
+		return res, nil
+	}
+	for _, rsmT := range cfg.Rsm2WavesTs {
+		for _, inRate := range cfg.InRates {
+			rsmInsT, err := rsmT.GetRsmIns()
+			if err != nil {
+				return nil, err
+			}
+			if !converts(rsmInsT, inRate, 8000) || !converts(rsmInsT, inRate, 16000) {
+				continue
+			}
+			cur, err := Time2Waves(rsmT, inRate, 8000, 16000, wave(inRate), cfg.MinTime)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, cur)
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,61 @@
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/lehatrutenb/goresampler"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTime(t *testing.T) {
+	for _, minTime := range []time.Duration{0, time.Millisecond} {
+		res, err := Time(goresampler.ResamplerSplineT, 48000, 16000, func(minLen int) []int16 { return synthWave(48000, max(4800, minLen)) }, minTime)
+		if !assert.NoError(t, err) {
+			continue
+		}
+		assert.Positive(t, res.Runs, "min time %s", minTime)
+		assert.Positive(t, res.InSamples)
+		for _, x := range []float64{res.NsPerSample, res.RealtimeFactor, res.AllocsPerRun, res.BytesPerRun} {
+			assert.False(t, math.IsNaN(x) || math.IsInf(x, 0), "min time %s", minTime)
+		}
+	}
+
+	_, err := Time(goresampler.ResamplerSplineT, 48000, 16000, func(int) []int16 { return nil }, 0)
+	assert.ErrorIs(t, err, ErrNoWave)
+}
+
+func TestRun(t *testing.T) {
+	cfg := Config{}.NewDefault()
+	cfg.InRates, cfg.DurationS, cfg.MinTime = []int{11000, 16000, 48000}, 0.1, 0
+
+	res, err := Run(cfg)
+	assert.NoError(t, err)
+	got := make([]string, 0)
+	for _, r := range res {
+		got = append(got, fmt.Sprintf("%s %d->%s", r.RsmT, r.InRate, r.outRates()))
+		assert.Equal(t, 1, r.Runs)
+	}
+	assert.ElementsMatch(t, []string{
+		"Const_expression_resampler 11000->8000",
+		"Const_expression_resampler 11000->16000",
+		"Const_expression_resampler 16000->8000",
+		"Const_expression_resampler 48000->8000",
+		"Const_expression_resampler 48000->16000",
+		"Spline_resampler 16000->8000",
+		"Spline_resampler 48000->8000",
+		"Spline_resampler 48000->16000",
+		"FFT_resampler 16000->8000",
+		"FFT_resampler 48000->8000",
+		"FFT_resampler 48000->16000",
+		"Spline_resampler_2waves 16000->8000+16000",
+		"Spline_resampler_2waves 48000->8000+16000",
+	}, got)
+
+	_, err = json.Marshal(res)
+	assert.NoError(t, err, "results are finite")
+}
@@ -0,0 +1,40 @@
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+)
+
+// WriteJSON writes rep as json (ReadReport reads it)
+func WriteJSON(w io.Writer, rep Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(rep)
+}
+
+// fmtSNR returns snr with 1 digit after point ("-" if it's not measured)
+func fmtSNR(snr *float64) string {
+	if snr == nil {
+		return "-"
+	}
+	return strconv.FormatFloat(*snr, 'f', 1, 64)
+}
+
+// WriteTable writes results of rep as aligned text table
+func WriteTable(w io.Writer, rep Report) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	if _, err := fmt.Fprintln(tw, "resampler\tin_rate\tout_rate\tns/sample\trealtime_x\tallocs/run\tKB/run\truns\tsnr_db\t"); err != nil {
+		return err
+	}
+	for _, r := range rep.Results {
+		_, err := fmt.Fprintf(tw, "%s\t%d\t%s\t%.2f\t%.1f\t%.1f\t%.1f\t%d\t%s\t\n",
+			r.RsmT, r.InRate, r.outRates(), r.NsPerSample, r.RealtimeFactor, r.AllocsPerRun, r.BytesPerRun/1024, r.Runs, fmtSNR(r.SNRDB))
+		if err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,195 @@
+package bench
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/lehatrutenb/goresampler"
+	"github.com/lehatrutenb/goresampler/internal/freqresp"
+)
+
+var (
+	ErrNotComparable = errors.New("reports are got on different machines")
+)
+
+// Report is results of 1 bench run keyed by commit they are got on
+type Report struct {
+	Commit    string    `json:"commit"`
+	Date      time.Time `json:"date"`
+	GoVersion string    `json:"go_version"`
+	Platform  string    `json:"platform"` // GOOS/GOARCH
+	CPUs      int       `json:"cpus"`
+	Results   []Result  `json:"results"`
+}
+
+// NewReport returns report of res got on commit and current machine
+func NewReport(commit string, res []Result) Report {
+	return Report{
+		Commit:    commit,
+		Date:      time.Now().UTC().Truncate(time.Second),
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+		CPUs:      runtime.NumCPU(),
+		Results:   res,
+	}
+}
+
+// ReadReport reads report written by WriteJSON
+func ReadReport(fName string) (Report, error) {
+	f, err := os.Open(fName)
+	if err != nil {
+		return Report{}, err
+	}
+	defer f.Close()
+	rep := Report{}
+	if err = json.NewDecoder(f).Decode(&rep); err != nil {
+		return Report{}, fmt.Errorf("failed to read report %s: %w", fName, err)
+	}
+	return rep, nil
+}
+
+// qualitySteps is amt of tones swept to measure SNR - less than freqresp default not to wait for minutes
+const qualitySteps = 20
+
+// AddQuality sets SNRDB of every 1 wave result of res by sweeping tones through its resampler (see freqresp.Sweep)
+func AddQuality(res []Result) error {
+	cfg := freqresp.Config{}.NewDefault()
+	cfg.Steps = qualitySteps
+	for i, r := range res {
+		if r.OutRate2 != 0 {
+			continue
+		}
+		rsmT, ok := rsmTOfName(r.RsmT)
+		if !ok {
+			continue
+		}
+		sw, err := freqresp.Sweep(rsmT, r.InRate, r.OutRate, cfg)
+		if err != nil {
+			return err
+		}
+		snr := -sw.Summary.MaxAliasDB
+		res[i].SNRDB = &snr
+	}
+	return nil
+}
+
+func rsmTOfName(name string) (goresampler.ResamplerT, bool) {
+	for _, rsmT := range []goresampler.ResamplerT{goresampler.ResamplerConstExprT, goresampler.ResamplerSplineT, goresampler.ResamplerFFtT} {
+		if rsmT.String() == name {
+			return rsmT, true
+		}
+	}
+	return 0, false
+}
+
+// Tolerance describes how much worse results can be not to be treated as regression
+type Tolerance struct {
+	Speed float64 // relative growth of ns/sample (0.1 - 10% slower is fine)
+	SNRDB float64 // drop of SNR in dB
+}
+
+// Diff is comparison of 1 resampler and rate pair of 2 reports
+type Diff struct {
+	Key                string
+	OldNs, NewNs       float64
+	SpeedChange        float64 // relative change of ns/sample (> 0 - slower)
+	OldSNRDB, NewSNRDB *float64
+	Status             string // "added" or "removed" if only new or only old report has result, else empty
+	Regression         string // empty if results are fine, else what got worse ("removed" for removed results)
+}
+
+// key identifies resampler and rates of r among results of reports
+func (r Result) key() string {
+	return fmt.Sprintf("%s %d->%s", r.RsmT, r.InRate, r.outRates())
+}
+
+// Compare returns diffs of results of reports (sorted by key) - results only one report has are marked by Diff.Status
+//
+// reports are expected to be got on same machine (see Comparable)
+func Compare(oldRep, newRep Report, tol Tolerance) []Diff {
+	olds := make(map[string]Result, len(oldRep.Results))
+	for _, r := range oldRep.Results {
+		olds[r.key()] = r
+	}
+
+	res := make([]Diff, 0)
+	for _, cur := range newRep.Results {
+		old, ok := olds[cur.key()]
+		if !ok {
+			res = append(res, Diff{Key: cur.key(), NewNs: cur.NsPerSample, NewSNRDB: cur.SNRDB, Status: "added"})
+			continue
+		}
+		delete(olds, cur.key())
+		d := Diff{Key: cur.key(), OldNs: old.NsPerSample, NewNs: cur.NsPerSample, OldSNRDB: old.SNRDB, NewSNRDB: cur.SNRDB}
+		d.SpeedChange = cur.NsPerSample/old.NsPerSample - 1
+		if d.SpeedChange > tol.Speed {
+			d.Regression = "speed"
+		}
+		if old.SNRDB != nil && cur.SNRDB != nil && *cur.SNRDB < *old.SNRDB-tol.SNRDB {
+			if d.Regression != "" {
+				d.Regression += ","
+			}
+			d.Regression += "snr"
+		}
+		res = append(res, d)
+	}
+	for key, old := range olds { // resampler or rate pair is not benchmarked any more
+		res = append(res, Diff{Key: key, OldNs: old.NsPerSample, OldSNRDB: old.SNRDB, Status: "removed", Regression: "removed"})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Key < res[j].Key })
+	return res
+}
+
+// Comparable returns error matching ErrNotComparable if reports are got on different platforms or amt of cpus
+// - their speed is not comparable then
+func Comparable(oldRep, newRep Report) error {
+	if oldRep.Platform != newRep.Platform || oldRep.CPUs != newRep.CPUs {
+		return fmt.Errorf("%w: %s with %d cpus and %s with %d cpus", ErrNotComparable, oldRep.Platform, oldRep.CPUs, newRep.Platform, newRep.CPUs)
+	}
+	return nil
+}
+
+// Regressions returns amt of diffs that are regressions
+func Regressions(diffs []Diff) int {
+	res := 0
+	for _, d := range diffs {
+		if d.Regression != "" {
+			res++
+		}
+	}
+	return res
+}
+
+// WriteDiffTable writes diffs as aligned text table
+func WriteDiffTable(w io.Writer, oldRep, newRep Report, diffs []Diff) error {
+	if _, err := fmt.Fprintf(w, "old: %s (%s, %d cpus, %s)\nnew: %s (%s, %d cpus, %s)\n", oldRep.Commit, oldRep.Platform, oldRep.CPUs, oldRep.GoVersion,
+		newRep.Commit, newRep.Platform, newRep.CPUs, newRep.GoVersion); err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	if _, err := fmt.Fprintln(tw, "resampler\told ns/sample\tnew ns/sample\tchange\told snr_db\tnew snr_db\tregression\t"); err != nil {
+		return err
+	}
+	for _, d := range diffs {
+		oldNs, newNs, change := fmt.Sprintf("%.2f", d.OldNs), fmt.Sprintf("%.2f", d.NewNs), fmt.Sprintf("%+.1f%%", d.SpeedChange*100)
+		switch d.Status {
+		case "added":
+			oldNs, change = "-", d.Status
+		case "removed":
+			newNs, change = "-", d.Status
+		}
+		_, err := fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n",
+			d.Key, oldNs, newNs, change, fmtSNR(d.OldSNRDB), fmtSNR(d.NewSNRDB), d.Regression)
+		if err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
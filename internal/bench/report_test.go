@@ -0,0 +1,56 @@
+package bench
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompare(t *testing.T) {
+	snr := func(x float64) *float64 { return &x }
+	oldRep := Report{Commit: "old", Results: []Result{
+		{RsmT: "Spline_resampler", InRate: 48000, OutRate: 16000, NsPerSample: 40, SNRDB: snr(80)},
+		{RsmT: "Spline_resampler", InRate: 44100, OutRate: 16000, NsPerSample: 40, SNRDB: snr(80)},
+		{RsmT: "FFT_resampler", InRate: 48000, OutRate: 16000, NsPerSample: 1000},
+		{RsmT: "Spline_resampler_2waves", InRate: 48000, OutRate: 8000, OutRate2: 16000, NsPerSample: 50},
+		{RsmT: "Const_expression_resampler", InRate: 16000, OutRate: 8000, NsPerSample: 10}, // not in new one
+	}}
+	newRep := Report{Commit: "new", Results: []Result{
+		{RsmT: "Spline_resampler", InRate: 48000, OutRate: 16000, NsPerSample: 43, SNRDB: snr(79.5)}, // in tolerance
+		{RsmT: "Spline_resampler", InRate: 44100, OutRate: 16000, NsPerSample: 50, SNRDB: snr(70)},
+		{RsmT: "FFT_resampler", InRate: 48000, OutRate: 16000, NsPerSample: 500, SNRDB: snr(100)}, // old one has no snr
+		{RsmT: "Spline_resampler_2waves", InRate: 48000, OutRate: 8000, OutRate2: 16000, NsPerSample: 60},
+		{RsmT: "FFT_resampler", InRate: 8000, OutRate: 16000, NsPerSample: 60}, // not in old one
+	}}
+
+	diffs := Compare(oldRep, newRep, Tolerance{Speed: 0.1, SNRDB: 1})
+	got := make(map[string]string)
+	for _, d := range diffs {
+		got[d.Key] = d.Regression
+	}
+	assert.Equal(t, map[string]string{
+		"Const_expression_resampler 16000->8000":    "removed",
+		"FFT_resampler 48000->16000":                "",
+		"FFT_resampler 8000->16000":                 "",
+		"Spline_resampler 44100->16000":             "speed,snr",
+		"Spline_resampler 48000->16000":             "",
+		"Spline_resampler_2waves 48000->8000+16000": "speed",
+	}, got)
+	assert.Equal(t, 3, Regressions(diffs))
+	assert.Equal(t, "removed", diffs[0].Status)
+	assert.Equal(t, "added", diffs[2].Status)
+	assert.InDelta(t, -0.5, diffs[1].SpeedChange, 1e-9)
+
+	var table bytes.Buffer
+	assert.NoError(t, WriteDiffTable(&table, oldRep, newRep, diffs))
+	assert.Contains(t, table.String(), "removed")
+	assert.Contains(t, table.String(), "added")
+}
+
+func TestComparable(t *testing.T) {
+	rep := Report{Platform: "linux/amd64", CPUs: 8}
+	assert.NoError(t, Comparable(rep, rep))
+	assert.ErrorIs(t, Comparable(rep, Report{Platform: "darwin/arm64", CPUs: 8}), ErrNotComparable)
+	assert.ErrorIs(t, Comparable(rep, Report{Platform: "linux/amd64", CPUs: 4}), ErrNotComparable)
+}
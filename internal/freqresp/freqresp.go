@@ -0,0 +1,208 @@
+package freqresp
+
+import (
+	"errors"
+	"math"
+
+	"github.com/lehatrutenb/goresampler"
+	"github.com/lehatrutenb/goresampler/internal/spectrum"
+	"github.com/lehatrutenb/goresampler/internal/utils"
+)
+
+var (
+	ErrTooShortOutput = errors.New("resampled wave is too short to analyse with given fft size")
+)
+
+const passbandEdge = 0.9         // part of min(inRate, outRate)/2 counted as passband
+const transientSkipS = 0.05      // skip beginning of output not to measure filters warm up
+const minAnalysedFreqHz = 50.0   // lower freqs badly fit in fft window
+const maxAnalysedFreqPart = 0.98 // part of inRate/2 up to which tones are swept
+
+// Config describes how to sweep tones through resamplers
+type Config struct {
+	Steps     int     // amt of tones per sweep
+	Amplitude float64 // tone amplitude (0..1] of full scale
+	FFTSize   int     // len of analysed output wave (must be power of 2)
+	RsmTs     []goresampler.ResamplerT
+	InRates   []int
+	OutRates  []int
+}
+
+func (Config) NewDefault() Config {
+	return Config{
+		Steps:     60,
+		Amplitude: 0.5,
+		FFTSize:   1 << 13,
+		RsmTs:     []goresampler.ResamplerT{goresampler.ResamplerConstExprT, goresampler.ResamplerSplineT, goresampler.ResamplerFFtT},
+		InRates:   []int{8000, 11000, 11025, 16000, 44000, 44100, 48000},
+		OutRates:  []int{8000, 16000},
+	}
+}
+
+const (
+	BandPass       = "pass"       // tone is expected to be kept as is
+	BandTransition = "transition" // tone is near nyquist of one of rates - not counted in Summary
+	BandStop       = "stop"       // tone is noticeably higher than nyquist of out rate - expected to be removed
+)
+
+// Point is result of resampling of 1 tone
+type Point struct {
+	FreqHz    float64 `json:"freq_hz"`
+	Band      string  `json:"band"`       // BandPass, BandTransition or BandStop
+	GainDB    float64 `json:"gain_db"`    // power of tone in output relative to input ; for stopband tones - power of whole output
+	AliasDB   float64 `json:"alias_db"`   // power of everything except tone relative to input tone power
+	InBandDB  float64 `json:"in_band_db"` // power of output bins that are not near tone freq relative to tone in output (dBc)
+	ToneBinHz float64 `json:"tone_bin_hz"`
+}
+
+// Summary is short description of Point-s of 1 sweep
+type Summary struct {
+	PassbandRippleDB    float64 `json:"passband_ripple_db"`    // max-min of GainDB in passband
+	PassbandMinGainDB   float64 `json:"passband_min_gain_db"`  // min GainDB in passband
+	StopbandRejectionDB float64 `json:"stopband_rejection_db"` // -max GainDB in stopband (0 if no stopband)
+	MaxAliasDB          float64 `json:"max_alias_db"`          // max AliasDB in passband
+}
+
+// Result of sweep for 1 resampler and rate pair
+type Result struct {
+	RsmT    string  `json:"resampler"`
+	InRate  int     `json:"in_rate"`
+	OutRate int     `json:"out_rate"`
+	Points  []Point `json:"points"`
+	Summary Summary `json:"summary"`
+}
+
+func genTone(freq, amp float64, rate, amt int) []int16 {
+	res := make([]int16, amt)
+	for i := 0; i < amt; i++ {
+		res[i] = utils.Float64ToS16(amp * math.Sin(2*math.Pi*freq*float64(i)/float64(rate)))
+	}
+	return res
+}
+
+// aliasFreq returns frequency that tone freq will have after sampling with rate
+func aliasFreq(freq float64, rate int) float64 {
+	f := math.Mod(freq, float64(rate))
+	if f > float64(rate)/2 {
+		f = float64(rate) - f
+	}
+	return f
+}
+
+func measureTone(rsm goresampler.Resampler, freq float64, inRate, outRate int, cfg Config) (Point, error) {
+	skip := int(transientSkipS * float64(outRate))
+	inAmt, outAmt := rsm.CalcInOutSamplesPerOutAmt(skip + cfg.FFTSize)
+	in := genTone(freq, cfg.Amplitude, inRate, inAmt)
+	out := make([]int16, outAmt)
+	rsm.Reset()
+	if err := rsm.Resample(in, out); err != nil {
+		return Point{}, err
+	}
+	if len(out) < skip+cfg.FFTSize {
+		return Point{}, ErrTooShortOutput
+	}
+
+	pw := spectrum.Power(utils.AS16ToFloat64(out[skip : skip+cfg.FFTSize]))
+	ref := spectrum.ToneRefPower(cfg.FFTSize, cfg.Amplitude)
+
+	band := BandTransition
+	if freq <= passbandEdge*float64(min(inRate, outRate))/2 {
+		band = BandPass
+	} else if freq >= (2-passbandEdge)*float64(outRate)/2 { // symmetric to passband transition width
+		band = BandStop
+	}
+	toneFreq := aliasFreq(freq, outRate)
+	toneBin := spectrum.FreqBin(toneFreq, cfg.FFTSize, outRate)
+	total := spectrum.Sum(pw, 1, len(pw)-1) // not count DC
+	tone := spectrum.Sum(pw, toneBin-spectrum.ToneHalfWidth, toneBin+spectrum.ToneHalfWidth)
+	rest := max(total-tone, 0)
+
+	pt := Point{FreqHz: freq, Band: band, ToneBinHz: spectrum.BinFreq(toneBin, cfg.FFTSize, outRate)}
+	if band != BandStop {
+		pt.GainDB = spectrum.DB(tone / ref)
+		pt.AliasDB = spectrum.DB(rest / ref)
+		pt.InBandDB = spectrum.DB(rest / max(tone, 1e-20))
+	} else { // ideal resampler outputs silence - so every output is alias
+		pt.GainDB = spectrum.DB(total / ref)
+		pt.AliasDB = pt.GainDB
+		pt.InBandDB = 0
+	}
+	return pt, nil
+}
+
+func summarize(pts []Point) Summary {
+	res := Summary{PassbandMinGainDB: math.Inf(1), MaxAliasDB: math.Inf(-1)}
+	maxGain := math.Inf(-1)
+	maxStop := math.Inf(-1)
+	for _, pt := range pts {
+		switch pt.Band {
+		case BandPass:
+			res.PassbandMinGainDB = min(res.PassbandMinGainDB, pt.GainDB)
+			maxGain = max(maxGain, pt.GainDB)
+			res.MaxAliasDB = max(res.MaxAliasDB, pt.AliasDB)
+		case BandStop:
+			maxStop = max(maxStop, pt.GainDB)
+		}
+	}
+	if !math.IsInf(maxGain, -1) {
+		res.PassbandRippleDB = maxGain - res.PassbandMinGainDB
+	} else {
+		res.PassbandMinGainDB = 0
+		res.MaxAliasDB = 0
+	}
+	if !math.IsInf(maxStop, -1) {
+		res.StopbandRejectionDB = -maxStop
+	}
+	return res
+}
+
+// Sweep sends cfg.Steps tones from ~0 to inRate/2 through rsmT resampler and measures them
+func Sweep(rsmT goresampler.ResamplerT, inRate, outRate int, cfg Config) (Result, error) {
+	rsm, _, err := goresampler.NewResamplerAuto(inRate, outRate, rsmT, nil)
+	if err != nil {
+		return Result{}, err
+	}
+
+	res := Result{RsmT: rsmT.String(), InRate: inRate, OutRate: outRate, Points: make([]Point, 0, cfg.Steps)}
+	maxFreq := maxAnalysedFreqPart * float64(inRate) / 2
+	for i := 0; i < cfg.Steps; i++ {
+		freq := minAnalysedFreqHz + (maxFreq-minAnalysedFreqHz)*float64(i)/float64(max(cfg.Steps-1, 1))
+		pt, err := measureTone(rsm, freq, inRate, outRate, cfg)
+		if err != nil {
+			return Result{}, err
+		}
+		res.Points = append(res.Points, pt)
+	}
+	res.Summary = summarize(res.Points)
+	return res, nil
+}
+
+// safe returns true if rsmT converts inRate to outRate with strict rates (see goresampler.Capabilities)
+func safe(rsmT goresampler.ResamplerT, inRate, outRate int) bool {
+	for _, c := range goresampler.Capabilities(inRate, outRate) {
+		if c.Type == rsmT {
+			return c.Safe
+		}
+	}
+	return false
+}
+
+// Run calls Sweep for every cfg resampler and rate pair it converts with strict rates (except not changing ones)
+func Run(cfg Config) ([]Result, error) {
+	res := make([]Result, 0)
+	for _, rsmT := range cfg.RsmTs {
+		for _, inRate := range cfg.InRates {
+			for _, outRate := range cfg.OutRates {
+				if !safe(rsmT, inRate, outRate) {
+					continue
+				}
+				cur, err := Sweep(rsmT, inRate, outRate, cfg)
+				if err != nil {
+					return nil, err
+				}
+				res = append(res, cur)
+			}
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,38 @@
+package freqresp
+
+import (
+	"testing"
+
+	"github.com/lehatrutenb/goresampler"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAliasFreq(t *testing.T) {
+	for _, tt := range []struct {
+		freq float64
+		rate int
+		want float64
+	}{
+		{1000, 8000, 1000},
+		{4000, 8000, 4000}, // nyquist stays
+		{5000, 8000, 3000},
+		{7900, 8000, 100},
+		{8000, 8000, 0},
+		{9000, 8000, 1000},
+		{13000, 8000, 3000},
+		{20000, 44100, 20000},
+		{30000, 44100, 14100},
+	} {
+		assert.InDelta(t, tt.want, aliasFreq(tt.freq, tt.rate), 1e-9, "%f at %d", tt.freq, tt.rate)
+	}
+}
+
+func TestSafe(t *testing.T) {
+	assert.True(t, safe(goresampler.ResamplerConstExprT, 11000, 8000))
+	assert.False(t, safe(goresampler.ResamplerConstExprT, 11025, 8000), "there is no const expression resampler of 11025")
+	assert.False(t, safe(goresampler.ResamplerSplineT, 11000, 8000), "only const expression resampler converts 11000")
+	assert.True(t, safe(goresampler.ResamplerSplineT, 8000, 16000))
+	assert.False(t, safe(goresampler.ResamplerFFtT, 8000, 16000), "fft resampler doesn't increase rate")
+	assert.False(t, safe(goresampler.ResamplerSplineT, 16000, 16000), "not changing rates are not swept")
+}
@@ -0,0 +1,130 @@
+package freqresp
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"io"
+	"path/filepath"
+	"strconv"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+)
+
+// WriteJSON writes res as json array
+func WriteJSON(w io.Writer, res []Result) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(res)
+}
+
+// WriteCSV writes 1 line per measured tone
+func WriteCSV(w io.Writer, res []Result) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"resampler", "in_rate", "out_rate", "freq_hz", "band", "gain_db", "alias_db", "in_band_db"}); err != nil {
+		return err
+	}
+	fl := func(x float64) string {
+		return strconv.FormatFloat(x, 'f', 3, 64)
+	}
+	for _, r := range res {
+		for _, pt := range r.Points {
+			err := cw.Write([]string{r.RsmT, strconv.Itoa(r.InRate), strconv.Itoa(r.OutRate), fl(pt.FreqHz), pt.Band, fl(pt.GainDB), fl(pt.AliasDB), fl(pt.InBandDB)})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteSummaryCSV writes 1 line per sweep with its Summary
+func WriteSummaryCSV(w io.Writer, res []Result) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"resampler", "in_rate", "out_rate", "passband_ripple_db", "passband_min_gain_db", "stopband_rejection_db", "max_alias_db"}); err != nil {
+		return err
+	}
+	fl := func(x float64) string {
+		return strconv.FormatFloat(x, 'f', 3, 64)
+	}
+	for _, r := range res {
+		s := r.Summary
+		err := cw.Write([]string{r.RsmT, strconv.Itoa(r.InRate), strconv.Itoa(r.OutRate), fl(s.PassbandRippleDB), fl(s.PassbandMinGainDB), fl(s.StopbandRejectionDB), fl(s.MaxAliasDB)})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func PlotName(r Result) string {
+	return fmt.Sprintf("%s_%dto%d.png", r.RsmT, r.InRate, r.OutRate)
+}
+
+func addLine(p *plot.Plot, xs, ys []float64, clr color.Color, name string) error {
+	pts := make(plotter.XYs, len(xs))
+	for i := range xs {
+		pts[i].X, pts[i].Y = xs[i], ys[i]
+	}
+	l, err := plotter.NewLine(pts)
+	if err != nil {
+		return err
+	}
+	l.Color = clr
+	p.Add(l)
+	p.Legend.Add(name, l)
+	return nil
+}
+
+// SavePlot draws gain and alias curves of r to fName (format is got from extension)
+func SavePlot(fName string, r Result) error {
+	p := plot.New()
+	p.Title.Text = fmt.Sprintf("%s %d -> %d", r.RsmT, r.InRate, r.OutRate)
+	p.X.Label.Text = "input tone, Hz"
+	p.Y.Label.Text = "dB"
+	p.Y.Min = -120
+	p.Y.Max = 10
+	p.Add(plotter.NewGrid())
+
+	xs := make([]float64, len(r.Points))
+	gains := make([]float64, len(r.Points))
+	aliases := make([]float64, len(r.Points))
+	for i, pt := range r.Points {
+		xs[i] = pt.FreqHz
+		gains[i] = max(pt.GainDB, p.Y.Min)
+		aliases[i] = max(pt.AliasDB, p.Y.Min)
+	}
+	if err := addLine(p, xs, gains, color.RGBA{B: 200, A: 255}, "gain"); err != nil {
+		return err
+	}
+	if err := addLine(p, xs, aliases, color.RGBA{R: 200, A: 255}, "alias + distortion"); err != nil {
+		return err
+	}
+
+	nyq, err := plotter.NewLine(plotter.XYs{{X: float64(r.OutRate) / 2, Y: p.Y.Min}, {X: float64(r.OutRate) / 2, Y: p.Y.Max}})
+	if err != nil {
+		return err
+	}
+	nyq.Dashes = []vg.Length{vg.Points(4), vg.Points(4)}
+	p.Add(nyq)
+	p.Legend.Add("out nyquist", nyq)
+	p.Legend.Top = false
+	p.Legend.Left = true
+
+	return p.Save(8*vg.Inch, 5*vg.Inch, fName)
+}
+
+// SavePlots calls SavePlot for every r in res and saves them in dir with PlotName names
+func SavePlots(dir string, res []Result) error {
+	for _, r := range res {
+		if err := SavePlot(filepath.Join(dir, PlotName(r)), r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
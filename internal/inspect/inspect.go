@@ -0,0 +1,131 @@
+// Package inspect compares resampled wave with reference one - aligns them, counts errors and draws report to triage without listening
+package inspect
+
+import (
+	"errors"
+	"math"
+	"math/cmplx"
+
+	"github.com/lehatrutenb/goresampler/internal/utils"
+
+	"github.com/mjibson/go-dsp/fft"
+)
+
+var (
+	ErrNoOverlap = errors.New("waves have no common samples after alignment")
+)
+
+// Config describes how to align waves
+type Config struct {
+	MaxLagS   float64 // max shift of got wave relative to ref one searched (in secs) ; 0 - waves are not aligned
+	AlignDurS float64 // duration of waves beginning cross correlation is calculated on
+}
+
+func (Config) NewDefault() Config {
+	return Config{MaxLagS: 0.5, AlignDurS: 10}
+}
+
+// Result of comparison of ref and got waves
+type Result struct {
+	RefFile    string
+	GotFile    string
+	Rate       int
+	Channels   int
+	Lag        int // got[i+Lag] is aligned with ref[i] (per channel)
+	LagMS      float64
+	Frames     int // amt of compared frames (common part of aligned waves)
+	RefFrames  int
+	GotFrames  int
+	Te         utils.TestErr // same metrics tests count (got compared to ref)
+	SNRDB      float64       // power of ref to power of difference
+	MaxAbsDiff int
+
+	Ref  []int16 // aligned waves (interleaved, Frames frames)
+	Got  []int16
+	Diff []int16 // ref - got (clipped to int16)
+}
+
+// mono returns amt first frames of first channel of wave as complex fft input zero padded to n
+func mono(wave []int16, chAmt, amt, n int) []complex128 {
+	res := make([]complex128, n)
+	for i := 0; i < amt && i*chAmt < len(wave); i++ {
+		res[i] = complex(utils.S16ToFloat64(wave[i*chAmt]), 0)
+	}
+	return res
+}
+
+// Align returns lag (in frames) of got relative to ref that maximizes cross correlation of their first channels
+//
+// only first alignLen frames are used, lags are searched in [-maxLag, maxLag]
+// ; of lags with same correlation (like of silent beginnings) one closest to 0 is returned
+func Align(ref, got []int16, chAmt, maxLag, alignLen int) int {
+	if maxLag <= 0 {
+		return 0
+	}
+	refLen, gotLen := min(len(ref)/chAmt, alignLen), min(len(got)/chAmt, alignLen)
+	n := 1
+	for n < refLen+gotLen {
+		n *= 2
+	}
+	x, y := fft.FFT(mono(ref, chAmt, refLen, n)), fft.FFT(mono(got, chAmt, gotLen, n))
+	for i := range x {
+		x[i] = cmplx.Conj(x[i]) * y[i]
+	}
+	corr := fft.IFFT(x) // corr[k] = sum ref[i] * got[i+k] (negative k are at the end)
+
+	lo, hi := -min(maxLag, refLen-1), min(maxLag, gotLen-1)
+	maxVal, maxAbs := math.Inf(-1), 0.0
+	for k := lo; k <= hi; k++ {
+		v := real(corr[(k+n)%n])
+		maxVal, maxAbs = max(maxVal, v), max(maxAbs, math.Abs(v))
+	}
+	eps := alignTieEps * (maxAbs + 1) // fft gives values of equal correlations not exactly equal
+	best, found := 0, false
+	for k := lo; k <= hi; k++ {
+		if real(corr[(k+n)%n]) >= maxVal-eps && (!found || abs(k) < abs(best)) {
+			best, found = k, true
+		}
+	}
+	return best
+}
+
+// alignTieEps is relative difference of correlations Align treats as equal
+const alignTieEps = 1e-9
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// Compare aligns got wave with ref one (same rate and channels) and counts errors of got
+func Compare(ref, got []int16, rate, chAmt int, cfg Config) (Result, error) {
+	res := Result{Rate: rate, Channels: chAmt, RefFrames: len(ref) / chAmt, GotFrames: len(got) / chAmt}
+	res.Lag = Align(ref, got, chAmt, int(cfg.MaxLagS*float64(rate)), int(cfg.AlignDurS*float64(rate)))
+	res.LagMS = float64(res.Lag) * 1000 / float64(rate)
+
+	refStart, gotStart := 0, res.Lag
+	if res.Lag < 0 {
+		refStart, gotStart = -res.Lag, 0
+	}
+	res.Frames = min(res.RefFrames-refStart, res.GotFrames-gotStart)
+	if res.Frames <= 0 {
+		return Result{}, ErrNoOverlap
+	}
+	res.Ref = ref[refStart*chAmt : (refStart+res.Frames)*chAmt]
+	res.Got = got[gotStart*chAmt : (gotStart+res.Frames)*chAmt]
+
+	res.Te = utils.CalcTestErr(res.Got, res.Ref)
+	res.Diff = make([]int16, len(res.Ref))
+	refPw, diffPw := 0.0, 0.0
+	for i := range res.Ref {
+		d := int(res.Ref[i]) - int(res.Got[i])
+		res.MaxAbsDiff = max(res.MaxAbsDiff, d, -d)
+		res.Diff[i] = int16(max(min(d, math.MaxInt16), math.MinInt16))
+		refPw += float64(res.Ref[i]) * float64(res.Ref[i])
+		diffPw += float64(d) * float64(d)
+	}
+	res.SNRDB = 10 * math.Log10(refPw/diffPw) // +Inf if waves are same
+	return res, nil
+}
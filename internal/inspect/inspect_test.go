@@ -0,0 +1,83 @@
+package inspect
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompare_Lag(t *testing.T) {
+	const rate, chAmt, frames = 8000, 2, 8000
+	rnd := rand.New(rand.NewSource(1))
+	ref := make([]int16, frames*chAmt)
+	for i := range ref {
+		ref[i] = int16(rnd.Intn(20000) - 10000)
+	}
+
+	for _, lag := range []int{0, 37, -120} {
+		got := make([]int16, len(ref))
+		for i := range frames {
+			if j := i - lag; j >= 0 && j < frames {
+				copy(got[i*chAmt:(i+1)*chAmt], ref[j*chAmt:(j+1)*chAmt])
+			}
+		}
+		res, err := Compare(ref, got, rate, chAmt, Config{}.NewDefault())
+		assert.NoError(t, err)
+		assert.Equal(t, lag, res.Lag)
+		assert.Equal(t, frames-max(lag, -lag), res.Frames)
+		assert.Equal(t, 0, res.MaxAbsDiff)
+		assert.True(t, math.IsInf(res.SNRDB, 1))
+	}
+
+	// every lag correlates same on silent beginning - waves are not shifted
+	for _, prefix := range []int16{0, 1000} {
+		cur := append([]int16(nil), ref...)
+		for i := range frames / 2 * chAmt {
+			cur[i] = prefix
+		}
+		res, err := Compare(cur, cur, rate, chAmt, Config{MaxLagS: 0.1, AlignDurS: 0.25})
+		assert.NoError(t, err)
+		assert.Equal(t, 0, res.Lag, "prefix %d", prefix)
+		assert.Equal(t, frames, res.Frames, "prefix %d", prefix)
+	}
+}
+
+func TestOutput(t *testing.T) {
+	const rate, chAmt, frames = 8000, 2, 1024 // drawing is slow - small waves
+	ref := make([]int16, frames*chAmt)
+	got := make([]int16, frames*chAmt)
+	for i := range ref {
+		ref[i] = int16(10000 * math.Sin(float64(i/chAmt)/10))
+		got[i] = ref[i] / 100 * 99
+	}
+	res, err := Compare(ref, got, rate, chAmt, Config{}.NewDefault())
+	if !assert.NoError(t, err) {
+		return
+	}
+	res.RefFile, res.GotFile = "ref.wav", "got <b>.wav"
+
+	png := filepath.Join(t.TempDir(), "report.png")
+	assert.NoError(t, SavePNG(png, res))
+	st, err := os.Stat(png)
+	if assert.NoError(t, err) {
+		assert.Positive(t, st.Size())
+	}
+
+	var html bytes.Buffer
+	assert.NoError(t, WriteHTML(&html, res, "report.png"))
+	assert.Contains(t, html.String(), `<img src="report.png">`)
+	assert.Contains(t, html.String(), "got &lt;b&gt;.wav", "file names are escaped")
+	assert.Contains(t, html.String(), fmtDB(res.SNRDB))
+	html.Reset()
+	assert.NoError(t, WriteHTML(&html, res, ""))
+	assert.NotContains(t, html.String(), "<img")
+
+	var text bytes.Buffer
+	assert.NoError(t, WriteText(&text, res))
+	assert.Contains(t, text.String(), "lag 0 frames")
+}
@@ -0,0 +1,103 @@
+package inspect
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+
+	"github.com/lehatrutenb/goresampler/internal/plots"
+	"github.com/lehatrutenb/goresampler/internal/utils"
+
+	"gonum.org/v1/plot"
+)
+
+// channelPlots returns 2 rows of plots of channel ch of r: waveforms (overlay, difference, error histogram) and spectrograms (ref, got, difference)
+func channelPlots(r Result, ch int) ([][]*plot.Plot, error) {
+	ref, got, diff := utils.GetWithStep(r.Ref, ch, r.Channels), utils.GetWithStep(r.Got, ch, r.Channels), utils.GetWithStep(r.Diff, ch, r.Channels)
+	res := [][]*plot.Plot{make([]*plot.Plot, 3), make([]*plot.Plot, 3)}
+
+	var err error
+	if res[0][0], err = plots.Overlay(fmt.Sprintf("aligned waveforms ch %d", ch), r.Rate, []string{"ref", "got"}, ref, got); err != nil {
+		return nil, err
+	}
+	if res[0][1], err = plots.Overlay(fmt.Sprintf("difference ch %d", ch), r.Rate, []string{"ref - got"}, diff); err != nil {
+		return nil, err
+	}
+	if res[0][2], err = plots.ErrorHistogram(ref, got, 100); err != nil {
+		return nil, err
+	}
+
+	for i, w := range []struct {
+		name string
+		wave []int16
+	}{{"ref", ref}, {"got", got}, {"difference", diff}} {
+		if len(w.wave) < plots.MinSpectrogramLen { // left blank
+			continue
+		}
+		if res[1][i], err = plots.Spectrogram(w.wave, r.Rate, fmt.Sprintf("%s spectrogram ch %d", w.name, ch)); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// SavePNG draws waveforms, difference and spectrograms of every channel of r to png fName
+func SavePNG(fName string, r Result) error {
+	rows := make([][]*plot.Plot, 0, 2*r.Channels)
+	for ch := range r.Channels {
+		cur, err := channelPlots(r, ch)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, cur...)
+	}
+	return plots.SaveGrid(fName, rows)
+}
+
+// fmtDB returns x with 2 digits after point ("inf" if waves are same)
+func fmtDB(x float64) string {
+	if math.IsInf(x, 0) {
+		return "inf"
+	}
+	return fmt.Sprintf("%.2f", x)
+}
+
+var htmlReport = template.Must(template.New("report").Funcs(template.FuncMap{"db": fmtDB, "proc": func(x float64) string {
+	return fmt.Sprintf("%.3f%%", x*100)
+}}).Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>goresampler inspect: {{.R.GotFile}}</title>
+<style>body{font-family:sans-serif} td,th{padding:2px 12px;text-align:left} img{max-width:100%}</style>
+</head>
+<body>
+<h2>{{.R.GotFile}} compared to {{.R.RefFile}}</h2>
+<table>
+<tr><th>rate</th><td>{{.R.Rate}}</td></tr>
+<tr><th>channels</th><td>{{.R.Channels}}</td></tr>
+<tr><th>frames ref / got / compared</th><td>{{.R.RefFrames}} / {{.R.GotFrames}} / {{.R.Frames}}</td></tr>
+<tr><th>lag of got (frames)</th><td>{{.R.Lag}} ({{printf "%.2f" .R.LagMS}} ms)</td></tr>
+<tr><th>snr, dB</th><td>{{db .R.SNRDB}}</td></tr>
+<tr><th>max abs difference</th><td>{{.R.MaxAbsDiff}}</td></tr>
+<tr><th>samples with error higher 1% / 5% / 10% / 20%</th><td>{{proc .R.Te.SqProc1}} / {{proc .R.Te.SqProc5}} / {{proc .R.Te.SqProc10}} / {{proc .R.Te.SqProc20}}</td></tr>
+<tr><th>error squared: sum / mean</th><td>{{printf "%.4g" .R.Te.ErrSqed}} / {{printf "%.4g" .R.Te.ErrMeanSqed}}</td></tr>
+</table>
+{{if .PNG}}<img src="{{.PNG}}">{{end}}
+</body>
+</html>
+`))
+
+// WriteHTML writes metrics of r as html page with png (drawn by SavePNG) embedded by its path relative to page
+func WriteHTML(w io.Writer, r Result, png string) error {
+	return htmlReport.Execute(w, struct {
+		R   Result
+		PNG string
+	}{r, png})
+}
+
+// WriteText writes metrics of r as short text
+func WriteText(w io.Writer, r Result) error {
+	_, err := fmt.Fprintf(w, "lag %d frames (%.2f ms), compared %d frames: snr %s dB, max abs diff %d, error higher 1%%/5%%/10%%/20%%: %.3f%%/%.3f%%/%.3f%%/%.3f%%, mean squared error %.4g\n",
+		r.Lag, r.LagMS, r.Frames, fmtDB(r.SNRDB), r.MaxAbsDiff, r.Te.SqProc1*100, r.Te.SqProc5*100, r.Te.SqProc10*100, r.Te.SqProc20*100, r.Te.ErrMeanSqed)
+	return err
+}
@@ -0,0 +1,319 @@
+// Package plots draws test reports (waveforms, errors, spectrograms) with gonum/plot - so no python is needed
+package plots
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/lehatrutenb/goresampler/internal/spectrum"
+	"github.com/lehatrutenb/goresampler/internal/utils"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/palette/moreland"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgimg"
+)
+
+const maxWaveLen = 60000                     // max amt of samples of 1 channel drawn on waveform (more makes png unreadable)
+const spectrogramFFTSize = 512               // len of 1 frame of spectrogram
+const MinSpectrogramLen = spectrogramFFTSize // min len of wave Spectrogram can draw
+const spectrogramMaxCols = 400               // frames are skipped not to draw too large heatmaps
+const minDB = -120.0                         // lower bound of spectrogram colors
+
+var (
+	inColor      = color.RGBA{R: 120, G: 120, B: 120, A: 255}
+	outColor     = color.RGBA{B: 200, A: 255}
+	correctColor = color.RGBA{R: 200, A: 255}
+)
+
+// Report is resampling result to draw - json of it is same as of test reports saved by TestObj.Save (":large" ones)
+type Report struct {
+	In       []int16 `json:"InWave"`
+	Out      []int16 `json:"Resampeled"`
+	Correct  []int16 `json:"CorrectW"` // nil if correct output is unknown
+	Channels int     `json:"NumChannels"`
+	InRate   int
+	OutRate  int
+}
+
+// ReadReport reads report saved by TestObj.Save
+func ReadReport(fName string) (Report, error) {
+	data, err := os.ReadFile(fName)
+	if err != nil {
+		return Report{}, err
+	}
+	r := Report{}
+	if err = json.Unmarshal(data, &r); err != nil {
+		return Report{}, fmt.Errorf("failed to parse report %s: %w", fName, err)
+	}
+	if r.Channels <= 0 || r.InRate <= 0 || r.OutRate <= 0 {
+		return Report{}, fmt.Errorf("report %s has no rates or channels", fName)
+	}
+	return r, nil
+}
+
+// channel returns samples of channel ch of interleaved wave
+func channel(wave []int16, ch, chAmt int) []int16 {
+	return utils.GetWithStep(wave, ch, chAmt)
+}
+
+func addLine(p *plot.Plot, wave []int16, rate int, clr color.Color, name string) error {
+	pts := make(plotter.XYs, len(wave))
+	for i, x := range wave {
+		pts[i].X, pts[i].Y = float64(i)/float64(rate), float64(x)
+	}
+	l, err := plotter.NewLine(pts)
+	if err != nil {
+		return err
+	}
+	l.Color = clr
+	l.Width = vg.Points(0.5)
+	p.Add(l)
+	p.Legend.Add(name, l)
+	return nil
+}
+
+// Waveform draws overlay of input, output and correct (if it's not nil) waves of 1 channel in time
+//
+// waves are cut to maxWaveLen samples of output (input is cut to same time)
+func Waveform(in []int16, inRate int, out []int16, outRate int, correct []int16) (*plot.Plot, error) {
+	outLen := min(len(out), maxWaveLen)
+	inLen := min(len(in), int(float64(outLen)*float64(inRate)/float64(outRate)))
+
+	p := plot.New()
+	p.Title.Text = fmt.Sprintf("waveform %d -> %d", inRate, outRate)
+	p.X.Label.Text = "time, s"
+	p.Add(plotter.NewGrid())
+	if err := addLine(p, in[:inLen], inRate, inColor, "input"); err != nil {
+		return nil, err
+	}
+	if correct != nil {
+		if err := addLine(p, correct[:min(len(correct), outLen)], outRate, correctColor, "correct"); err != nil {
+			return nil, err
+		}
+	}
+	if err := addLine(p, out[:outLen], outRate, outColor, "resampled"); err != nil {
+		return nil, err
+	}
+	p.Legend.Top = true
+	return p, nil
+}
+
+// Overlay draws waves of same rate over each other (cut to maxWaveLen samples) - names are legend of waves
+func Overlay(title string, rate int, names []string, waves ...[]int16) (*plot.Plot, error) {
+	if len(names) != len(waves) {
+		return nil, fmt.Errorf("got %d names of %d waves", len(names), len(waves))
+	}
+	clrs := []color.Color{correctColor, outColor, inColor}
+	p := plot.New()
+	p.Title.Text = title
+	p.X.Label.Text = "time, s"
+	p.Add(plotter.NewGrid())
+	for i, w := range waves {
+		if err := addLine(p, w[:min(len(w), maxWaveLen)], rate, clrs[i%len(clrs)], names[i]); err != nil {
+			return nil, err
+		}
+	}
+	p.Legend.Top = true
+	return p, nil
+}
+
+// ErrorHistogram draws distribution of signed error (correct - got) of samples both waves have
+func ErrorHistogram(correct, got []int16, bins int) (*plot.Plot, error) {
+	n := min(len(correct), len(got))
+	if n == 0 {
+		return nil, fmt.Errorf("no samples to calc error of")
+	}
+	errs := make(plotter.Values, n)
+	sumAbs, maxAbs := 0.0, 0.0
+	for i := range errs {
+		errs[i] = float64(correct[i]) - float64(got[i])
+		sumAbs += math.Abs(errs[i])
+		maxAbs = max(maxAbs, math.Abs(errs[i]))
+	}
+	h, err := plotter.NewHist(errs, bins)
+	if err != nil {
+		return nil, err
+	}
+	h.FillColor = correctColor
+
+	p := plot.New()
+	p.Title.Text = fmt.Sprintf("signed error: mean abs %.1f, max abs %.0f", sumAbs/float64(n), maxAbs)
+	p.X.Label.Text = "correct - resampled"
+	p.Y.Label.Text = "samples"
+	p.Add(plotter.NewGrid(), h)
+	return p, nil
+}
+
+// spectrogramGrid is power (dBFS) of frames of wave - columns are frames, rows are frequency bins
+type spectrogramGrid struct {
+	db        [][]float64 // [col][row]
+	hop, rate int
+	fftSize   int
+}
+
+func (g spectrogramGrid) Dims() (int, int)   { return len(g.db), len(g.db[0]) }
+func (g spectrogramGrid) Z(c, r int) float64 { return g.db[c][r] }
+func (g spectrogramGrid) X(c int) float64 {
+	return float64(c*g.hop+g.fftSize/2) / float64(g.rate)
+}
+func (g spectrogramGrid) Y(r int) float64 { return spectrum.BinFreq(r, g.fftSize, g.rate) }
+
+// Spectrogram draws power of wave frames (dB of full scale sine) by time and frequency
+func Spectrogram(wave []int16, rate int, title string) (*plot.Plot, error) {
+	if len(wave) < spectrogramFFTSize {
+		return nil, fmt.Errorf("wave is too short for spectrogram: %d < %d", len(wave), spectrogramFFTSize)
+	}
+	hop := max(spectrogramFFTSize/2, (len(wave)-spectrogramFFTSize)/spectrogramMaxCols+1)
+	g := spectrogramGrid{hop: hop, rate: rate, fftSize: spectrogramFFTSize}
+	ref := spectrum.ToneRefPower(spectrogramFFTSize, 1)
+	for start := 0; start+spectrogramFFTSize <= len(wave); start += hop {
+		pw := spectrum.Power(utils.AS16ToFloat64(wave[start : start+spectrogramFFTSize]))
+		col := make([]float64, len(pw))
+		for i, x := range pw {
+			col[i] = max(spectrum.DB(x/ref), minDB)
+		}
+		g.db = append(g.db, col)
+	}
+
+	hm := plotter.NewHeatMap(g, moreland.SmoothBlueRed().Palette(255))
+	hm.Min, hm.Max = minDB, 0
+	hm.Rasterized = true
+
+	p := plot.New()
+	p.Title.Text = title
+	p.X.Label.Text = "time, s"
+	p.Y.Label.Text = "Hz"
+	p.Add(hm)
+	return p, nil
+}
+
+// reportPlots returns plots of r - row per channel: waveform, error histogram (if correct wave is known), spectrogram of output
+func reportPlots(r Report) ([][]*plot.Plot, error) {
+	res := make([][]*plot.Plot, r.Channels)
+	for ch := range res {
+		in, out := channel(r.In, ch, r.Channels), channel(r.Out, ch, r.Channels)
+		var correct []int16
+		if r.Correct != nil {
+			correct = channel(r.Correct, ch, r.Channels)
+		}
+		res[ch] = make([]*plot.Plot, 3)
+
+		var err error
+		if res[ch][0], err = Waveform(in, r.InRate, out, r.OutRate, correct); err != nil {
+			return nil, err
+		}
+		res[ch][0].Title.Text += fmt.Sprintf(" ch %d", ch)
+		if correct != nil {
+			if res[ch][1], err = ErrorHistogram(correct, out, 100); err != nil {
+				return nil, err
+			}
+		}
+		if len(out) >= MinSpectrogramLen {
+			if res[ch][2], err = Spectrogram(out, r.OutRate, fmt.Sprintf("resampled spectrogram ch %d", ch)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return res, nil
+}
+
+// SaveReport draws waveform, error histogram and spectrogram of every channel of r to png fName
+func SaveReport(fName string, r Report) error {
+	plots, err := reportPlots(r)
+	if err != nil {
+		return err
+	}
+	return SaveGrid(fName, plots)
+}
+
+// SaveGrid draws plots as table (rows of same len, nil plots are left blank) to png fName
+func SaveGrid(fName string, plots [][]*plot.Plot) error {
+	if len(plots) == 0 || len(plots[0]) == 0 {
+		return fmt.Errorf("no plots to draw")
+	}
+	cols := len(plots[0])
+	const colW, rowH = 8 * vg.Inch, 4 * vg.Inch
+	img := vgimg.New(colW*vg.Length(cols), rowH*vg.Length(len(plots)))
+	dc := draw.New(img)
+	t := draw.Tiles{Rows: len(plots), Cols: cols, PadX: vg.Millimeter, PadY: vg.Millimeter,
+		PadTop: vg.Points(2), PadBottom: vg.Points(2), PadLeft: vg.Points(2), PadRight: vg.Points(2)}
+	canvases := plot.Align(plots, t, dc)
+	for i := range plots {
+		for j, p := range plots[i] {
+			if p != nil {
+				p.Draw(canvases[i][j])
+			}
+		}
+	}
+
+	f, err := os.Create(fName)
+	if err != nil {
+		return err
+	}
+	if _, err = (vgimg.PngCanvas{Canvas: img}).WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// SaveReports draws every report (":large" file) of subdirs of inDir to png with same name in same subdir of outDir
+//
+// reports are drawn by workers goroutines, first error is returned after all of them are done
+func SaveReports(inDir, outDir string, workers int) error {
+	subDirs, err := os.ReadDir(inDir)
+	if err != nil {
+		return err
+	}
+	jobs := make([][2]string, 0)
+	for _, sub := range subDirs {
+		if !sub.IsDir() {
+			continue
+		}
+		files, err := os.ReadDir(filepath.Join(inDir, sub.Name()))
+		if err != nil {
+			return err
+		}
+		if err = os.MkdirAll(filepath.Join(outDir, sub.Name()), 0755); err != nil {
+			return err
+		}
+		for _, f := range files {
+			if strings.HasSuffix(f.Name(), ":large") {
+				jobs = append(jobs, [2]string{filepath.Join(inDir, sub.Name(), f.Name()), filepath.Join(outDir, sub.Name(), f.Name()+".png")})
+			}
+		}
+	}
+
+	errs := make([]error, len(jobs))
+	inds := make(chan int)
+	wg := sync.WaitGroup{}
+	for range max(workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range inds {
+				r, err := ReadReport(jobs[i][0])
+				if err == nil {
+					err = SaveReport(jobs[i][1], r)
+				}
+				errs[i] = err
+			}
+		}()
+	}
+	for i := range jobs {
+		inds <- i
+	}
+	close(inds)
+	wg.Wait()
+	return errors.Join(errs...)
+}
@@ -0,0 +1,76 @@
+package plots
+
+import (
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sinWave(rate, amt, chAmt int, freq float64) []int16 {
+	res := make([]int16, amt*chAmt)
+	for i := range res {
+		res[i] = int16(10000 * math.Sin(2*math.Pi*freq*float64(i/chAmt)/float64(rate)))
+	}
+	return res
+}
+
+// checkPNG checks that fName is png of at least minW x minH pixels
+func checkPNG(t *testing.T, fName string, minW, minH int) {
+	f, err := os.Open(fName)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer f.Close()
+	cfg, err := png.DecodeConfig(f)
+	if assert.NoError(t, err) {
+		assert.GreaterOrEqual(t, cfg.Width, minW)
+		assert.GreaterOrEqual(t, cfg.Height, minH)
+	}
+}
+
+func TestSaveReport(t *testing.T) {
+	dir := t.TempDir()
+	r := Report{In: sinWave(48000, 4800, 2, 440), Out: sinWave(16000, 1600, 2, 440), Correct: sinWave(16000, 1600, 2, 440),
+		Channels: 2, InRate: 48000, OutRate: 16000}
+	r.Out[100] += 5000 // some error to draw
+	assert.NoError(t, SaveReport(filepath.Join(dir, "report.png"), r))
+	checkPNG(t, filepath.Join(dir, "report.png"), 3*100, 2*100) // row per channel, 3 plots in row
+
+	short := Report{In: sinWave(8000, 100, 1, 440), Out: sinWave(16000, 200, 1, 440), Channels: 1, InRate: 8000, OutRate: 16000}
+	assert.NoError(t, SaveReport(filepath.Join(dir, "short.png"), short), "no correct wave and too short for spectrogram - left blank")
+	checkPNG(t, filepath.Join(dir, "short.png"), 100, 100)
+
+	assert.Error(t, SaveReport(filepath.Join(dir, "no", "such", "dir.png"), short))
+	assert.Error(t, SaveGrid(filepath.Join(dir, "empty.png"), nil))
+}
+
+func TestSpectrogram(t *testing.T) {
+	_, err := Spectrogram(make([]int16, MinSpectrogramLen-1), 16000, "short")
+	assert.Error(t, err)
+
+	p, err := Spectrogram(sinWave(16000, 16000, 1, 1000), 16000, "1kHz")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "1kHz", p.Title.Text)
+	assert.InDelta(t, 8000, p.Y.Max, 100, "frequencies up to nyquist")
+	assert.InDelta(t, 1, p.X.Max, 0.1, "1s of wave")
+}
+
+func TestReadReport(t *testing.T) {
+	dir := t.TempDir()
+	fName := filepath.Join(dir, "r:large")
+	assert.NoError(t, os.WriteFile(fName, []byte(`{"InWave": [1, 2], "Resampeled": [3], "NumChannels": 1, "InRate": 16000, "OutRate": 8000}`), 0o644))
+	r, err := ReadReport(fName)
+	if assert.NoError(t, err) {
+		assert.Equal(t, Report{In: []int16{1, 2}, Out: []int16{3}, Channels: 1, InRate: 16000, OutRate: 8000}, r)
+	}
+
+	assert.NoError(t, os.WriteFile(fName, []byte(`{"InWave": [1, 2]}`), 0o644))
+	_, err = ReadReport(fName)
+	assert.Error(t, err, "no rates")
+}
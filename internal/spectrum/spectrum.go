@@ -0,0 +1,88 @@
+package spectrum
+
+import (
+	"math"
+	"math/cmplx"
+
+	"github.com/mjibson/go-dsp/fft"
+)
+
+// ToneHalfWidth is amt of bins on each side of tone bin that keep almost all tone power after Window
+const ToneHalfWidth = 6
+
+// Window returns 4 term Blackman-Harris window of len n
+//
+// not used Hann from go-dsp cause it's sidelobes (~-31dB) hide aliasing of good resamplers
+func Window(n int) []float64 {
+	const a0, a1, a2, a3 = 0.35875, 0.48829, 0.14128, 0.01168
+	res := make([]float64, n)
+	if n == 1 {
+		res[0] = 1
+		return res
+	}
+	for i := 0; i < n; i++ {
+		x := 2 * math.Pi * float64(i) / float64(n-1)
+		res[i] = a0 - a1*math.Cos(x) + a2*math.Cos(2*x) - a3*math.Cos(3*x)
+	}
+	return res
+}
+
+// Power returns one-sided power spectrum (len(x)/2+1 bins) of x multiplied by Window
+//
+// for real sine with amplitude a power of bins near it (ToneHalfWidth) is ~ ToneRefPower(len(x), a)
+func Power(x []float64) []float64 {
+	n := len(x)
+	w := Window(n)
+	buf := make([]float64, n)
+	for i := 0; i < n; i++ {
+		buf[i] = x[i] * w[i]
+	}
+
+	spec := fft.FFTReal(buf)
+	res := make([]float64, n/2+1)
+	for i := 0; i < len(res); i++ {
+		a := cmplx.Abs(spec[i])
+		res[i] = a * a
+		if i != 0 && i*2 != n { // fold mirrored half not to loose half of energy
+			res[i] *= 2
+		}
+	}
+	return res
+}
+
+// ToneRefPower returns expected summary power of Power() bins of sine with amplitude amp and len n
+func ToneRefPower(n int, amp float64) float64 {
+	w := Window(n)
+	sumSq := 0.0
+	for _, v := range w {
+		sumSq += v * v
+	}
+	// Parseval: sum(|X|^2) = n * sum((w*x)^2) ; mean of sin^2 is 1/2
+	return float64(n) * sumSq * amp * amp / 2
+}
+
+// BinFreq returns frequency (in Hz) of bin ind of spectrum got from wave of len n with given rate
+func BinFreq(ind, n, rate int) float64 {
+	return float64(ind) * float64(rate) / float64(n)
+}
+
+// FreqBin returns closest bin to freq (in Hz) of spectrum got from wave of len n with given rate
+func FreqBin(freq float64, n, rate int) int {
+	return int(math.Round(freq * float64(n) / float64(rate)))
+}
+
+// Sum returns sum of pw bins in [l; r] (bounds are cut to fit pw)
+func Sum(pw []float64, l, r int) float64 {
+	l = max(l, 0)
+	r = min(r, len(pw)-1)
+	res := 0.0
+	for i := l; i <= r; i++ {
+		res += pw[i]
+	}
+	return res
+}
+
+// DB returns 10*log10(x) with floor not to get -inf on silence
+func DB(x float64) float64 {
+	return 10 * math.Log10(max(x, 1e-20))
+}
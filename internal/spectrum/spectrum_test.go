@@ -0,0 +1,48 @@
+package spectrum
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sine(freq, amp float64, rate, n int) []float64 {
+	res := make([]float64, n)
+	for i := range res {
+		res[i] = amp * math.Sin(2*math.Pi*freq*float64(i)/float64(rate))
+	}
+	return res
+}
+
+func TestPower_Tone(t *testing.T) {
+	const rate, n = 8000, 1 << 12
+	for _, tt := range []struct {
+		freq, amp float64
+	}{
+		{1000, 0.5},
+		{1234.5, 1}, // not on bin centre
+		{3210, 0.1},
+	} {
+		pw := Power(sine(tt.freq, tt.amp, rate, n))
+		assert.Len(t, pw, n/2+1)
+
+		bin := FreqBin(tt.freq, n, rate)
+		tone := Sum(pw, bin-ToneHalfWidth, bin+ToneHalfWidth)
+		assert.InDelta(t, 0, DB(tone)-DB(ToneRefPower(n, tt.amp)), 0.1, "freq %f", tt.freq)
+		assert.Less(t, DB(Sum(pw, 0, len(pw)-1)-tone)-DB(tone), -80.0, "leakage out of tone bins of freq %f", tt.freq)
+	}
+}
+
+func TestPower_Silence(t *testing.T) {
+	pw := Power(make([]float64, 256))
+	assert.Equal(t, 0.0, Sum(pw, 0, len(pw)-1))
+	assert.Equal(t, -200.0, DB(0))
+}
+
+func TestBinFreq(t *testing.T) {
+	assert.Equal(t, 1000.0, BinFreq(512, 4096, 8000))
+	assert.Equal(t, 512, FreqBin(1000, 4096, 8000))
+	assert.Equal(t, 3, FreqBin(BinFreq(3, 1000, 44100), 1000, 44100))
+	assert.Equal(t, 3.0, Sum([]float64{1, 1, 1}, -5, 5), "bounds are cut")
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/lehatrutenb/goresampler/internal/plots"
 	"github.com/lehatrutenb/goresampler/internal/utils"
 
 	"github.com/go-audio/audio"
@@ -15,7 +16,8 @@ const SAVE_PATH = "./test"
 const REPORTS_SUFFIX = "reports"
 const AUDIO_SUFFIX = "audio"
 const LARGE_FILES_SUFFIX = "reports_large"
-const DRAW_USING_GO = false
+const PLOTS_SUFFIX = "plots"
+const DRAW_USING_GO = false // set to draw plots of every saved report right in tests (slow) ; else run make runPlotting after tests
 
 func createSoundFile(fName string, buf *audio.IntBuffer) error {
 	f, err := os.Create(fName)
@@ -86,6 +88,13 @@ func (tObj TestObj) Save(dirName string) error {
 		return err
 	}
 
+	if DRAW_USING_GO || tObj.opts.ToDrawPlots {
+		if err := tObj.savePlots(dirName); err != nil {
+			tObj.t.Error("failed to draw plots")
+			return err
+		}
+	}
+
 	if !tObj.opts.ToCrSF {
 		return nil
 	}
@@ -97,3 +106,13 @@ func (tObj TestObj) Save(dirName string) error {
 	}
 	return nil
 }
+
+func (tObj TestObj) savePlots(dirName string) error {
+	dir := fmt.Sprintf("%s/%s/%s", tObj.opts.OutPlotPath, PLOTS_SUFFIX, dirName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	r := plots.Report{In: tObj.Tres.InWave, Out: tObj.Tres.Resampeled, Correct: tObj.Tres.CorrectW,
+		Channels: tObj.Tw.NumChannels(), InRate: tObj.Tw.InRate(), OutRate: tObj.Tw.OutRate()}
+	return plots.SaveReport(fmt.Sprintf("%s/%s:%s:large.png", dir, tObj.Tr, tObj.Tw), r)
+}
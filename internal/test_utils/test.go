@@ -37,14 +37,8 @@ type MTTestResampler struct {
 	trs []TestResampler
 }
 
-type TestErr struct { // площадь процентного различия больше 10 5
-	SqProc20    float64 `json:"Proc of xs with error higher 20%"`
-	SqProc10    float64 `json:"Proc of xs with error higher 10%"`
-	SqProc5     float64 `json:"Proc of xs with error higher 5%"`
-	SqProc1     float64 `json:"Proc of xs with error higher 1%"`
-	ErrSqed     float64 `json:"ErrSqed (x-corr)^2"`
-	ErrMeanSqed float64 `json:"ErrSqed ((x-corr)^2)/output_samples_amt"`
-}
+// TestErr is errors of resampled wave compared to correct one
+type TestErr = utils.TestErr
 
 type TestResultZipped struct {
 	Te   TestErr
@@ -71,6 +65,7 @@ type TestOpts struct {
 	SFName           string
 	CalcDuration     bool
 	failOnHighDurErr bool
+	ToDrawPlots      bool
 	wg               *sync.WaitGroup
 }
 
@@ -175,6 +170,13 @@ func (to *TestOpts) WithCrSF(toCrSF bool) *TestOpts {
 	return to
 }
 
+// WithPlots makes Save draw plots of report (see plots.SaveReport)
+func (to *TestOpts) WithPlots(toDraw bool) *TestOpts {
+	to.checkAvoidErrAreLast()
+	to.ToDrawPlots = toDraw
+	return to
+}
+
 func (to *TestOpts) NotFailOnHighErr() *TestOpts {
 	to.failOnHighErr = false
 	return to
@@ -213,23 +215,6 @@ func (TestObj) New(tw TestWave, tr TestResampler, runAmt int, t *testing.T, opts
 	}
 }
 
-func (tErr *TestErr) recalcErr(got, corr int16) {
-	tErr.ErrSqed += float64(got-corr) * float64(got-corr)
-
-	if math.Abs(float64(got-corr)) > math.Abs(float64(corr))/100.0*1.0 {
-		tErr.SqProc1++
-	}
-	if math.Abs(float64(got-corr)) > math.Abs(float64(corr))/100.0*5.0 {
-		tErr.SqProc5++
-	}
-	if math.Abs(float64(got-corr)) > math.Abs(float64(corr))/100.0*10.0 {
-		tErr.SqProc10++
-	}
-	if math.Abs(float64(got-corr)) > math.Abs(float64(corr))/100.0*20.0 {
-		tErr.SqProc20++
-	}
-}
-
 // will update testObj.Tres
 func (tObj *TestObj) Run() error {
 	defer tObj.opts.wg.Done()
@@ -282,7 +267,7 @@ func (tObj *TestObj) Run() error {
 				return err2
 			}
 
-			tObj.Tres.Te.recalcErr(got, corr)
+			tObj.Tres.Te.Add(got, corr)
 			CorrectW[i] = corr
 		}
 	}
@@ -301,11 +286,7 @@ func (tObj *TestObj) Run() error {
 		return nil
 	}
 
-	tObj.Tres.Te.ErrMeanSqed = tObj.Tres.Te.ErrSqed / float64(tObj.Tw.OutLen())
-	tObj.Tres.Te.SqProc1 /= float64(tObj.Tw.OutLen())
-	tObj.Tres.Te.SqProc5 /= float64(tObj.Tw.OutLen())
-	tObj.Tres.Te.SqProc10 /= float64(tObj.Tw.OutLen())
-	tObj.Tres.Te.SqProc20 /= float64(tObj.Tw.OutLen())
+	tObj.Tres.Te.Normalize(tObj.Tw.OutLen())
 
 	// small check for resampler correctness
 	if tObj.opts.failOnHighErr && tObj.Tres.Te.SqProc20 >= 0.2 { // if too large error too often
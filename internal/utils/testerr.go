@@ -0,0 +1,52 @@
+package utils
+
+import "math"
+
+type TestErr struct { // площадь процентного различия больше 10 5
+	SqProc20    float64 `json:"Proc of xs with error higher 20%"`
+	SqProc10    float64 `json:"Proc of xs with error higher 10%"`
+	SqProc5     float64 `json:"Proc of xs with error higher 5%"`
+	SqProc1     float64 `json:"Proc of xs with error higher 1%"`
+	ErrSqed     float64 `json:"ErrSqed (x-corr)^2"`
+	ErrMeanSqed float64 `json:"ErrSqed ((x-corr)^2)/output_samples_amt"`
+}
+
+// Add counts error of got sample compared to correct one
+func (tErr *TestErr) Add(got, corr int16) {
+	tErr.ErrSqed += float64(got-corr) * float64(got-corr)
+
+	if math.Abs(float64(got-corr)) > math.Abs(float64(corr))/100.0*1.0 {
+		tErr.SqProc1++
+	}
+	if math.Abs(float64(got-corr)) > math.Abs(float64(corr))/100.0*5.0 {
+		tErr.SqProc5++
+	}
+	if math.Abs(float64(got-corr)) > math.Abs(float64(corr))/100.0*10.0 {
+		tErr.SqProc10++
+	}
+	if math.Abs(float64(got-corr)) > math.Abs(float64(corr))/100.0*20.0 {
+		tErr.SqProc20++
+	}
+}
+
+// Normalize turns counters of Add into means by amt of compared samples
+func (tErr *TestErr) Normalize(amt int) {
+	tErr.ErrMeanSqed = tErr.ErrSqed / float64(amt)
+	tErr.SqProc1 /= float64(amt)
+	tErr.SqProc5 /= float64(amt)
+	tErr.SqProc10 /= float64(amt)
+	tErr.SqProc20 /= float64(amt)
+}
+
+// CalcTestErr returns errors of got wave compared to correct one (as tests count them) - extra samples of longer wave are skipped
+func CalcTestErr(got, corr []int16) TestErr {
+	res := TestErr{}
+	amt := min(len(got), len(corr))
+	for i := range amt {
+		res.Add(got[i], corr[i])
+	}
+	if amt != 0 {
+		res.Normalize(amt)
+	}
+	return res
+}
@@ -6,7 +6,7 @@ import (
 )
 
 var (
-	// ErrUnexpResRate indicates that that ResamplerT not support that conversion
+	// ErrUnexpResRate indicates that that ResamplerT not support that conversion (returned as *RateError)
 	ErrUnexpResRate = errors.New("got unexpected in rate or out rate to resample")
 	// ErrUnexpResamplerType indicates that auto resampler got unsupported rsmT
 	ErrUnexpResamplerType   = errors.New("got unexpected resampler type (not const of ResamplerT)")
@@ -77,12 +77,13 @@ type ResamplerAuto struct {
 	inRate  int
 	outRate int
 	Resampler
+	format SampleFormat // format of ResampleBytes waves
 }
 
 // returns
 // error:
 //
-// if given rsmT not implement such rate convertion - ErrUnexpResRate
+// if given rsmT not implement such rate convertion - *RateError (matches ErrUnexpResRate)
 // if given rsmT not fit in known rsm types - ErrUnexpResamplerType
 //
 // bool:
@@ -91,82 +92,136 @@ type ResamplerAuto struct {
 //
 // if failed to find such batch to fit maxErrRate,  second arg is false,
 // otherwise true (but even with false, resampler is fine to use)
+//
+// it's same as New(inRate, outRate, WithType(rsmT), WithMaxTimeErr(*maxErrRateP)) - prefer New in new code
 func NewResamplerAuto(inRate, outRate int, rsmT ResamplerT, maxErrRateP *float64) (ResamplerAuto, bool, error) {
+	opts := []Option{WithType(rsmT)}
+	if maxErrRateP != nil {
+		opts = append(opts, WithMaxTimeErr(*maxErrRateP))
+	}
+	rsm, info, err := New(inRate, outRate, opts...)
+	return rsm, info.TimeErrOk, err
+}
+
+// ResampleBytes is Resample for waves stored as bytes in format set by WithSampleFormat
+//
+// len(in) and len(out) must be lens got from CalcInOutSamplesPerOutAmt multiplied by format Size()
+func (rsm ResamplerAuto) ResampleBytes(in, out []byte) error {
+	sz := rsm.format.Size()
+	if sz == 0 {
+		return ErrUnexpSampleFormat
+	}
+	if len(in)%sz != 0 {
+		return &LengthError{"in", len(in), len(in) / sz * sz}
+	}
+	if len(out)%sz != 0 {
+		return &LengthError{"out", len(out), len(out) / sz * sz}
+	}
+
+	inS, outS := make([]int16, len(in)/sz), make([]int16, len(out)/sz)
+	if err := rsm.format.Decode(inS, in); err != nil {
+		return err
+	}
+	if err := rsm.Resample(inS, outS); err != nil {
+		return err
+	}
+	return rsm.format.Encode(out, outS)
+}
+
+// constExprRsms are constructors of const expression resamplers by {inRate, outRate}
+var constExprRsms = map[[2]int]func() Resampler{
+	{11000, 8000}: func() Resampler { return NewRsm11To8L() }, // not 11025!
+	{16000, 8000}: func() Resampler { return NewRsm16To8L() },
+	{44000, 8000}: func() Resampler { return NewRsm44To8L() }, // not 44100!
+	{48000, 8000}: func() Resampler { return NewRsm48To8L() },
+
+	{8000, 16000}:  func() Resampler { return NewRsm8To16L() },
+	{11000, 16000}: func() Resampler { return NewRsm11To16L() }, // not 11025!
+	{44000, 16000}: func() Resampler { return NewRsm44To16L() }, // not 44100!
+	{48000, 16000}: func() Resampler { return NewRsm48To16L() },
+}
+
+// hasConstExpr reports if there is const expression resampler of rates (without building it)
+func hasConstExpr(inRate, outRate int) bool {
+	_, ok := constExprRsms[[2]int{inRate, outRate}]
+	return ok
+}
+
+// newConstExpr returns const expression resampler of rates (nil if there is no such one)
+func newConstExpr(inRate, outRate int) Resampler {
+	if newRsm, ok := constExprRsms[[2]int{inRate, outRate}]; ok {
+		return newRsm()
+	}
+	return nil
+}
+
+// algorithmFor returns algorithm rsmT resamples inRate to outRate by - ResamplerConstExprT, ResamplerSplineT or ResamplerFFtT
+// (ResamplerConstExprT for not changing rates)
+//
+// if strict is false rates are not checked to be from tested ones (same as ResamplerBestFitNotSafeT does)
+func algorithmFor(inRate, outRate int, rsmT ResamplerT, strict bool) (ResamplerT, error) {
 	if inRate == outRate {
-		return ResamplerAuto{inRate, outRate, NewRsmNotChange()}, true, nil
+		return ResamplerConstExprT, nil
 	}
 
-	if (!slices.Contains([]int{8000, 11025, 16000, 44100, 48000}, inRate) || !slices.Contains([]int{8000, 16000}, outRate)) && rsmT != ResamplerBestFitNotSafeT {
+	if !isBaseConversion(inRate, outRate) && rsmT != ResamplerBestFitNotSafeT && strict {
 		if slices.Contains([]int{11000, 44000}, inRate) && slices.Contains([]int{8000, 16000}, outRate) {
 			if rsmT != ResamplerConstExprT {
-				return ResamplerAuto{}, false, ErrUnexpResRate
+				return 0, &RateError{inRate, outRate, rsmT}
 			}
 		} else {
-			return ResamplerAuto{}, false, ErrUnexpResRate
+			return 0, &RateError{inRate, outRate, rsmT}
 		}
 	}
 
-	var rsm Resampler = nil
-	var ok bool = true
-	sRsmT := rsmT
 	switch rsmT {
 	case ResamplerSplineT:
-		rsm, ok = NewResamplerSpline(inRate, outRate, maxErrRateP)
+		return ResamplerSplineT, nil
 	case ResamplerFFtT:
 		if inRate <= outRate {
-			return ResamplerAuto{}, false, ErrUnexpResRate
+			return 0, &RateError{inRate, outRate, rsmT}
 		}
-		rsm, ok = NewResamplerFFT(inRate, outRate, maxErrRateP)
+		return ResamplerFFtT, nil
 	case ResamplerBestFitT, ResamplerBestFitNotSafeT:
-		switch inRate {
-		case 11025, 44100:
-			if outRate != 8000 && outRate != 16000 { // to try to set in constExprRsm to not safe
-				rsmT = ResamplerConstExprT
-			} else {
-				rsm, ok = NewResamplerSpline(inRate, outRate, maxErrRateP) // now it means resampling {11025, 44100}->{8000, 16000}
-			}
-		default:
-			rsmT = ResamplerConstExprT
+		if (inRate == 11025 || inRate == 44100) && (outRate == 8000 || outRate == 16000) {
+			return ResamplerSplineT, nil // there are no const expression resamplers of them
 		}
+	case ResamplerConstExprT:
+	default:
+		return 0, ErrUnexpResamplerType
 	}
-	if rsmT == ResamplerConstExprT {
-		switch outRate {
-		case 8000:
-			switch inRate {
-			case 11000: // not 11025!
-				rsm = NewRsm11To8L()
-			case 16000:
-				rsm = NewRsm16To8L()
-			case 44000: // not 44100!
-				rsm = NewRsm44To8L()
-			case 48000:
-				rsm = NewRsm48To8L()
-			}
-		case 16000:
-			switch inRate {
-			case 8000:
-				rsm = NewRsm8To16L()
-			case 11000: // not 11025!
-				rsm = NewRsm11To16L()
-			case 44000: // not 44100!
-				rsm = NewRsm44To16L()
-			case 48000:
-				rsm = NewRsm48To16L()
-			}
-		}
-		if rsm == nil {
-			if sRsmT != ResamplerBestFitNotSafeT { // if can't set resampler with expected conversion and resamplerT is safe
-				return ResamplerAuto{}, false, ErrUnexpResRate
-			}
-			rsm, ok = NewResamplerSpline(inRate, outRate, maxErrRateP) // support any conversions + not safe mod is on
-		}
+
+	if hasConstExpr(inRate, outRate) {
+		return ResamplerConstExprT, nil
 	}
+	if rsmT != ResamplerBestFitNotSafeT { // if can't set resampler with expected conversion and resamplerT is safe
+		return 0, &RateError{inRate, outRate, rsmT}
+	}
+	return ResamplerSplineT, nil // support any conversions + not safe mod is on
+}
 
-	if rsm == nil {
-		return ResamplerAuto{}, false, ErrUnexpResamplerType
+// newResampler returns resampler of rsmT for 1 channel
+//
+// if strict is false rates are not checked to be from tested ones (same as ResamplerBestFitNotSafeT does)
+func newResampler(inRate, outRate int, rsmT ResamplerT, maxErrRateP *float64, strict bool) (Resampler, bool, error) {
+	alg, err := algorithmFor(inRate, outRate, rsmT, strict)
+	if err != nil {
+		return nil, false, err
+	}
+	if inRate == outRate {
+		return NewRsmNotChange(), true, nil
 	}
 
-	return ResamplerAuto{inRate, outRate, rsm}, ok, nil
+	switch alg {
+	case ResamplerSplineT:
+		rsm, ok := NewResamplerSpline(inRate, outRate, maxErrRateP)
+		return rsm, ok, nil
+	case ResamplerFFtT:
+		rsm, ok := NewResamplerFFT(inRate, outRate, maxErrRateP)
+		return rsm, ok, nil
+	default:
+		return newConstExpr(inRate, outRate), true, nil
+	}
 }
 
 // resampler that wraps other resamplers for 2 waves and give ability to choose which of them to use
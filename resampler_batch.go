@@ -2,99 +2,170 @@ package goresampler
 
 import (
 	"errors"
-
-	"golang.org/x/exp/slices"
+	"fmt"
+	"time"
 )
 
 var (
 	// ErrNotEnoughSamples indicates that in ResampleBatch not enough buffered data
-	// to get requested samples amount
+	// to get requested samples amount (returned as *NeedMoreInputError)
 	ErrNotEnoughSamples = errors.New("need more samples to get that size of batch")
 )
 
 // ResampleBatch provides resampling within push (GetBatch/GetLargeBatch) and pull (AddBatch)
 type ResampleBatch struct {
-	in       []int16   // buffered input wave, not yet resampled
-	out      []int16   // buffered output wave, not yet pulled
-	rsm      Resampler // resampler that will resample
+	in       ringBuffer // buffered input wave, not yet resampled
+	out      ringBuffer // buffered output wave, not yet pulled
+	rsm      Resampler  // resampler that will resample
 	rsmTails ResamplerSpline
+	chAmt    int           // amt of interleaved channels rsm resamples (see WithChannels)
+	layout   ChannelMatrix // converts layout of added input (nil - not converted)
+	pts      ptsTracker
+	gap      gapState
 }
 
+// NewResampleBatch returns ResampleBatch with buffers that grow when they need
+// (but only up to max amt of samples buffered at once)
 func NewResampleBatch(rsm Resampler, inRate, outRate int) ResampleBatch {
+	rsmB, _ := NewResampleBatchBounded(rsm, inRate, outRate, 0, 0) // not bounded buffers fit any batch
+	return rsmB
+}
+
+// NewResampleBatchBounded returns ResampleBatch with fixed size buffers: at most maxInAmt not resampled samples
+// and maxOutAmt resampled not got ones (0 - not bounded)
+//
+// if buffer can't fit more - ErrBufferFull is returned (from AddBatch if in buffer is full,
+// from GetBatch and ResampleAllInBuf if there is no place for resampled samples)
+//
+// ErrIncorrectOption is returned if bounds can't fit min batch rsm resamples (of all channels) - stream would never move then
+func NewResampleBatchBounded(rsm Resampler, inRate, outRate int, maxInAmt, maxOutAmt int) (ResampleBatch, error) {
 	rsmTails, _ := NewResamplerSpline(inRate, outRate, nil)
-	return ResampleBatch{make([]int16, 0), make([]int16, 0), rsm, rsmTails}
+	rsm, layout := splitLayout(rsm)
+	chAmt := channelsAmt(rsm)
+	minInAmt, minOutAmt := rsm.CalcInOutSamplesPerOutAmt(1)
+	if err := checkBound("maxInAmt", maxInAmt, minInAmt); err != nil {
+		return ResampleBatch{}, err
+	}
+	if err := checkBound("maxOutAmt", maxOutAmt, minOutAmt); err != nil {
+		return ResampleBatch{}, err
+	}
+	return ResampleBatch{newRingBuffer(maxInAmt), newRingBuffer(maxOutAmt), rsm, rsmTails, chAmt, layout,
+		newPtsTracker(inRate, outRate, chAmt, latencyOf(rsm)), newGapState(inRate)}, nil
+}
+
+// checkBound returns ErrIncorrectOption if bounded (not 0) buffer size is negative or less than min batch
+func checkBound(name string, size, minSize int) error {
+	if size < 0 || (size != 0 && size < minSize) {
+		return fmt.Errorf("%w: %s %d is less than min batch %d", ErrIncorrectOption, name, size, minSize)
+	}
+	return nil
 }
 
 // AddBatch appends given in (input wave) to in buffer
+//
+// returns ErrBufferFull (and adds nothing) if in doesn't fit in bounded buffer
+// and *LengthError if layout is converted (see WithOutChannels) but in has not whole frames of in channels
 func (rsm *ResampleBatch) AddBatch(in []int16) error {
-	rsm.in = append(rsm.in, in...)
-	return nil
+	return rsm.addInput(in, 0, false)
 }
 
-/*
-Why
-out = rsm.out[:len(out)]
-is fine?
+// AddBatchAt is AddBatch for in which first sample is presented at pts (like capture timestamp)
+//
+// pts of samples added later by AddBatch are continued from that one by input rate
+// use GetBatchAt to get pts of resampled samples
+func (rsm *ResampleBatch) AddBatchAt(in []int16, pts time.Duration) error {
+	return rsm.addInput(in, pts, true)
+}
 
-1. It won't be freed by gc, even if later I resize out = out[x:]
-2. We won't access that data more
-3. GC will free it when ~ out slice is no longer in use
+// addInput converts layout of in (if rsm is got with WithOutChannels or WithChannelMatrix) and adds it
+func (rsm *ResampleBatch) addInput(in []int16, pts time.Duration, withPts bool) error {
+	if rsm.layout != nil {
+		if inChAmt := rsm.layout.inChAmt(); len(in)%inChAmt != 0 {
+			return &LengthError{"in", len(in), len(in) / inChAmt * inChAmt}
+		}
+		in = rsm.layout.convert(in)
+	}
+	return rsm.addBatch(in, pts, withPts)
+}
 
-use that func when len(out) is large (~ >1e5)
-*/
+func (rsm *ResampleBatch) addBatch(in []int16, pts time.Duration, withPts bool) error {
+	in, fadeInLeft := rsm.gap.fadeIn(in, rsm.chAmt)
+	if err := rsm.in.write(in); err != nil {
+		return err
+	}
+	rsm.gap.fadeInLeft = fadeInLeft
+	rsm.gap.added(in, rsm.chAmt)
+	rsm.pts.add(len(in), pts, withPts)
+	return nil
+}
 
 func (rsm *ResampleBatch) resampleMore(minRsmAmt int) error {
 	inAmt, outAmt := rsm.rsm.CalcInOutSamplesPerOutAmt(minRsmAmt)
-	if inAmt > len(rsm.in) {
-		return ErrNotEnoughSamples
+	if inAmt > rsm.in.Len() {
+		return &NeedMoreInputError{rsm.in.Len(), inAmt}
 	}
 
-	curOutLen := len(rsm.out)
-	rsm.out = slices.Grow(rsm.out, outAmt)[:len(rsm.out)+outAmt]
-	if err := rsm.rsm.Resample(rsm.in[:inAmt], rsm.out[curOutLen:curOutLen+outAmt]); err != nil {
+	out, err := rsm.out.reserve(outAmt)
+	if err != nil {
 		return err
 	}
-	rsm.in = rsm.in[inAmt:]
+	if err := rsm.rsm.Resample(rsm.in.view(inAmt), out); err != nil {
+		return err
+	}
+	rsm.out.commit(outAmt)
+	rsm.in.discard(inAmt)
+	rsm.pts.resampled(inAmt, outAmt, false)
 	return nil
 }
 
 // GetLargeBatch tries to fill out slice with already resampled and resamples if need
 //
-// returns ErrNotEnoughSamples if in not large enough to get len(out)
+// returns *NeedMoreInputError (matches ErrNotEnoughSamples) if in not large enough to get len(out)
 // resampler state after ErrNotEnoughSamples is is not broken - it's expected to get such err
 //
 // don't want to return ok bool cause out is not filling on err - so get more user attention
 //
-// Difference between GetBatch and GetLargeBatch just in way to fill out
+// Difference between GetBatch and GetLargeBatch just in way to fill out:
+// GetLargeBatch doesn't fill out, but sets it to memory that is given to caller - part of out buffer
+// (rest of out buffer moves to new array - it never touches out again) or new array if rest of out buffer is longer
+// ; so it copies less than GetBatch when out is large
 func (rsm *ResampleBatch) GetLargeBatch(out *[]int16) error {
 	bLen := len(*out)
-	if bLen > len(rsm.out) {
-		if err := rsm.resampleMore(bLen - len(rsm.out)); err != nil {
+	if bLen > rsm.out.Len() {
+		if err := rsm.resampleMore(bLen - rsm.out.Len()); err != nil {
 			return err
 		}
 	}
-	*out = rsm.out[:bLen]
-	rsm.out = rsm.out[bLen:]
+	*out = rsm.out.detach(bLen)
+	rsm.pts.got(bLen)
 	return nil
 }
 
 // GetBatch tries to fill out slice with already resampled and resamples if need
 //
-// returns ErrNotEnoughSamples if in not large enough to get len(out)
+// returns *NeedMoreInputError (matches ErrNotEnoughSamples) if in not large enough to get len(out)
 // resampler state after ErrNotEnoughSamples is is not broken - it's expected to get such err
 //
 // don't want to return ok bool cause out is not filling on err - so get more user attention
 func (rsm *ResampleBatch) GetBatch(out []int16) error {
+	_, err := rsm.GetBatchAt(out)
+	return err
+}
+
+// GetBatchAt is GetBatch that returns pts of first sample of out
+//
+// pts accounts delay of resampler and tails made by ResampleAllInBuf,
+// it's got from pts passed to AddBatchAt (stream without AddBatchAt calls starts at 0)
+func (rsm *ResampleBatch) GetBatchAt(out []int16) (time.Duration, error) {
 	bLen := len(out)
-	if bLen > len(rsm.out) {
-		if err := rsm.resampleMore(bLen - len(rsm.out)); err != nil {
-			return err
+	if bLen > rsm.out.Len() {
+		if err := rsm.resampleMore(bLen - rsm.out.Len()); err != nil {
+			return 0, err
 		}
 	}
 
-	copy(out, rsm.out)
-	rsm.out = rsm.out[bLen:]
-	return nil
+	rsm.out.read(out)
+	return rsm.pts.got(bLen), nil
 }
 
 // UnresampledUngetInAmt returns len of input and output buffers of ResampleBatch
@@ -103,33 +174,49 @@ func (rsm *ResampleBatch) GetBatch(out []int16) error {
 //
 // out []int16  - buffered output wave, not yet pulled
 func (rsm ResampleBatch) UnresampledUngetInAmt() (int, int) {
-	return len(rsm.in), len(rsm.out)
+	return rsm.in.Len(), rsm.out.Len()
 }
 
 // Len return size of out buffer (resampled ones)
 func (rsm *ResampleBatch) Len() int {
-	return len(rsm.out)
+	return rsm.out.Len()
 }
 
 // ResampleAllInBuf resamples all data in input buffer
-// after it in buffer is clear
+// after it in buffer is clear (except not full frame of multichannel wave)
 //
 // to get resampled samples - use GetBatch and len(ResampleBatch)
 func (rsm *ResampleBatch) ResampleAllInBuf() error {
-	curOutLen := len(rsm.out)
-	inAmt := len(rsm.in)
-	outAmt := rsm.rsmTails.calcOutSamplesPerInAmt(inAmt)
-	rsm.out = slices.Grow(rsm.out, outAmt)[:len(rsm.out)+outAmt]
-	if err := rsm.rsmTails.ResampleAll(rsm.in[:inAmt], rsm.out[curOutLen:curOutLen+outAmt]); err != nil {
+	inAmt := rsm.in.Len() / rsm.chAmt * rsm.chAmt
+	outAmt := rsm.rsmTails.calcOutSamplesPerInAmt(inAmt/rsm.chAmt) * rsm.chAmt
+	out, err := rsm.out.reserve(outAmt)
+	if err != nil {
+		return err
+	}
+	if err := resampleAllChannels(rsm.rsmTails, rsm.in.view(inAmt), out, rsm.chAmt); err != nil {
 		return err
 	}
-	rsm.in = rsm.in[inAmt:]
+	rsm.out.commit(outAmt)
+	rsm.in.discard(inAmt)
+	rsm.pts.resampled(inAmt, outAmt, true)
 	return nil
 }
 
+// Reset clears buffers (keeping their memory) and state of resamplers
 func (rsm *ResampleBatch) Reset() {
-	rsm.in = make([]int16, 0)
-	rsm.out = make([]int16, 0)
+	rsm.in.reset()
+	rsm.out.reset()
+	rsm.pts.reset()
+	rsm.gap.history, rsm.gap.fadeInLeft = rsm.gap.history[:0], 0
 	rsm.rsm.Reset()
 	rsm.rsmTails.Reset()
 }
+
+// canGet returns true if GetBatch can get n samples now
+func (rsm *ResampleBatch) canGet(n int) bool {
+	if n <= rsm.out.Len() {
+		return true
+	}
+	inAmt, _ := rsm.rsm.CalcInOutSamplesPerOutAmt(n - rsm.out.Len())
+	return inAmt <= rsm.in.Len()
+}
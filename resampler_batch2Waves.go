@@ -1,115 +1,135 @@
 package goresampler
 
-import (
-	"golang.org/x/exp/slices"
-)
-
 // ResampleBatch2Waves provides resampling within push (GetBatch/GetLargeBatch) and pull (AddBatch) from input to 2 waves with their own rates
 type ResampleBatch2Waves struct {
-	in       []int16         // buffered input wave, not yet resampled
-	out1     []int16         // buffered first output wave, not yet pulled
-	out2     []int16         // buffered secound output wave, not yet pulled
+	in       ringBuffer      // buffered input wave, not yet resampled
+	out1     ringBuffer      // buffered first output wave, not yet pulled
+	out2     ringBuffer      // buffered secound output wave, not yet pulled
 	rsm      Resampler2Waves // resampler that will resample
 	rsmTails ResamplerSpline2Waves
 }
 
+// NewResampleBatch2Waves returns ResampleBatch2Waves with buffers that grow when they need
+// (but only up to max amt of samples buffered at once)
 func NewResampleBatch2Waves(rsm Resampler2Waves, inRate, outRate1, outRate2 int) ResampleBatch2Waves {
+	rsmB, _ := NewResampleBatch2WavesBounded(rsm, inRate, outRate1, outRate2, 0, 0, 0) // not bounded buffers fit any batch
+	return rsmB
+}
+
+// NewResampleBatch2WavesBounded returns ResampleBatch2Waves with fixed size buffers (0 - not bounded)
+// see NewResampleBatchBounded
+func NewResampleBatch2WavesBounded(rsm Resampler2Waves, inRate, outRate1, outRate2 int, maxInAmt, maxOutAmt1, maxOutAmt2 int) (ResampleBatch2Waves, error) {
 	rsmTails, _ := NewResamplerSpline2Waves(inRate, outRate1, outRate2, nil)
-	return ResampleBatch2Waves{make([]int16, 0), make([]int16, 0), make([]int16, 0), rsm, rsmTails}
+	in1, out11, out21 := rsm.CalcInOutSamplesPerOutAmt(1, 0) // min batches to get first and second waves
+	in2, out12, out22 := rsm.CalcInOutSamplesPerOutAmt(0, 1)
+	for _, b := range []struct {
+		name          string
+		size, minSize int
+	}{{"maxInAmt", maxInAmt, max(in1, in2)}, {"maxOutAmt1", maxOutAmt1, max(out11, out12)}, {"maxOutAmt2", maxOutAmt2, max(out21, out22)}} {
+		if err := checkBound(b.name, b.size, b.minSize); err != nil {
+			return ResampleBatch2Waves{}, err
+		}
+	}
+	return ResampleBatch2Waves{newRingBuffer(maxInAmt), newRingBuffer(maxOutAmt1), newRingBuffer(maxOutAmt2), rsm, rsmTails}, nil
 }
 
 // AddBatch appends given in (input wave) to in buffer
+//
+// returns ErrBufferFull (and adds nothing) if in doesn't fit in bounded buffer
 func (rsm *ResampleBatch2Waves) AddBatch(in []int16) error {
-	rsm.in = append(rsm.in, in...)
-	return nil
+	return rsm.in.write(in)
 }
 
-/*
-Why
-out = rsm.out[:len(out)]
-is fine?
-
-1. It won't be freed by gc, even if later I resize out = out[x:]
-2. We won't access that data more
-3. GC will free it when ~ out slice is no longer in use
-
-use that func when len(out) is large (~ >1e5)
-*/
+// reserveOuts returns free space for outAmt1 and outAmt2 samples in out buffers
+func (rsm *ResampleBatch2Waves) reserveOuts(outAmt1, outAmt2 int) ([]int16, []int16, error) {
+	out1, err := rsm.out1.reserve(outAmt1)
+	if err != nil {
+		return nil, nil, err
+	}
+	out2, err := rsm.out2.reserve(outAmt2)
+	if err != nil {
+		return nil, nil, err
+	}
+	return out1, out2, nil
+}
 
 func (rsm *ResampleBatch2Waves) resampleMore(minRsmAmt1, minRsmAmt2 int) error {
 	inAmt, outAmt1, outAmt2 := rsm.rsm.CalcInOutSamplesPerOutAmt(minRsmAmt1, minRsmAmt2)
-	if inAmt > len(rsm.in) {
-		return ErrNotEnoughSamples
+	if inAmt > rsm.in.Len() {
+		return &NeedMoreInputError{rsm.in.Len(), inAmt}
 	}
 
-	curOutLen1 := len(rsm.out1)
-	curOutLen2 := len(rsm.out2)
-	rsm.out1 = slices.Grow(rsm.out1, outAmt1)[:len(rsm.out1)+outAmt1]
-	rsm.out2 = slices.Grow(rsm.out2, outAmt2)[:len(rsm.out2)+outAmt2] // not want to use loop there 1. slower 2. not really makes code prettier
-	rsm.rsm.Resample(rsm.in[:inAmt], rsm.out1[curOutLen1:curOutLen1+outAmt1], rsm.out2[curOutLen2:curOutLen2+outAmt2])
-	rsm.in = rsm.in[inAmt:]
+	out1, out2, err := rsm.reserveOuts(outAmt1, outAmt2)
+	if err != nil {
+		return err
+	}
+	if err := rsm.rsm.Resample(rsm.in.view(inAmt), out1, out2); err != nil {
+		return err
+	}
+	rsm.out1.commit(outAmt1)
+	rsm.out2.commit(outAmt2)
+	rsm.in.discard(inAmt)
 	return nil
 }
 
 // GetLargeBatch tries to fill out slice with already resampled and resamples if need
 //
-// returns ErrNotEnoughSamples if in not large enough to get len(out)
+// returns *NeedMoreInputError (matches ErrNotEnoughSamples) if in not large enough to get len(out)
 // resampler state after ErrNotEnoughSamples is is not broken - it's expected to get such err
 //
 // don't want to return ok bool cause out is not filling on err - so get more user attention
 //
-// Difference between GetBatch and GetLargeBatch just in way to fill out
+// Difference between GetBatch and GetLargeBatch just in way to fill out:
+// GetLargeBatch doesn't fill out, but sets it to memory that is given to caller - part of out buffer
+// (rest of out buffer moves to new array - it never touches out again) or new array if rest of out buffer is longer
+// ; so it copies less than GetBatch when out is large
 func (rsm *ResampleBatch2Waves) GetLargeBatchFirstWave(out *[]int16) error {
 	bLen := len(*out)
-	if bLen > len(rsm.out1) {
-		if err := rsm.resampleMore(bLen-len(rsm.out1), 0); err != nil {
+	if bLen > rsm.out1.Len() {
+		if err := rsm.resampleMore(bLen-rsm.out1.Len(), 0); err != nil {
 			return err
 		}
 	}
-	*out = rsm.out1[:bLen]
-	rsm.out1 = rsm.out1[bLen:]
+	*out = rsm.out1.detach(bLen)
 	return nil
 }
 func (rsm *ResampleBatch2Waves) GetLargeBatchSecondWave(out *[]int16) error {
 	bLen := len(*out)
-	if bLen > len(rsm.out2) {
-		if err := rsm.resampleMore(0, bLen-len(rsm.out2)); err != nil {
+	if bLen > rsm.out2.Len() {
+		if err := rsm.resampleMore(0, bLen-rsm.out2.Len()); err != nil {
 			return err
 		}
 	}
-	*out = rsm.out2[:bLen]
-	rsm.out2 = rsm.out2[bLen:]
+	*out = rsm.out2.detach(bLen)
 	return nil
 }
 
 // GetBatch tries to fill out slice with already resampled and resamples if need
 //
-// returns ErrNotEnoughSamples if in not large enough to get len(out)
+// returns *NeedMoreInputError (matches ErrNotEnoughSamples) if in not large enough to get len(out)
 // resampler state after ErrNotEnoughSamples is is not broken - it's expected to get such err
 //
 // don't want to return ok bool cause out is not filling on err - so get more user attention
 func (rsm *ResampleBatch2Waves) GetBatchFirstWave(out []int16) error {
 	bLen := len(out)
-	if bLen > len(rsm.out1) {
-		if err := rsm.resampleMore(bLen-len(rsm.out1), 0); err != nil {
+	if bLen > rsm.out1.Len() {
+		if err := rsm.resampleMore(bLen-rsm.out1.Len(), 0); err != nil {
 			return err
 		}
 	}
 
-	copy(out, rsm.out1)
-	rsm.out1 = rsm.out1[bLen:]
+	rsm.out1.read(out)
 	return nil
 }
 func (rsm *ResampleBatch2Waves) GetBatchSecondWave(out []int16) error {
 	bLen := len(out)
-	if bLen > len(rsm.out2) {
-		if err := rsm.resampleMore(0, bLen-len(rsm.out2)); err != nil {
+	if bLen > rsm.out2.Len() {
+		if err := rsm.resampleMore(0, bLen-rsm.out2.Len()); err != nil {
 			return err
 		}
 	}
 
-	copy(out, rsm.out2)
-	rsm.out2 = rsm.out2[bLen:]
+	rsm.out2.read(out)
 	return nil
 }
 
@@ -119,12 +139,12 @@ func (rsm *ResampleBatch2Waves) GetBatchSecondWave(out []int16) error {
 //
 // out []int16  - buffered output wave, not yet pulled
 func (rsm ResampleBatch2Waves) UnresampledUngetInAmt() (int, int, int) {
-	return len(rsm.in), len(rsm.out1), len(rsm.out2)
+	return rsm.in.Len(), rsm.out1.Len(), rsm.out2.Len()
 }
 
 // Len return size of out buffer (resampled ones)
 func (rsm *ResampleBatch2Waves) Len() (int, int) {
-	return len(rsm.out1), len(rsm.out2)
+	return rsm.out1.Len(), rsm.out2.Len()
 }
 
 // ResampleAllInBuf resamples all data in input buffer
@@ -132,21 +152,26 @@ func (rsm *ResampleBatch2Waves) Len() (int, int) {
 //
 // to get resampled samples - use GetBatch and len(ResampleBatch)
 func (rsm *ResampleBatch2Waves) ResampleAllInBuf() error {
-	inAmt := len(rsm.in)
+	inAmt := rsm.in.Len()
 	outAmt1, outAmt2 := rsm.rsmTails.calcOutSamplesPerInAmt(inAmt)
-	curOutLen1 := len(rsm.out1)
-	curOutLen2 := len(rsm.out2)
-	rsm.out1 = slices.Grow(rsm.out1, outAmt1)[:len(rsm.out1)+outAmt1]
-	rsm.out2 = slices.Grow(rsm.out2, outAmt2)[:len(rsm.out2)+outAmt2] // not want to use loop there 1. slower 2. not really makes code prettier
-	rsm.rsmTails.ResampleAll(rsm.in[:inAmt], rsm.out1[curOutLen1:curOutLen1+outAmt1], rsm.out2[curOutLen2:curOutLen2+outAmt2])
-	rsm.in = rsm.in[inAmt:]
+	out1, out2, err := rsm.reserveOuts(outAmt1, outAmt2)
+	if err != nil {
+		return err
+	}
+	if err := rsm.rsmTails.ResampleAll(rsm.in.view(inAmt), out1, out2); err != nil {
+		return err
+	}
+	rsm.out1.commit(outAmt1)
+	rsm.out2.commit(outAmt2)
+	rsm.in.discard(inAmt)
 	return nil
 }
 
+// Reset clears buffers (keeping their memory) and state of resamplers
 func (rsm *ResampleBatch2Waves) Reset() {
-	rsm.in = make([]int16, 0)
-	rsm.out1 = make([]int16, 0)
-	rsm.out2 = make([]int16, 0)
+	rsm.in.reset()
+	rsm.out1.reset()
+	rsm.out2.reset()
 	rsm.rsm.Reset()
 	rsm.rsmTails.Reset()
 }
@@ -436,7 +436,7 @@ func ExampleResampleBatch2Waves_GetBatchFirstWave() {
 
 	resampledWave1 := make([]int16, 20)
 	resampledWave2 := make([]int16, 40)
-	for i := int16(0); rsmBatch.GetBatchFirstWave(resampledWave1) == goresampler.ErrNotEnoughSamples; i += 5 {
+	for i := int16(0); errors.Is(rsmBatch.GetBatchFirstWave(resampledWave1), goresampler.ErrNotEnoughSamples); i += 5 {
 		err = rsmBatch.AddBatch([]int16{i, i + 1, i + 2, i + 3, i + 4})
 	}
 	for {
@@ -464,7 +464,7 @@ func ExampleResampleBatch2Waves_GetLargeBatchFirstWave() {
 	*resampledWave1 = make([]int16, 20)
 	resampledWave2 := new([]int16)
 	*resampledWave2 = make([]int16, 40)
-	for i := int16(0); rsmBatch.GetLargeBatchFirstWave(resampledWave1) == goresampler.ErrNotEnoughSamples; i += 5 {
+	for i := int16(0); errors.Is(rsmBatch.GetLargeBatchFirstWave(resampledWave1), goresampler.ErrNotEnoughSamples); i += 5 {
 		err = rsmBatch.AddBatch([]int16{i, i + 1, i + 2, i + 3, i + 4})
 	}
 	for {
@@ -0,0 +1,119 @@
+package goresampler
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	// ErrBatchClosed indicates that ResampleBatchSync is closed: no more input can be added
+	// and there is not enough resampled samples left to fill requested batch
+	ErrBatchClosed = errors.New("resample batch is closed")
+)
+
+// ResampleBatchSync is ResampleBatch that is safe to use from several goroutines
+// like capture goroutine calling AddBatch and sender one calling GetBatchWait
+type ResampleBatchSync struct {
+	mu     sync.Mutex
+	rsm    ResampleBatch
+	notify chan struct{} // closed (and replaced) on every change of input to wake up waiters
+	closed bool
+}
+
+func NewResampleBatchSync(rsm Resampler, inRate, outRate int) *ResampleBatchSync {
+	return &ResampleBatchSync{rsm: NewResampleBatch(rsm, inRate, outRate), notify: make(chan struct{})}
+}
+
+// wakeUp must be called under mu
+func (rsm *ResampleBatchSync) wakeUp() {
+	close(rsm.notify)
+	rsm.notify = make(chan struct{})
+}
+
+// AddBatch appends given in (input wave) to in buffer and wakes up GetBatchWait callers
+//
+// returns ErrBatchClosed after Close
+func (rsm *ResampleBatchSync) AddBatch(in []int16) error {
+	rsm.mu.Lock()
+	defer rsm.mu.Unlock()
+	if rsm.closed {
+		return ErrBatchClosed
+	}
+	if err := rsm.rsm.AddBatch(in); err != nil {
+		return err
+	}
+	rsm.wakeUp()
+	return nil
+}
+
+// GetBatch is ResampleBatch.GetBatch - it doesn't wait for input
+func (rsm *ResampleBatchSync) GetBatch(out []int16) error {
+	rsm.mu.Lock()
+	defer rsm.mu.Unlock()
+	return rsm.rsm.GetBatch(out)
+}
+
+// GetBatchWait fills out like GetBatch, but if there is not enough input waits for it
+//
+// returns ctx.Err() if ctx is done before out is filled
+// returns ErrBatchClosed if batch is closed and left samples (see Len) are not enough to fill out
+func (rsm *ResampleBatchSync) GetBatchWait(ctx context.Context, out []int16) error {
+	for {
+		rsm.mu.Lock()
+		err := rsm.rsm.GetBatch(out)
+		if !errors.Is(err, ErrNotEnoughSamples) {
+			rsm.mu.Unlock()
+			return err
+		}
+		if rsm.closed {
+			rsm.mu.Unlock()
+			return ErrBatchClosed
+		}
+		notify := rsm.notify
+		rsm.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-notify:
+		}
+	}
+}
+
+// Close resamples all data left in input buffer (ResampleAllInBuf) and forbids to add more
+//
+// waiting GetBatchWait calls get rest of samples or ErrBatchClosed ; repeated Close does nothing
+func (rsm *ResampleBatchSync) Close() error {
+	rsm.mu.Lock()
+	defer rsm.mu.Unlock()
+	if rsm.closed {
+		return nil
+	}
+	rsm.closed = true
+	defer rsm.wakeUp()
+	return rsm.rsm.ResampleAllInBuf()
+}
+
+// UnresampledUngetInAmt is ResampleBatch.UnresampledUngetInAmt
+func (rsm *ResampleBatchSync) UnresampledUngetInAmt() (int, int) {
+	rsm.mu.Lock()
+	defer rsm.mu.Unlock()
+	return rsm.rsm.UnresampledUngetInAmt()
+}
+
+// Len return size of out buffer (resampled ones)
+func (rsm *ResampleBatchSync) Len() int {
+	rsm.mu.Lock()
+	defer rsm.mu.Unlock()
+	return rsm.rsm.Len()
+}
+
+// Reset clears buffers and reopens closed batch
+func (rsm *ResampleBatchSync) Reset() {
+	rsm.mu.Lock()
+	defer rsm.mu.Unlock()
+	rsm.rsm.Reset()
+	rsm.closed = false
+	rsm.wakeUp()
+}
@@ -0,0 +1,106 @@
+package goresampler_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	goresampler "github.com/lehatrutenb/goresampler"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// run with go test -race to check locking
+func TestResampleBatchSync_ProducerConsumer(t *testing.T) {
+	for _, inRate := range []int{11025, 16000, 48000} {
+		rsmIns, _, err := goresampler.New(inRate, 8000)
+		if !assert.NoError(t, err) {
+			continue
+		}
+		rsm := goresampler.NewResampleBatchSync(rsmIns, inRate, 8000)
+		in := goldenInWave(inRate, inRate) // 1 sec
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for l := 0; l < len(in); l += 441 {
+				assert.NoError(t, rsm.AddBatch(in[l:min(l+441, len(in))]))
+				time.Sleep(50 * time.Microsecond)
+			}
+			assert.NoError(t, rsm.Close())
+		}()
+
+		outAmt := 0
+		out := make([]int16, 80)
+		for {
+			err = rsm.GetBatchWait(context.Background(), out)
+			if errors.Is(err, goresampler.ErrBatchClosed) {
+				break
+			}
+			if !assert.NoError(t, err) {
+				break
+			}
+			outAmt += len(out)
+		}
+		wg.Wait()
+
+		outAmt += rsm.Len()
+		assert.InDelta(t, 8000, outAmt, 1, "%d -> 8000", inRate)
+		assert.ErrorIs(t, rsm.AddBatch(in[:10]), goresampler.ErrBatchClosed)
+	}
+}
+
+func TestResampleBatchSync_Wait(t *testing.T) {
+	rsmIns, _, _ := goresampler.New(48000, 16000)
+	rsm := goresampler.NewResampleBatchSync(rsmIns, 48000, 16000)
+	out := make([]int16, 160)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, rsm.GetBatchWait(ctx, out), context.DeadlineExceeded)
+
+	errCh := make(chan error)
+	go func() {
+		errCh <- rsm.GetBatchWait(context.Background(), out)
+	}()
+	assert.NoError(t, rsm.AddBatch(make([]int16, 240)))
+	assert.NoError(t, rsm.AddBatch(make([]int16, 240))) // 480 in -> 160 out
+	assert.NoError(t, <-errCh)
+
+	go func() {
+		errCh <- rsm.GetBatchWait(context.Background(), out)
+	}()
+	assert.NoError(t, rsm.AddBatch(make([]int16, 30)))
+	assert.NoError(t, rsm.Close()) // 30 in -> 10 out is not enough
+	assert.ErrorIs(t, <-errCh, goresampler.ErrBatchClosed)
+	assert.Equal(t, 10, rsm.Len())
+	assert.NoError(t, rsm.GetBatch(out[:10]))
+
+	rsm.Reset()
+	assert.NoError(t, rsm.AddBatch(make([]int16, 480)))
+	assert.NoError(t, rsm.GetBatchWait(context.Background(), out))
+}
+
+func ExampleResampleBatchSync_GetBatchWait() {
+	rsmIns, _, _ := goresampler.New(48000, 16000)
+	rsm := goresampler.NewResampleBatchSync(rsmIns, 48000, 16000)
+
+	go func() { // capture goroutine
+		for i := 0; i < 10; i++ {
+			_ = rsm.AddBatch(make([]int16, 480)) // 10ms of 48000 wave
+		}
+		_ = rsm.Close()
+	}()
+
+	frames := 0
+	out := make([]int16, 320) // 20ms of 16000 wave
+	for rsm.GetBatchWait(context.Background(), out) == nil {
+		frames++
+	}
+	fmt.Println(frames)
+	// Output: 5
+}
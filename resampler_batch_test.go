@@ -476,7 +476,7 @@ func ExampleResampleBatch_AddBatch() {
 	rsmBatch := goresampler.NewResampleBatch(rsm, 16000, 8000)
 
 	resampledWave := make([]int16, 20)
-	for i := int16(0); rsmBatch.GetBatch(resampledWave) == goresampler.ErrNotEnoughSamples; i += 5 {
+	for i := int16(0); errors.Is(rsmBatch.GetBatch(resampledWave), goresampler.ErrNotEnoughSamples); i += 5 {
 		err = rsmBatch.AddBatch([]int16{i, i + 1, i + 2, i + 3, i + 4})
 	}
 
@@ -496,7 +496,7 @@ func ExampleResampleBatch_GetBatch() {
 	rsmBatch := goresampler.NewResampleBatch(rsm, 16000, 8000)
 
 	resampledWave := make([]int16, 20)
-	for i := int16(0); rsmBatch.GetBatch(resampledWave) == goresampler.ErrNotEnoughSamples; i += 5 {
+	for i := int16(0); errors.Is(rsmBatch.GetBatch(resampledWave), goresampler.ErrNotEnoughSamples); i += 5 {
 		err = rsmBatch.AddBatch([]int16{i, i + 1, i + 2, i + 3, i + 4})
 	}
 
@@ -517,7 +517,7 @@ func ExampleResampleBatch_GetLargeBatch() {
 
 	resampledWave := new([]int16)
 	*resampledWave = make([]int16, 20)
-	for i := int16(0); rsmBatch.GetLargeBatch(resampledWave) == goresampler.ErrNotEnoughSamples; i += 5 {
+	for i := int16(0); errors.Is(rsmBatch.GetLargeBatch(resampledWave), goresampler.ErrNotEnoughSamples); i += 5 {
 		err = rsmBatch.AddBatch([]int16{i, i + 1, i + 2, i + 3, i + 4})
 	}
 
@@ -0,0 +1,302 @@
+package goresampler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrMinSNRNotMet indicates that no calibrated resampler type reaches min snr - one with best snr is chosen
+	ErrMinSNRNotMet = errors.New("no calibrated resampler type reaches min snr")
+)
+
+const calibDurS = 0.5                         // duration of output wave candidates are measured on
+const calibMaxSNRDB = 200.0                   // snr of exact tone (not to store +Inf)
+const calibEdgeSkip = 10                      // 1/calibEdgeSkip of output wave on both sides is not used to calc snr (filters transients)
+const calibStopbandFrac = 0.6                 // stopband tone place between out and in nyquist freqs
+const calibAmplitude = 0.5                    // of full scale
+var calibToneFracs = []float64{0.1, 0.5, 0.8} // passband tones snr is measured on as part of nyquist freq of min rate
+
+// CalibrationEntry is measured speed and quality of 1 resampler type converting 1 rate pair
+type CalibrationEntry struct {
+	RsmT        ResamplerT `json:"rsm_t"`
+	InRate      int        `json:"in_rate"`
+	OutRate     int        `json:"out_rate"`
+	NsPerSample float64    `json:"ns_per_sample"` // per input sample
+	SNRDB       float64    `json:"snr_db"`        // min snr of passband tones
+}
+
+// Calibration chooses resampler type of rate pair by speed and quality measured on this machine (see WithCalibration)
+//
+// every rate pair is measured once (on first New with it or by Measure) - or entries are loaded from profile written by Save
+// ; it's safe to use Calibration from several goroutines
+//
+// care: FFT resampler of rates with long batches (like 44100->16000) takes seconds to measure -
+// save profile or remove it from Candidates if it matters
+type Calibration struct {
+	MeasureTime time.Duration // min time spent to time every candidate resampler (20ms by default)
+	Candidates  []ResamplerT  // types to measure (ResamplerConstExprT, ResamplerSplineT, ResamplerFFtT by default)
+
+	mu        sync.Mutex // guards maps only - rate pairs are measured without it
+	entries   map[[2]int][]CalibrationEntry
+	measuring map[[2]int]*calibMeasurement
+}
+
+// calibMeasurement is measurement of 1 rate pair in progress - other callers wait for it instead of measuring again
+type calibMeasurement struct {
+	done chan struct{} // closed when es and err are set
+	es   []CalibrationEntry
+	err  error
+}
+
+func NewCalibration() *Calibration {
+	return &Calibration{
+		MeasureTime: 20 * time.Millisecond,
+		Candidates:  []ResamplerT{ResamplerConstExprT, ResamplerSplineT, ResamplerFFtT},
+		entries:     make(map[[2]int][]CalibrationEntry),
+		measuring:   make(map[[2]int]*calibMeasurement),
+	}
+}
+
+// LoadCalibration reads profile written by Calibration.Save - rate pairs it has are not measured again
+func LoadCalibration(r io.Reader) (*Calibration, error) {
+	es := make([]CalibrationEntry, 0)
+	if err := json.NewDecoder(r).Decode(&es); err != nil {
+		return nil, err
+	}
+	c := NewCalibration()
+	for _, e := range es {
+		key := [2]int{e.InRate, e.OutRate}
+		c.entries[key] = append(c.entries[key], e)
+	}
+	return c, nil
+}
+
+// Save writes all measured entries as json profile (LoadCalibration reads it)
+func (c *Calibration) Save(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(c.Entries())
+}
+
+// Entries returns all measured entries sorted by rates and resampler type
+func (c *Calibration) Entries() []CalibrationEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	res := make([]CalibrationEntry, 0)
+	for _, es := range c.entries {
+		res = append(res, es...)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].InRate != res[j].InRate {
+			return res[i].InRate < res[j].InRate
+		}
+		if res[i].OutRate != res[j].OutRate {
+			return res[i].OutRate < res[j].OutRate
+		}
+		return res[i].RsmT < res[j].RsmT
+	})
+	return res
+}
+
+// Measure returns entries of every resampler type that can convert inRate to outRate (measures them if it's not done yet)
+//
+// rates are not checked to be from tested ones - Choose skips not allowed types
+// ; callers of same not measured rate pair wait for one measurement, callers of other pairs are not blocked by it
+func (c *Calibration) Measure(inRate, outRate int) ([]CalibrationEntry, error) {
+	if inRate <= 0 || outRate <= 0 || inRate == outRate {
+		return nil, &RateError{inRate, outRate, ResamplerBestFitNotSafeT}
+	}
+	key := [2]int{inRate, outRate}
+	c.mu.Lock()
+	if es, ok := c.entries[key]; ok {
+		c.mu.Unlock()
+		return es, nil
+	}
+	if m, ok := c.measuring[key]; ok {
+		c.mu.Unlock()
+		<-m.done
+		return m.es, m.err
+	}
+	m := &calibMeasurement{done: make(chan struct{})}
+	c.measuring[key] = m
+	candidates, measureTime := slices.Clone(c.Candidates), c.MeasureTime
+	c.mu.Unlock()
+
+	m.es, m.err = measureCandidates(candidates, inRate, outRate, measureTime)
+	c.mu.Lock()
+	if m.err == nil {
+		c.entries[key] = m.es
+	}
+	delete(c.measuring, key)
+	c.mu.Unlock()
+	close(m.done)
+	return m.es, m.err
+}
+
+// measureCandidates measures every candidate type that can convert inRate to outRate
+func measureCandidates(candidates []ResamplerT, inRate, outRate int, measureTime time.Duration) ([]CalibrationEntry, error) {
+	es := make([]CalibrationEntry, 0, len(candidates))
+	for _, rsmT := range candidates {
+		rsm, _, err := newResampler(inRate, outRate, rsmT, nil, false)
+		if err != nil { // can't convert such rates
+			continue
+		}
+		e, err := measureCalibration(rsm, rsmT, inRate, outRate, measureTime)
+		if err != nil {
+			return nil, err
+		}
+		es = append(es, e)
+	}
+	return es, nil
+}
+
+// Choose returns fastest resampler type converting inRate to outRate with snr not less than minSNRDB
+// - types strict mode doesn't allow are skipped (see WithStrictRates)
+//
+// if there are no such type one with best snr is returned with error that matches ErrMinSNRNotMet
+func (c *Calibration) Choose(inRate, outRate int, minSNRDB float64, strict bool) (ResamplerT, error) {
+	es, err := c.Measure(inRate, outRate)
+	if err != nil {
+		return 0, err
+	}
+	var fastest, best *CalibrationEntry
+	for i, e := range es {
+		if _, err = algorithmFor(inRate, outRate, e.RsmT, strict); err != nil {
+			continue
+		}
+		if best == nil || e.SNRDB > best.SNRDB {
+			best = &es[i]
+		}
+		if e.SNRDB >= minSNRDB && (fastest == nil || e.NsPerSample < fastest.NsPerSample) {
+			fastest = &es[i]
+		}
+	}
+	switch {
+	case fastest != nil:
+		return fastest.RsmT, nil
+	case best != nil:
+		return best.RsmT, fmt.Errorf("%w: best snr of %d -> %d is %.1f dB (%s), want %.1f dB", ErrMinSNRNotMet, inRate, outRate, best.SNRDB, best.RsmT, minSNRDB)
+	default:
+		return 0, &RateError{inRate, outRate, ResamplerBestFitT}
+	}
+}
+
+// calibTone returns amt samples of sine with freq and phase
+func calibTone(freq, phase float64, rate, amt int) []int16 {
+	res := make([]int16, amt)
+	for i := range res {
+		res[i] = int16(math.Round(calibAmplitude * math.MaxInt16 * math.Sin(2*math.Pi*freq*float64(i)/float64(rate)+phase)))
+	}
+	return res
+}
+
+// toneSNR returns power of sine with freq fitted to wave (by least squares with any phase and offset) to power of what is left
+func toneSNR(wave []int16, freq float64, rate int) float64 {
+	// normal equations of wave ~ a*sin + b*cos + c
+	var m [3][3]float64
+	var v [3]float64
+	basis := func(i int) [3]float64 {
+		x := 2 * math.Pi * freq * float64(i) / float64(rate)
+		return [3]float64{math.Sin(x), math.Cos(x), 1}
+	}
+	for i, y := range wave {
+		f := basis(i)
+		for r := range 3 {
+			for c := range 3 {
+				m[r][c] += f[r] * f[c]
+			}
+			v[r] += f[r] * float64(y)
+		}
+	}
+	det := func(m [3][3]float64) float64 {
+		return m[0][0]*(m[1][1]*m[2][2]-m[1][2]*m[2][1]) - m[0][1]*(m[1][0]*m[2][2]-m[1][2]*m[2][0]) + m[0][2]*(m[1][0]*m[2][1]-m[1][1]*m[2][0])
+	}
+	d := det(m)
+	var coef [3]float64
+	for c := range 3 { // Cramer's rule
+		cur := m
+		for r := range 3 {
+			cur[r][c] = v[r]
+		}
+		coef[c] = det(cur) / d
+	}
+
+	sigPw, restPw := 0.0, 0.0
+	for i, y := range wave {
+		f := basis(i)
+		fit := coef[0]*f[0] + coef[1]*f[1]
+		sigPw += fit * fit
+		rest := float64(y) - fit - coef[2]
+		restPw += rest * rest
+	}
+	if restPw == 0 {
+		return calibMaxSNRDB
+	}
+	return math.Min(10*math.Log10(sigPw/restPw), calibMaxSNRDB)
+}
+
+// aliasSNR returns power of input tone (with calibAmplitude) to power of what is left of it in wave (after resampling tone out of band)
+func aliasSNR(wave []int16) float64 {
+	restPw := 0.0 // with dc - input tone can be aliased to it
+	for _, y := range wave {
+		restPw += float64(y) * float64(y)
+	}
+	amp := calibAmplitude * math.MaxInt16
+	sigPw := amp * amp / 2 * float64(len(wave))
+	if restPw == 0 {
+		return calibMaxSNRDB
+	}
+	return math.Min(10*math.Log10(sigPw/restPw), calibMaxSNRDB)
+}
+
+// measureCalibration resamples passband tones (and stopband one if rsm downsamples) by rsm to get its snr and times it
+func measureCalibration(rsm Resampler, rsmT ResamplerT, inRate, outRate int, measureTime time.Duration) (CalibrationEntry, error) {
+	inLen, outLen := rsm.CalcInOutSamplesPerOutAmt(int(calibDurS * float64(outRate)))
+	out := make([]int16, outLen)
+	res := CalibrationEntry{RsmT: rsmT, InRate: inRate, OutRate: outRate, SNRDB: calibMaxSNRDB}
+	inNyquist, outNyquist := float64(inRate)/2, float64(outRate)/2
+	nyquist := math.Min(inNyquist, outNyquist)
+
+	var spent time.Duration
+	runs := 0
+	resample := func(in []int16) error {
+		rsm.Reset()
+		start := time.Now()
+		err := rsm.Resample(in, out)
+		spent += time.Since(start)
+		runs++
+		return err
+	}
+	skip := outLen / calibEdgeSkip
+
+	var in []int16
+	for _, frac := range calibToneFracs {
+		in = calibTone(frac*nyquist, 0, inRate, inLen)
+		if err := resample(in); err != nil {
+			return CalibrationEntry{}, err
+		}
+		res.SNRDB = math.Min(res.SNRDB, toneSNR(out[skip:outLen-skip], frac*nyquist, outRate))
+	}
+	if inRate > outRate { // tone between nyquist freqs must be filtered out ; phase not to pick only zeros of it (like 16000 of 48000->16000 does)
+		if err := resample(calibTone(outNyquist+calibStopbandFrac*(inNyquist-outNyquist), math.Pi/4, inRate, inLen)); err != nil {
+			return CalibrationEntry{}, err
+		}
+		res.SNRDB = math.Min(res.SNRDB, aliasSNR(out[skip:outLen-skip]))
+	}
+
+	for spent < measureTime {
+		if err := resample(in); err != nil {
+			return CalibrationEntry{}, err
+		}
+	}
+	res.NsPerSample = float64(spent.Nanoseconds()) / float64(runs*inLen)
+	return res, nil
+}
@@ -0,0 +1,149 @@
+package goresampler_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	goresampler "github.com/lehatrutenb/goresampler"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testProfile = `[
+	{"rsm_t": 1, "in_rate": 48000, "out_rate": 16000, "ns_per_sample": 10, "snr_db": 80},
+	{"rsm_t": 2, "in_rate": 48000, "out_rate": 16000, "ns_per_sample": 5, "snr_db": 20},
+	{"rsm_t": 3, "in_rate": 48000, "out_rate": 16000, "ns_per_sample": 100, "snr_db": 100},
+	{"rsm_t": 1, "in_rate": 11000, "out_rate": 8000, "ns_per_sample": 10, "snr_db": 60},
+	{"rsm_t": 2, "in_rate": 11000, "out_rate": 8000, "ns_per_sample": 5, "snr_db": 90}
+]`
+
+func TestCalibration_Choose(t *testing.T) {
+	calib, err := goresampler.LoadCalibration(strings.NewReader(testProfile))
+	if !assert.NoError(t, err) {
+		return
+	}
+	for _, tc := range []struct {
+		minSNRDB float64
+		exp      goresampler.ResamplerT
+		expErr   error
+	}{
+		{0, goresampler.ResamplerSplineT, nil},
+		{50, goresampler.ResamplerConstExprT, nil},
+		{90, goresampler.ResamplerFFtT, nil},
+		{150, goresampler.ResamplerFFtT, goresampler.ErrMinSNRNotMet}, // no one is good enough - best one
+	} {
+		rsmT, err := calib.Choose(48000, 16000, tc.minSNRDB, true)
+		if tc.expErr == nil {
+			assert.NoError(t, err)
+		} else {
+			assert.ErrorIs(t, err, tc.expErr)
+		}
+		assert.Equal(t, tc.exp, rsmT, tc.minSNRDB)
+	}
+
+	// 11000->8000 is well tested only by const expression resampler
+	rsmT, err := calib.Choose(11000, 8000, 70, true)
+	assert.ErrorIs(t, err, goresampler.ErrMinSNRNotMet, "spline one reaches it, but it's not allowed")
+	assert.Equal(t, goresampler.ResamplerConstExprT, rsmT)
+	rsmT, err = calib.Choose(11000, 8000, 70, false)
+	assert.NoError(t, err)
+	assert.Equal(t, goresampler.ResamplerSplineT, rsmT)
+}
+
+func TestNew_Calibration(t *testing.T) {
+	calib, err := goresampler.LoadCalibration(strings.NewReader(testProfile))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, info, err := goresampler.New(48000, 16000, goresampler.WithCalibration(calib, 90))
+	if assert.NoError(t, err) {
+		assert.True(t, info.Calibrated)
+		assert.False(t, info.BelowMinSNR)
+		assert.Equal(t, goresampler.ResamplerFFtT, info.Type)
+		assert.Equal(t, goresampler.ResamplerFFtT, info.Algorithm)
+	}
+
+	_, info, err = goresampler.New(48000, 16000, goresampler.WithCalibration(calib, 150))
+	if assert.NoError(t, err) { // no one is good enough - best one is built, but it's reported
+		assert.True(t, info.Calibrated)
+		assert.True(t, info.BelowMinSNR)
+		assert.Equal(t, goresampler.ResamplerFFtT, info.Type)
+	}
+
+	_, info, err = goresampler.New(48000, 16000, goresampler.WithCalibration(calib, 90), goresampler.WithType(goresampler.ResamplerSplineT))
+	if assert.NoError(t, err) { // WithType overrides calibration
+		assert.False(t, info.Calibrated)
+		assert.Equal(t, goresampler.ResamplerSplineT, info.Algorithm)
+	}
+
+	_, info, err = goresampler.New(48000, 16000, goresampler.WithCalibration(nil, 90))
+	if assert.NoError(t, err) { // disabled - same as default
+		assert.False(t, info.Calibrated)
+		assert.Equal(t, goresampler.ResamplerBestFitT, info.Type)
+		assert.Equal(t, goresampler.ResamplerConstExprT, info.Algorithm)
+	}
+}
+
+func TestCalibration_Measure(t *testing.T) {
+	calib := goresampler.NewCalibration()
+	calib.MeasureTime = time.Millisecond
+	es, err := calib.Measure(16000, 8000)
+	if !assert.NoError(t, err) || !assert.Len(t, es, 3) {
+		return
+	}
+	snrs := make(map[goresampler.ResamplerT]float64)
+	for _, e := range es {
+		assert.Greater(t, e.NsPerSample, 0.0)
+		snrs[e.RsmT] = e.SNRDB
+	}
+	assert.Greater(t, snrs[goresampler.ResamplerConstExprT], 60.0)
+	assert.Less(t, snrs[goresampler.ResamplerSplineT], 20.0) // not filters aliasing
+
+	_, err = calib.Measure(8000, 8000)
+	assert.ErrorIs(t, err, goresampler.ErrUnexpResRate)
+
+	buf := bytes.NewBuffer(nil)
+	if !assert.NoError(t, calib.Save(buf)) {
+		return
+	}
+	loaded, err := goresampler.LoadCalibration(buf)
+	if assert.NoError(t, err) {
+		assert.Equal(t, calib.Entries(), loaded.Entries())
+	}
+}
+
+// long measurement must not block callers of other (measured) rate pairs
+func TestCalibration_MeasureConcurrent(t *testing.T) {
+	calib, err := goresampler.LoadCalibration(strings.NewReader(testProfile))
+	if !assert.NoError(t, err) {
+		return
+	}
+	calib.Candidates = []goresampler.ResamplerT{goresampler.ResamplerFFtT} // takes about a second for 11025
+	calib.MeasureTime = time.Millisecond
+
+	const waitersAmt = 4
+	results := make(chan []goresampler.CalibrationEntry, waitersAmt)
+	for range waitersAmt {
+		go func() {
+			es, err := calib.Measure(11025, 8000)
+			assert.NoError(t, err)
+			results <- es
+		}()
+	}
+	time.Sleep(20 * time.Millisecond) // measurement is started
+
+	rsmT, err := calib.Choose(48000, 16000, 90, true)
+	assert.NoError(t, err)
+	assert.Equal(t, goresampler.ResamplerFFtT, rsmT)
+	assert.Empty(t, results, "measured pair is got while other one is measured")
+
+	first := <-results
+	assert.Len(t, first, 1)
+	for range waitersAmt - 1 {
+		assert.Equal(t, first, <-results, "pair is measured once")
+	}
+	assert.Len(t, calib.Entries(), len(first)+5)
+}
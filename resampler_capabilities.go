@@ -0,0 +1,79 @@
+package goresampler
+
+// RatePair is input and output rates of conversion
+type RatePair struct {
+	InRate  int
+	OutRate int
+}
+
+// rates conversions of are tested (strict rates allow only them) - SupportedConversions checks pairs of them
+var baseInRates = []int{8000, 11000, 11025, 16000, 44000, 44100, 48000}
+var baseOutRates = []int{8000, 16000}
+
+// SupportedConversions returns pairs of tested rates (of different rates) rsmT converts with strict rates (see WithStrictRates)
+//
+// with strict rates off spline resampler (and ResamplerBestFitNotSafeT) converts any rates, fft one - any decreasing ones
+func SupportedConversions(rsmT ResamplerT) []RatePair {
+	res := make([]RatePair, 0)
+	for _, inRate := range baseInRates {
+		for _, outRate := range baseOutRates {
+			if inRate == outRate {
+				continue
+			}
+			if _, err := algorithmFor(inRate, outRate, rsmT, true); err == nil {
+				res = append(res, RatePair{inRate, outRate})
+			}
+		}
+	}
+	return res
+}
+
+// CapabilityInfo describes how resampler type converts rate pair
+type CapabilityInfo struct {
+	Type      ResamplerT
+	Algorithm ResamplerT // what really resamples (see Info.Algorithm)
+	Safe      bool       // conversion is well tested - allowed with strict rates
+	// rough estimates of Algorithm by Calibration on sine tones - measure them on your machine and rates by Calibration if they matter
+	SNRDB    float64 // expected min snr of passband tones and aliasing
+	RelSpeed float64 // expected time per input sample relative to const expression resampler
+}
+
+// algorithmEstimates returns expected snr and relative speed of alg (spline one doesn't filter aliasing - so it's worse on decreasing rates)
+func algorithmEstimates(alg ResamplerT, inRate, outRate int) (float64, float64) {
+	switch alg {
+	case ResamplerSplineT:
+		if inRate > outRate {
+			return 0, 3
+		}
+		return 5, 5
+	case ResamplerFFtT:
+		return 30, 40
+	default:
+		return 80, 1
+	}
+}
+
+// Capabilities returns how every resampler type converts inRate to outRate (types that can't convert them are skipped)
+//
+// nil is returned for not changing rates - any type converts them (nothing is resampled)
+func Capabilities(inRate, outRate int) []CapabilityInfo {
+	if inRate <= 0 || outRate <= 0 || inRate == outRate {
+		return nil
+	}
+	res := make([]CapabilityInfo, 0)
+	for _, rsmT := range []ResamplerT{ResamplerConstExprT, ResamplerSplineT, ResamplerFFtT, ResamplerBestFitT, ResamplerBestFitNotSafeT} {
+		alg, err := algorithmFor(inRate, outRate, rsmT, false)
+		if err != nil {
+			continue
+		}
+		safeT := rsmT
+		if rsmT == ResamplerBestFitNotSafeT { // it's not checked to be safe at all
+			safeT = ResamplerBestFitT
+		}
+		_, err = algorithmFor(inRate, outRate, safeT, true)
+		cur := CapabilityInfo{Type: rsmT, Algorithm: alg, Safe: err == nil}
+		cur.SNRDB, cur.RelSpeed = algorithmEstimates(alg, inRate, outRate)
+		res = append(res, cur)
+	}
+	return res
+}
@@ -0,0 +1,67 @@
+package goresampler_test
+
+import (
+	"testing"
+
+	goresampler "github.com/lehatrutenb/goresampler"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSupportedConversions(t *testing.T) {
+	assert.Equal(t, []goresampler.RatePair{
+		{8000, 16000}, {11000, 8000}, {11000, 16000}, {16000, 8000}, {44000, 8000}, {44000, 16000}, {48000, 8000}, {48000, 16000},
+	}, goresampler.SupportedConversions(goresampler.ResamplerConstExprT))
+	assert.Equal(t, []goresampler.RatePair{
+		{11025, 8000}, {16000, 8000}, {44100, 8000}, {44100, 16000}, {48000, 8000}, {48000, 16000}, // only decreasing
+	}, goresampler.SupportedConversions(goresampler.ResamplerFFtT))
+	assert.Len(t, goresampler.SupportedConversions(goresampler.ResamplerBestFitT), 8) // 11000 and 44000 are safe only for const expression one
+	assert.Empty(t, goresampler.SupportedConversions(goresampler.ResamplerT(100)))
+
+	// every supported conversion must be really supported
+	for _, rsmT := range []goresampler.ResamplerT{goresampler.ResamplerConstExprT, goresampler.ResamplerSplineT, goresampler.ResamplerFFtT, goresampler.ResamplerBestFitT} {
+		for _, p := range goresampler.SupportedConversions(rsmT) {
+			_, _, err := goresampler.NewResamplerAuto(p.InRate, p.OutRate, rsmT, nil)
+			assert.NoError(t, err, "%v %v", rsmT, p)
+		}
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	caps := goresampler.Capabilities(44100, 16000)
+	algs := make(map[goresampler.ResamplerT]goresampler.ResamplerT)
+	for _, c := range caps {
+		algs[c.Type] = c.Algorithm
+		assert.True(t, c.Safe, c.Type)
+	}
+	assert.Equal(t, map[goresampler.ResamplerT]goresampler.ResamplerT{
+		goresampler.ResamplerSplineT:         goresampler.ResamplerSplineT,
+		goresampler.ResamplerFFtT:            goresampler.ResamplerFFtT,
+		goresampler.ResamplerBestFitT:        goresampler.ResamplerSplineT,
+		goresampler.ResamplerBestFitNotSafeT: goresampler.ResamplerSplineT,
+	}, algs) // there is no const expression resampler of 44100
+
+	caps = goresampler.Capabilities(22050, 8000)
+	for _, c := range caps {
+		assert.False(t, c.Safe, c.Type)
+		_, info, err := goresampler.New(22050, 8000, goresampler.WithType(c.Type), goresampler.WithStrictRates(false))
+		if assert.NoError(t, err) {
+			assert.Equal(t, c.Algorithm, info.Algorithm)
+		}
+	}
+	assert.Len(t, caps, 3) // spline, fft and not safe best fit
+
+	assert.Nil(t, goresampler.Capabilities(16000, 16000))
+	assert.Nil(t, goresampler.Capabilities(0, 16000))
+}
+
+// choosing algorithm is called for every rate pair by capabilities - it must not build resamplers
+func TestAlgorithmFor_NoAllocs(t *testing.T) {
+	allocs := testing.AllocsPerRun(100, func() {
+		alg, err := goresampler.AlgorithmFor(48000, 16000, goresampler.ResamplerBestFitT, true)
+		if err != nil || alg != goresampler.ResamplerConstExprT {
+			t.Fatal("expected const expression resampler of 48000 -> 16000")
+		}
+	})
+	assert.Equal(t, 0.0, allocs)
+}
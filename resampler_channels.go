@@ -0,0 +1,95 @@
+package goresampler
+
+// resamplerChannels resamples interleaved wave (ch0 ch1 ... chN ch0 ch1 ...) with own resampler per channel
+//
+// all amts in its funcs are in samples of all channels (so they are multiple of channels amt)
+type resamplerChannels struct {
+	rsms []Resampler
+}
+
+func newResamplerChannels(rsms []Resampler) resamplerChannels {
+	return resamplerChannels{rsms}
+}
+
+// channelsAmt returns amt of interleaved channels rsm expects (1 for usual resamplers)
+func channelsAmt(rsm Resampler) int {
+	if rsmAuto, ok := rsm.(ResamplerAuto); ok {
+		rsm = rsmAuto.Resampler
+	}
+	if rsmCh, ok := rsm.(resamplerChannels); ok {
+		return len(rsmCh.rsms)
+	}
+	return 1
+}
+
+func (rsm resamplerChannels) CalcNeedSamplesPerOutAmt(outAmt int) int {
+	chAmt := len(rsm.rsms)
+	return rsm.rsms[0].CalcNeedSamplesPerOutAmt((outAmt+chAmt-1)/chAmt) * chAmt
+}
+
+func (rsm resamplerChannels) calcOutSamplesPerInAmt(inAmt int) int {
+	chAmt := len(rsm.rsms)
+	return rsm.rsms[0].calcOutSamplesPerInAmt(inAmt/chAmt) * chAmt
+}
+
+func (rsm resamplerChannels) CalcInOutSamplesPerOutAmt(outAmt int) (int, int) {
+	chAmt := len(rsm.rsms)
+	in, out := rsm.rsms[0].CalcInOutSamplesPerOutAmt((outAmt + chAmt - 1) / chAmt)
+	return in * chAmt, out * chAmt
+}
+
+// deinterleave returns channel ch of wave
+func deinterleave(wave []int16, ch, chAmt int) []int16 {
+	res := make([]int16, len(wave)/chAmt)
+	for i := range res {
+		res[i] = wave[i*chAmt+ch]
+	}
+	return res
+}
+
+// interleave writes channel ch to wave
+func interleave(wave []int16, chWave []int16, ch, chAmt int) {
+	for i, x := range chWave {
+		wave[i*chAmt+ch] = x
+	}
+}
+
+func (rsm resamplerChannels) Resample(in, out []int16) error {
+	chAmt := len(rsm.rsms)
+	if len(in)%chAmt != 0 {
+		return &LengthError{"in", len(in), rsm.CalcNeedSamplesPerOutAmt(len(out))}
+	}
+	if len(out)%chAmt != 0 {
+		return &LengthError{"out", len(out), rsm.calcOutSamplesPerInAmt(len(in))}
+	}
+
+	chOut := make([]int16, len(out)/chAmt)
+	for ch, chRsm := range rsm.rsms {
+		if err := chRsm.Resample(deinterleave(in, ch, chAmt), chOut); err != nil {
+			return err
+		}
+		interleave(out, chOut, ch, chAmt)
+	}
+	return nil
+}
+
+func (rsm resamplerChannels) Reset() {
+	for _, chRsm := range rsm.rsms {
+		chRsm.Reset()
+	}
+}
+
+// resampleAllChannels calls rsm.ResampleAll for every channel of interleaved in and out
+func resampleAllChannels(rsm ResamplerSpline, in, out []int16, chAmt int) error {
+	if chAmt == 1 {
+		return rsm.ResampleAll(in, out)
+	}
+	chOut := make([]int16, len(out)/chAmt)
+	for ch := 0; ch < chAmt; ch++ {
+		if err := rsm.ResampleAll(deinterleave(in, ch, chAmt), chOut); err != nil {
+			return err
+		}
+		interleave(out, chOut, ch, chAmt)
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 )
 
 var (
+	// ErrIncorrectInLen indicates that in len doesn't fit resampler batches (returned as *LengthError)
 	ErrIncorrectInLen = errors.New("expected strict input len, got not matched")
 )
 
@@ -859,7 +860,7 @@ func (rsm Resampler48To8L) CalcInOutSamplesPerOutAmt(outAmt int) (int, int) {
 
 func (rsm Resampler48To8L) Resample(in []int16, out []int16) error {
 	if len(in)%480 != 0 {
-		return ErrIncorrectInLen
+		return &LengthError{"in", len(in), rsm.CalcNeedSamplesPerOutAmt(len(out))}
 	}
 
 	rsm.tmpMem = make([]int32, 496)
@@ -922,7 +923,7 @@ func (rsm Resampler48To16L) CalcInOutSamplesPerOutAmt(outAmt int) (int, int) {
 
 func (rsm Resampler48To16L) Resample(in []int16, out []int16) error {
 	if len(in)%480 != 0 {
-		return ErrIncorrectInLen
+		return &LengthError{"in", len(in), rsm.CalcNeedSamplesPerOutAmt(len(out))}
 	}
 
 	rsm.tmpMem = make([]int32, 496)
@@ -980,7 +981,7 @@ func (rsm Resampler11To8L) CalcInOutSamplesPerOutAmt(outAmt int) (int, int) {
 
 func (rsm Resampler11To8L) Resample(in []int16, out []int16) error {
 	if len(in)%220 != 0 {
-		return ErrIncorrectInLen
+		return &LengthError{"in", len(in), rsm.CalcNeedSamplesPerOutAmt(len(out))}
 	}
 
 	rsm.tmpMem = make([]int32, 104)
@@ -1031,7 +1032,7 @@ func (rsm Resampler11To16L) Reset() {
 
 func (Resampler11To16L) CalcNeedSamplesPerOutAmt(outAmt int) int {
 	divider := 110
-	return (((outAmt*11)/16 + divider - 1) / divider) * divider
+	return (((outAmt*11+15)/16 + divider - 1) / divider) * divider // ceil, floor gives less output than asked
 }
 
 func (Resampler11To16L) calcOutSamplesPerInAmt(inAmt int) int {
@@ -1045,7 +1046,7 @@ func (rsm Resampler11To16L) CalcInOutSamplesPerOutAmt(outAmt int) (int, int) {
 
 func (rsm Resampler11To16L) Resample(in []int16, out []int16) error {
 	if len(in)%110 != 0 {
-		return ErrIncorrectInLen
+		return &LengthError{"in", len(in), rsm.CalcNeedSamplesPerOutAmt(len(out))}
 	}
 
 	rsm.tmpMem = make([]int32, 104)
@@ -1115,7 +1116,7 @@ func (rsm Resampler44To8L) CalcInOutSamplesPerOutAmt(outAmt int) (int, int) {
 
 func (rsm Resampler44To8L) Resample(in []int16, out []int16) error {
 	if len(in)%220 != 0 {
-		return ErrIncorrectInLen
+		return &LengthError{"in", len(in), rsm.CalcNeedSamplesPerOutAmt(len(out))}
 	}
 
 	rsm.tmpMem = make([]int32, 126)
@@ -1178,7 +1179,7 @@ func (rsm Resampler44To16L) CalcInOutSamplesPerOutAmt(outAmt int) (int, int) {
 
 func (rsm Resampler44To16L) Resample(in []int16, out []int16) error {
 	if len(in)%220 != 0 {
-		return ErrIncorrectInLen
+		return &LengthError{"in", len(in), rsm.CalcNeedSamplesPerOutAmt(len(out))}
 	}
 
 	rsm.tmpMem = make([]int32, 126)
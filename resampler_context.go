@@ -0,0 +1,95 @@
+package goresampler
+
+import (
+	"context"
+)
+
+// ProgressFunc gets amt of input samples already resampled and total amt of input samples to resample
+type ProgressFunc func(done, total int)
+
+// contextChunkOutAmt is amt of out samples (of 1 channel) resampled between ctx checks
+const contextChunkOutAmt = 1 << 14
+
+// ResampleAllInBufContext is ResampleAllInBuf that can be cancelled by ctx
+//
+// unlike ResampleAllInBuf it resamples input by rsm (the resampler batch is created with) by chunks
+// checking ctx between them and only rest of input (less than one rsm batch) by tails resampler
+// so result is same as getting all possible samples by GetBatch and calling ResampleAllInBuf after
+//
+// if ctx is done - ctx.Err() is returned, not resampled input stays in buffer and resampled samples
+// can be got as usual (batch is consistent, so it's fine to continue it)
+func (rsm *ResampleBatch) ResampleAllInBufContext(ctx context.Context) error {
+	return rsm.resampleAllInBufContext(ctx, nil)
+}
+
+func (rsm *ResampleBatch) resampleAllInBufContext(ctx context.Context, progress ProgressFunc) error {
+	total, lastDone := rsm.in.Len(), -1
+	report := func() {
+		if done := total - rsm.in.Len(); progress != nil && done != lastDone {
+			progress(done, total)
+			lastDone = done
+		}
+	}
+
+	if err := rsm.resampleBatches(ctx, report); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := rsm.ResampleAllInBuf(); err != nil {
+		return err
+	}
+	report()
+	return nil
+}
+
+// resampleBatches resamples by rsm (not tails resampler) all input it can by chunks checking ctx between them
+// and calling afterChunk after every one
+func (rsm *ResampleBatch) resampleBatches(ctx context.Context, afterChunk func()) error {
+	chunkOutAmt := contextChunkOutAmt * rsm.chAmt
+	for chunkOutAmt >= rsm.chAmt {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		inAmt, outAmt := rsm.rsm.CalcInOutSamplesPerOutAmt(chunkOutAmt)
+		if inAmt > rsm.in.Len() || outAmt == 0 { // try smaller chunk for rest of input
+			chunkOutAmt /= 2
+			continue
+		}
+		if err := rsm.resampleMore(chunkOutAmt); err != nil {
+			return err
+		}
+		afterChunk()
+	}
+	return nil
+}
+
+// ResampleAll resamples whole in from inRate to outRate with resampler configured by opts (see New)
+// and returns output wave (with tails)
+//
+// it checks ctx while resampling and returns ctx.Err() if it's done
+// use WithProgress option to get progress of resampling
+func ResampleAll(ctx context.Context, in []int16, inRate, outRate int, opts ...Option) ([]int16, error) {
+	o := options{}.newDefault()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	rsmIns, _, err := New(inRate, outRate, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	rsm := NewResampleBatch(rsmIns, inRate, outRate)
+	if err := rsm.AddBatch(in); err != nil {
+		return nil, err
+	}
+	if err := rsm.resampleAllInBufContext(ctx, o.progress); err != nil {
+		return nil, err
+	}
+	out := make([]int16, rsm.Len())
+	if err := rsm.GetBatch(out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
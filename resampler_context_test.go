@@ -0,0 +1,101 @@
+package goresampler_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	goresampler "github.com/lehatrutenb/goresampler"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// must be same as getting all possible samples by GetBatch and then ResampleAllInBuf
+func TestResampleAll(t *testing.T) {
+	for _, tt := range []struct {
+		inRate, outRate int
+		opts            []goresampler.Option
+	}{
+		{48000, 16000, nil},
+		{11025, 8000, nil},
+		{44100, 16000, []goresampler.Option{goresampler.WithQuality(goresampler.QualityBest)}},
+		{22050, 16000, []goresampler.Option{goresampler.WithStrictRates(false)}},
+		{48000, 8000, []goresampler.Option{goresampler.WithChannels(2)}},
+	} {
+		rsmIns, info, err := goresampler.New(tt.inRate, tt.outRate, tt.opts...)
+		if !assert.NoError(t, err) {
+			continue
+		}
+		in := goldenInWave(tt.inRate, 3*tt.inRate*info.Channels+123*info.Channels)
+		want := batchResampleAll(t, goresampler.NewResampleBatch(rsmIns, tt.inRate, tt.outRate), in, info.Channels)
+
+		var dones []int
+		opts := append(tt.opts, goresampler.WithProgress(func(done, total int) {
+			assert.Equal(t, len(in), total)
+			dones = append(dones, done)
+		}))
+		got, err := goresampler.ResampleAll(context.Background(), in, tt.inRate, tt.outRate, opts...)
+		assert.NoError(t, err)
+		assert.Equal(t, want, got, "%d -> %d", tt.inRate, tt.outRate)
+
+		assert.IsIncreasing(t, dones)
+		if assert.NotEmpty(t, dones) {
+			assert.Equal(t, len(in), dones[len(dones)-1])
+		}
+	}
+}
+
+func TestResampleAll_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	calls := 0
+	_, err := goresampler.ResampleAll(ctx, goldenInWave(48000, 10*48000), 48000, 16000, goresampler.WithProgress(func(done, total int) {
+		calls++
+		cancel()
+	}))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, calls)
+
+	_, err = goresampler.ResampleAll(ctx, nil, 48000, 7, goresampler.WithStrictRates(false))
+	assert.ErrorIs(t, err, context.Canceled)
+	_, err = goresampler.ResampleAll(context.Background(), nil, 48000, 7)
+	assert.ErrorIs(t, err, goresampler.ErrUnexpResRate)
+}
+
+// cancelled batch must stay consistent - continuing it gives same result
+func TestResampleBatch_ResampleAllInBufContext(t *testing.T) {
+	rsmIns, _, _ := goresampler.New(44100, 16000, goresampler.WithQuality(goresampler.QualityBest))
+	in := goldenInWave(44100, 5*44100)
+	want := batchResampleAll(t, goresampler.NewResampleBatch(rsmIns, 44100, 16000), in, 1)
+
+	rsmIns.Reset()
+	rsm := goresampler.NewResampleBatch(rsmIns, 44100, 16000)
+	assert.NoError(t, rsm.AddBatch(in))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, rsm.ResampleAllInBufContext(ctx), context.Canceled)
+	unresampled, unget := rsm.UnresampledUngetInAmt()
+	assert.Equal(t, []int{len(in), 0}, []int{unresampled, unget})
+
+	assert.NoError(t, rsm.ResampleAllInBufContext(context.Background()))
+	unresampled, _ = rsm.UnresampledUngetInAmt()
+	assert.Equal(t, 0, unresampled)
+	assert.Equal(t, want, getAll(t, &rsm))
+}
+
+func ExampleResampleAll() {
+	in := make([]int16, 48000) // 1 sec
+	out, err := goresampler.ResampleAll(context.Background(), in, 48000, 16000, goresampler.WithProgress(func(done, total int) {
+		if done == total {
+			fmt.Println("done")
+		}
+	}))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(len(out))
+	// Output:
+	// done
+	// 16000
+}
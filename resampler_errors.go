@@ -0,0 +1,66 @@
+package goresampler
+
+import (
+	"fmt"
+)
+
+// errors below carry details of failure, but still match base errors:
+//
+//	errors.Is(err, ErrUnexpResRate) // true for *RateError
+//
+// to get details use errors.As:
+//
+//	var needErr *NeedMoreInputError
+//	if errors.As(err, &needErr) { feed needErr.Need - needErr.Have samples more }
+
+// RateError is returned when resampler of Type can't convert from In rate to Out rate
+//
+// matches ErrUnexpResRate
+type RateError struct {
+	In   int
+	Out  int
+	Type ResamplerT
+}
+
+func (e *RateError) Error() string {
+	return fmt.Sprintf("%s (%s: %d -> %d)", ErrUnexpResRate, e.Type, e.In, e.Out)
+}
+
+func (e *RateError) Is(target error) bool {
+	return target == ErrUnexpResRate
+}
+
+// LengthError is returned when len of Wave ("in", "out", "out1" or "out2") passed to Resample
+// is not one that resampler expects (got from CalcInOutSamplesPerOutAmt)
+//
+// matches ErrIncorrectInLen and ErrGotIncorrectInOutLen - they were returned by different resamplers for same mistake
+type LengthError struct {
+	Wave string
+	Got  int
+	Want int
+}
+
+func (e *LengthError) Error() string {
+	return fmt.Sprintf("got unexpected %s array len %d, want %d", e.Wave, e.Got, e.Want)
+}
+
+func (e *LengthError) Is(target error) bool {
+	return target == ErrIncorrectInLen || target == ErrGotIncorrectInOutLen
+}
+
+// NeedMoreInputError is returned by batch resamplers when buffered input (Have) is less than
+// amt of input samples (Need) needed to get requested batch - so AddBatch at least Need-Have samples more
+//
+// matches ErrNotEnoughSamples
+type NeedMoreInputError struct {
+	Have int
+	Need int
+}
+
+func (e *NeedMoreInputError) Error() string {
+	return fmt.Sprintf("%s (have %d input samples, need %d)", ErrNotEnoughSamples, e.Have, e.Need)
+}
+
+func (e *NeedMoreInputError) Is(target error) bool {
+	return target == ErrNotEnoughSamples
+}
@@ -0,0 +1,92 @@
+package goresampler_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	goresampler "github.com/lehatrutenb/goresampler"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrors_RateError(t *testing.T) {
+	_, _, err := goresampler.NewResamplerAuto(8000, 16000, goresampler.ResamplerFFtT, nil)
+	assert.ErrorIs(t, err, goresampler.ErrUnexpResRate)
+
+	var rateErr *goresampler.RateError
+	if assert.ErrorAs(t, err, &rateErr) {
+		assert.Equal(t, goresampler.RateError{In: 8000, Out: 16000, Type: goresampler.ResamplerFFtT}, *rateErr)
+	}
+
+	_, _, err = goresampler.NewResamplerAuto(22050, 16000, goresampler.ResamplerConstExprT, nil)
+	assert.ErrorIs(t, err, goresampler.ErrUnexpResRate)
+	_, _, err = goresampler.NewResamplerAuto(22050, 16000, goresampler.ResamplerBestFitNotSafeT, nil)
+	assert.NoError(t, err)
+}
+
+func TestErrors_LengthError(t *testing.T) {
+	for _, rsmT := range []goresampler.ResamplerT{goresampler.ResamplerConstExprT, goresampler.ResamplerSplineT, goresampler.ResamplerFFtT} {
+		rsm, _, err := goresampler.NewResamplerAuto(48000, 16000, rsmT, nil)
+		if !assert.NoError(t, err) {
+			continue
+		}
+		inAmt, outAmt := rsm.CalcInOutSamplesPerOutAmt(1000)
+		err = rsm.Resample(make([]int16, inAmt+1), make([]int16, outAmt))
+		assert.ErrorIs(t, err, goresampler.ErrIncorrectInLen, rsmT.String())
+		assert.ErrorIs(t, err, goresampler.ErrGotIncorrectInOutLen, rsmT.String())
+
+		var lenErr *goresampler.LengthError
+		if assert.ErrorAs(t, err, &lenErr, rsmT.String()) {
+			assert.Equal(t, "in", lenErr.Wave)
+			assert.Equal(t, inAmt+1, lenErr.Got)
+			if rsmT != goresampler.ResamplerFFtT { // fft tells only amt that fits in its batches
+				assert.Equal(t, inAmt, lenErr.Want)
+			}
+		}
+	}
+
+	rsm, _, err := goresampler.NewResamplerAuto2Waves(44100, 8000, 16000, goresampler.Resampler2WavesSplineT, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	inAmt, outAmt1, outAmt2 := rsm.CalcInOutSamplesPerOutAmt(100, 100)
+	err = rsm.Resample(make([]int16, inAmt), make([]int16, outAmt1), make([]int16, outAmt2-1))
+	var lenErr *goresampler.LengthError
+	if assert.ErrorAs(t, err, &lenErr) {
+		assert.Equal(t, goresampler.LengthError{Wave: "out2", Got: outAmt2 - 1, Want: outAmt2}, *lenErr)
+	}
+}
+
+func TestErrors_NeedMoreInputError(t *testing.T) {
+	rsmIns, _, err := goresampler.NewResamplerAuto(48000, 16000, goresampler.ResamplerConstExprT, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	rsm := goresampler.NewResampleBatch(rsmIns, 48000, 16000)
+	assert.NoError(t, rsm.AddBatch(make([]int16, 100)))
+
+	out := make([]int16, 160)
+	err = rsm.GetBatch(out)
+	assert.ErrorIs(t, err, goresampler.ErrNotEnoughSamples)
+	var needErr *goresampler.NeedMoreInputError
+	if !assert.ErrorAs(t, err, &needErr) {
+		return
+	}
+	assert.Equal(t, 100, needErr.Have)
+
+	assert.NoError(t, rsm.AddBatch(make([]int16, needErr.Need-needErr.Have)))
+	assert.NoError(t, rsm.GetBatch(out))
+}
+
+func ExampleNeedMoreInputError() {
+	rsmIns, _, _ := goresampler.NewResamplerAuto(48000, 16000, goresampler.ResamplerConstExprT, nil)
+	rsm := goresampler.NewResampleBatch(rsmIns, 48000, 16000)
+
+	out := make([]int16, 160)
+	var needErr *goresampler.NeedMoreInputError
+	if errors.As(rsm.GetBatch(out), &needErr) {
+		fmt.Printf("add %d more samples\n", needErr.Need-needErr.Have)
+	}
+	// Output: add 480 more samples
+}
@@ -10,7 +10,7 @@ import (
 
 var (
 	// ErrGotIncorrectInOutLen indicates that in or out arr lens
-	// not equal to any call of ResamplerFFT.CalcInOutSamplesPerOutAmt
+	// not equal to any call of ResamplerFFT.CalcInOutSamplesPerOutAmt (returned as *LengthError)
 	ErrGotIncorrectInOutLen = errors.New("got unexpected in or out array lens")
 )
 
@@ -365,8 +365,11 @@ func (rsm *ResamplerFFT) Resample(in []int16, out []int16) error {
 
 	copy(out, utils.AFloatToS16(rsm.out))
 
-	if inInd != len(in) || outInd != len(out) {
-		return ErrGotIncorrectInOutLen
+	if inInd != len(in) { // Want is amt that fits in batches
+		return &LengthError{"in", len(in), inInd}
+	}
+	if outInd != len(out) {
+		return &LengthError{"out", len(out), outInd}
 	}
 	return nil
 }
@@ -0,0 +1,122 @@
+package goresampler
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/lehatrutenb/goresampler/internal/utils"
+)
+
+// SampleFormat describes how samples of wave are stored as bytes (see ResamplerAuto.ResampleBytes)
+type SampleFormat int
+
+const (
+	SampleFormatS16LE SampleFormat = iota // signed 16 bit little endian (default, same as []int16 in memory on most archs)
+	SampleFormatS16BE                     // signed 16 bit big endian
+	SampleFormatF32LE                     // float32 [-1; 1] little endian
+	SampleFormatMuLaw                     // G.711 mu-law, 8 bit
+	SampleFormatALaw                      // G.711 A-law, 8 bit
+)
+
+func (f SampleFormat) String() string {
+	switch f {
+	case SampleFormatS16LE:
+		return "s16le"
+	case SampleFormatS16BE:
+		return "s16be"
+	case SampleFormatF32LE:
+		return "f32le"
+	case SampleFormatMuLaw:
+		return "mulaw"
+	case SampleFormatALaw:
+		return "alaw"
+	default:
+		return "Undefined"
+	}
+}
+
+// Size returns amt of bytes per 1 sample (0 if format is unknown)
+func (f SampleFormat) Size() int {
+	switch f {
+	case SampleFormatMuLaw, SampleFormatALaw:
+		return 1
+	case SampleFormatS16LE, SampleFormatS16BE:
+		return 2
+	case SampleFormatF32LE:
+		return 4
+	default:
+		return 0
+	}
+}
+
+// Decode converts src bytes to samples written to dst
+//
+// len(dst) must be len(src) / f.Size()
+func (f SampleFormat) Decode(dst []int16, src []byte) error {
+	if f.Size() == 0 {
+		return ErrUnexpSampleFormat
+	}
+	if len(src) != len(dst)*f.Size() {
+		return &LengthError{"in", len(src), len(dst) * f.Size()}
+	}
+
+	switch f {
+	case SampleFormatS16LE:
+		for i := range dst {
+			dst[i] = int16(binary.LittleEndian.Uint16(src[i*2:]))
+		}
+	case SampleFormatS16BE:
+		for i := range dst {
+			dst[i] = int16(binary.BigEndian.Uint16(src[i*2:]))
+		}
+	case SampleFormatF32LE:
+		for i := range dst {
+			dst[i] = utils.FloatToS16(math.Float32frombits(binary.LittleEndian.Uint32(src[i*4:])))
+		}
+	case SampleFormatMuLaw:
+		for i, b := range src {
+			dst[i] = muLawDecode(b)
+		}
+	case SampleFormatALaw:
+		for i, b := range src {
+			dst[i] = aLawDecode(b)
+		}
+	}
+	return nil
+}
+
+// Encode converts src samples to bytes written to dst
+//
+// len(dst) must be len(src) * f.Size()
+func (f SampleFormat) Encode(dst []byte, src []int16) error {
+	if f.Size() == 0 {
+		return ErrUnexpSampleFormat
+	}
+	if len(dst) != len(src)*f.Size() {
+		return &LengthError{"out", len(dst), len(src) * f.Size()}
+	}
+
+	switch f {
+	case SampleFormatS16LE:
+		for i, x := range src {
+			binary.LittleEndian.PutUint16(dst[i*2:], uint16(x))
+		}
+	case SampleFormatS16BE:
+		for i, x := range src {
+			binary.BigEndian.PutUint16(dst[i*2:], uint16(x))
+		}
+	case SampleFormatF32LE:
+		for i, x := range src {
+			binary.LittleEndian.PutUint32(dst[i*4:], math.Float32bits(utils.S16ToFloat(x)))
+		}
+	case SampleFormatMuLaw:
+		for i, x := range src {
+			dst[i] = muLawEncode(x)
+		}
+	case SampleFormatALaw:
+		for i, x := range src {
+			dst[i] = aLawEncode(x)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package goresampler
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrIncorrectFrameDuration indicates that frame duration doesn't give whole positive amt of samples for out rate
+	ErrIncorrectFrameDuration = errors.New("frame duration doesn't match whole amt of samples")
+)
+
+// frameSizeOf returns amt of samples per channel in frame of frameDur for rate
+func frameSizeOf(rate int, frameDur time.Duration) (int, error) {
+	samples := int64(rate) * int64(frameDur)
+	if frameDur <= 0 || samples%int64(time.Second) != 0 {
+		return 0, ErrIncorrectFrameDuration
+	}
+	return int(samples / int64(time.Second)), nil
+}
+
+// Framer gives resampled wave by frames of same duration (like 10ms or 20ms for RTP)
+//
+// it wraps ResampleBatch: use AddBatch to push input and Frames to get all ready frames,
+// every frame has exactly FrameSize samples, so output cadence doesn't depend on sizes of input batches
+type Framer struct {
+	rsm       ResampleBatch
+	frameSize int
+}
+
+// NewFramer returns Framer over rsm (that resamples to outRate) giving frames of frameDur
+//
+// rsm must not be used by caller after NewFramer call
+// returns ErrIncorrectFrameDuration if frameDur*outRate is not whole amt of samples
+func NewFramer(rsm ResampleBatch, outRate int, frameDur time.Duration) (*Framer, error) {
+	frameSize, err := frameSizeOf(outRate, frameDur)
+	if err != nil {
+		return nil, err
+	}
+	return &Framer{rsm, frameSize * rsm.chAmt}, nil
+}
+
+// FrameSize returns amt of samples in every frame (of all channels)
+func (f *Framer) FrameSize() int {
+	return f.frameSize
+}
+
+// AddBatch is ResampleBatch.AddBatch
+func (f *Framer) AddBatch(in []int16) error {
+	return f.rsm.AddBatch(in)
+}
+
+// Frames returns all frames that can be got from added input and amt of pending resampled samples
+// that will go to next frames (including ones that would be resampled from buffered input)
+func (f *Framer) Frames() ([][]int16, int, error) {
+	frames, err := getFrames(f.rsm.GetBatch, f.frameSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	return frames, f.pendingAmt(), nil
+}
+
+// Flush resamples all buffered input and returns rest frames - last one is padded with silence
+func (f *Framer) Flush() ([][]int16, error) {
+	if err := f.rsm.ResampleAllInBuf(); err != nil {
+		return nil, err
+	}
+	frames, err := getFrames(f.rsm.GetBatch, f.frameSize)
+	if err != nil {
+		return nil, err
+	}
+	if tailAmt := f.rsm.Len(); tailAmt != 0 {
+		frame := make([]int16, f.frameSize)
+		if err := f.rsm.GetBatch(frame[:tailAmt]); err != nil {
+			return nil, err
+		}
+		frames = append(frames, frame)
+	}
+	return frames, nil
+}
+
+// pendingAmt returns amt of resampled and not got samples + amt of samples buffered input would be resampled to
+func (f *Framer) pendingAmt() int {
+	inAmt, outAmt := f.rsm.UnresampledUngetInAmt()
+	return outAmt + f.rsm.rsmTails.calcOutSamplesPerInAmt(inAmt/f.rsm.chAmt)*f.rsm.chAmt
+}
+
+// Reset is ResampleBatch.Reset
+func (f *Framer) Reset() {
+	f.rsm.Reset()
+}
+
+// Framer2Waves is Framer for ResampleBatch2Waves: both waves are given by frames of same duration
+//
+// Frames returns only pairs of frames (same amt for both waves) so both outputs go with same cadence
+type Framer2Waves struct {
+	rsm                    ResampleBatch2Waves
+	frameSize1, frameSize2 int
+	ready1, ready2         [][]int16 // frames got from rsm but not returned yet as other wave has no frame pair
+}
+
+// NewFramer2Waves returns Framer2Waves over rsm (that resamples to outRate1 and outRate2) giving frames of frameDur
+//
+// rsm must not be used by caller after NewFramer2Waves call
+// returns ErrIncorrectFrameDuration if frameDur doesn't give whole amt of samples for both rates
+func NewFramer2Waves(rsm ResampleBatch2Waves, outRate1, outRate2 int, frameDur time.Duration) (*Framer2Waves, error) {
+	frameSize1, err := frameSizeOf(outRate1, frameDur)
+	if err != nil {
+		return nil, err
+	}
+	frameSize2, err := frameSizeOf(outRate2, frameDur)
+	if err != nil {
+		return nil, err
+	}
+	return &Framer2Waves{rsm: rsm, frameSize1: frameSize1, frameSize2: frameSize2}, nil
+}
+
+// FrameSize returns amt of samples in every frame of first and second wave
+func (f *Framer2Waves) FrameSize() (int, int) {
+	return f.frameSize1, f.frameSize2
+}
+
+// AddBatch is ResampleBatch2Waves.AddBatch
+func (f *Framer2Waves) AddBatch(in []int16) error {
+	return f.rsm.AddBatch(in)
+}
+
+// Frames returns all frame pairs that can be got from added input and amts of pending samples for both waves (see Framer.Frames)
+func (f *Framer2Waves) Frames() ([][]int16, [][]int16, int, int, error) {
+	frames1, err := getFrames(f.rsm.GetBatchFirstWave, f.frameSize1)
+	if err != nil {
+		return nil, nil, 0, 0, err
+	}
+	frames2, err := getFrames(f.rsm.GetBatchSecondWave, f.frameSize2)
+	if err != nil {
+		return nil, nil, 0, 0, err
+	}
+	f.ready1, f.ready2 = append(f.ready1, frames1...), append(f.ready2, frames2...)
+
+	pairsAmt := len(f.ready1)
+	if len(f.ready2) < pairsAmt {
+		pairsAmt = len(f.ready2)
+	}
+	frames1, frames2 = f.ready1[:pairsAmt:pairsAmt], f.ready2[:pairsAmt:pairsAmt]
+	f.ready1, f.ready2 = f.ready1[pairsAmt:], f.ready2[pairsAmt:]
+	pending1, pending2 := f.pendingAmt()
+	return frames1, frames2, pending1, pending2, nil
+}
+
+// Flush resamples all buffered input and returns rest frames of both waves - last ones are padded with silence
+//
+// amts of frames can differ by one if tail of one wave ends right on frame border
+func (f *Framer2Waves) Flush() ([][]int16, [][]int16, error) {
+	if err := f.rsm.ResampleAllInBuf(); err != nil {
+		return nil, nil, err
+	}
+	frames1, err := getFrames(f.rsm.GetBatchFirstWave, f.frameSize1)
+	if err != nil {
+		return nil, nil, err
+	}
+	frames2, err := getFrames(f.rsm.GetBatchSecondWave, f.frameSize2)
+	if err != nil {
+		return nil, nil, err
+	}
+	frames1, frames2 = append(f.ready1, frames1...), append(f.ready2, frames2...)
+	f.ready1, f.ready2 = nil, nil
+
+	tailAmt1, tailAmt2 := f.rsm.Len()
+	if tailAmt1 != 0 {
+		frame := make([]int16, f.frameSize1)
+		if err := f.rsm.GetBatchFirstWave(frame[:tailAmt1]); err != nil {
+			return nil, nil, err
+		}
+		frames1 = append(frames1, frame)
+	}
+	if tailAmt2 != 0 {
+		frame := make([]int16, f.frameSize2)
+		if err := f.rsm.GetBatchSecondWave(frame[:tailAmt2]); err != nil {
+			return nil, nil, err
+		}
+		frames2 = append(frames2, frame)
+	}
+	return frames1, frames2, nil
+}
+
+func (f *Framer2Waves) pendingAmt() (int, int) {
+	inAmt, outAmt1, outAmt2 := f.rsm.UnresampledUngetInAmt()
+	inOutAmt1, inOutAmt2 := f.rsm.rsmTails.calcOutSamplesPerInAmt(inAmt)
+	return len(f.ready1)*f.frameSize1 + outAmt1 + inOutAmt1, len(f.ready2)*f.frameSize2 + outAmt2 + inOutAmt2
+}
+
+// Reset is ResampleBatch2Waves.Reset
+func (f *Framer2Waves) Reset() {
+	f.rsm.Reset()
+	f.ready1, f.ready2 = nil, nil
+}
@@ -0,0 +1,105 @@
+package goresampler_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	goresampler "github.com/lehatrutenb/goresampler"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewFramer_FrameSize(t *testing.T) {
+	rsmIns, _, _ := goresampler.New(48000, 16000, goresampler.WithChannels(2))
+	f, err := goresampler.NewFramer(goresampler.NewResampleBatch(rsmIns, 48000, 16000), 16000, 20*time.Millisecond)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 2*320, f.FrameSize())
+	}
+
+	for _, dur := range []time.Duration{0, -10 * time.Millisecond, time.Millisecond / 3} {
+		_, err = goresampler.NewFramer(goresampler.NewResampleBatch(rsmIns, 48000, 16000), 16000, dur)
+		assert.ErrorIs(t, err, goresampler.ErrIncorrectFrameDuration)
+	}
+	rsmIns, _, _ = goresampler.New(44100, 11025, goresampler.WithStrictRates(false))
+	_, err = goresampler.NewFramer(goresampler.NewResampleBatch(rsmIns, 44100, 11025), 11025, 10*time.Millisecond) // 110.25 samples
+	assert.ErrorIs(t, err, goresampler.ErrIncorrectFrameDuration)
+}
+
+// output must be same as batch one and go by frames of same size whatever input batches are
+func TestFramer(t *testing.T) {
+	for _, inRate := range []int{11025, 16000, 44100, 48000} {
+		rsmIns, _, err := goresampler.New(inRate, 8000, goresampler.WithStrictRates(false))
+		if !assert.NoError(t, err) {
+			continue
+		}
+		in := goldenInWave(inRate, inRate+123)
+		want := batchResampleAll(t, goresampler.NewResampleBatch(rsmIns, inRate, 8000), in, 80)
+		rsmIns.Reset()
+
+		f, err := goresampler.NewFramer(goresampler.NewResampleBatch(rsmIns, inRate, 8000), 8000, 10*time.Millisecond)
+		if !assert.NoError(t, err) {
+			continue
+		}
+		var got []int16
+		for l, sz := 0, 1; l < len(in); l, sz = l+sz, sz*3%997+1 { // irregular input batches
+			assert.NoError(t, f.AddBatch(in[l:min(l+sz, len(in))]))
+			frames, pending, err := f.Frames()
+			assert.NoError(t, err)
+			for _, frame := range frames {
+				assert.Len(t, frame, 80)
+				got = append(got, frame...)
+			}
+			assert.InDelta(t, len(want)*min(l+sz, len(in))/len(in)-len(got), pending, 2)
+		}
+		frames, err := f.Flush()
+		assert.NoError(t, err)
+		for _, frame := range frames {
+			assert.Len(t, frame, 80)
+			got = append(got, frame...)
+		}
+
+		if assert.Len(t, got, (len(want)+79)/80*80, "%d -> 8000", inRate) {
+			assert.Equal(t, want, got[:len(want)], "%d -> 8000", inRate)
+			assert.Equal(t, make([]int16, len(got)-len(want)), got[len(want):], "padded with silence")
+		}
+	}
+}
+
+func TestFramer2Waves(t *testing.T) {
+	rsmIns, _ := goresampler.NewResamplerSpline2Waves(44100, 16000, 8000, nil)
+	f, err := goresampler.NewFramer2Waves(goresampler.NewResampleBatch2Waves(rsmIns, 44100, 16000, 8000), 16000, 8000, 20*time.Millisecond)
+	if !assert.NoError(t, err) {
+		return
+	}
+	sz1, sz2 := f.FrameSize()
+	assert.Equal(t, []int{320, 160}, []int{sz1, sz2})
+
+	in := goldenInWave(44100, 44100)
+	var got1, got2 []int16
+	for l := 0; l < len(in); l += 441 {
+		assert.NoError(t, f.AddBatch(in[l:min(l+441, len(in))]))
+		frames1, frames2, pending1, pending2, err := f.Frames()
+		assert.NoError(t, err)
+		assert.Equal(t, len(frames1), len(frames2), "frames must go by pairs")
+		for i := range frames1 {
+			got1, got2 = append(got1, frames1[i]...), append(got2, frames2[i]...)
+		}
+		assert.Less(t, pending1, 2*320)
+		assert.Less(t, pending2, 2*160)
+	}
+	frames1, frames2, err := f.Flush()
+	assert.NoError(t, err)
+	assert.Equal(t, 16000, len(got1)+len(frames1)*320)
+	assert.Equal(t, 8000, len(got2)+len(frames2)*160)
+}
+
+func ExampleFramer() {
+	rsmIns, _, _ := goresampler.New(48000, 8000)
+	f, _ := goresampler.NewFramer(goresampler.NewResampleBatch(rsmIns, 48000, 8000), 8000, 20*time.Millisecond)
+
+	_ = f.AddBatch(make([]int16, 2000)) // ~41.7ms of 48000 wave
+	frames, pending, _ := f.Frames()
+	fmt.Println(len(frames), len(frames[0]), pending)
+	// Output: 2 160 13
+}
@@ -0,0 +1,224 @@
+package goresampler_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	goresampler "github.com/lehatrutenb/goresampler"
+)
+
+// run make runFuzz (or go test -fuzz=FuzzResampleBatch -fuzztime=1m) to search for new failing inputs
+// without -fuzz only seed corpus is checked (so it is part of usual test run)
+
+var fuzzRsmTs = []goresampler.ResamplerT{goresampler.ResamplerConstExprT, goresampler.ResamplerSplineT, goresampler.ResamplerFFtT, goresampler.ResamplerBestFitT, goresampler.ResamplerBestFitNotSafeT}
+var fuzzInRates = []int{8000, 11000, 11025, 16000, 22050, 32000, 44000, 44100, 48000}
+var fuzzOutRates = []int{8000, 16000}
+
+const fuzzMaxDurationErr = 1e-5 // same as in TestObj.Run
+const fuzzMaxLen = 1 << 14      // fft resampler is too slow on large waves to fuzz them
+
+func fuzzPick[T any](arr []T, ind uint8) T {
+	return arr[int(ind)%len(arr)]
+}
+
+// fuzzWave returns wave from lcg - content of wave doesn't matter much but let it be not only zeros
+func fuzzWave(seed uint32, amt int) []int16 {
+	res := make([]int16, amt)
+	for i := 0; i < amt; i++ {
+		seed = seed*1664525 + 1013904223
+		res[i] = int16(seed >> 16)
+	}
+	return res
+}
+
+// checkDuration checks same invariant as TestObj.Run - resampled + buffered waves have same duration as input one
+//
+// every resampling of tail may loose up to 1 output sample - so tailsAmt increases allowed error
+func checkDuration(t *testing.T, inRate, outRate int, inAmt, outAmt, unresampledIn, ungetOut, tailsAmt int) {
+	tCorr := int64(inAmt) * int64(outRate)
+	tGot := int64(outAmt)*int64(inRate) + int64(unresampledIn)*int64(outRate) + int64(ungetOut)*int64(inRate)
+	allowed := float64(tCorr)*fuzzMaxDurationErr + float64(tailsAmt)*float64(inRate)
+	if math.Abs(float64(tCorr-tGot)) > allowed {
+		t.Fatalf("%d -> %d: duration not conserved: in %d (unresampled %d) out %d (unget %d) tails %d", inRate, outRate, inAmt, unresampledIn, outAmt, ungetOut, tailsAmt)
+	}
+}
+
+func FuzzResampleAuto(f *testing.F) {
+	f.Add(uint8(0), uint8(0), uint8(0), uint16(100))
+	f.Add(uint8(0), uint8(2), uint8(1), uint16(1))
+	f.Add(uint8(1), uint8(1), uint8(1), uint16(161))
+	f.Add(uint8(2), uint8(7), uint8(0), uint16(4000))
+	f.Add(uint8(4), uint8(5), uint8(1), uint16(777))
+	f.Add(uint8(3), uint8(3), uint8(0), uint16(0))
+
+	f.Fuzz(func(t *testing.T, rsmInd, inRateInd, outRateInd uint8, outAmt uint16) {
+		rsmT, inRate, outRate := fuzzPick(fuzzRsmTs, rsmInd), fuzzPick(fuzzInRates, inRateInd), fuzzPick(fuzzOutRates, outRateInd)
+		rsm, ok, err := goresampler.NewResamplerAuto(inRate, outRate, rsmT, nil)
+		if err != nil {
+			if !errors.Is(err, goresampler.ErrUnexpResRate) {
+				t.Fatalf("unexpected error on creation of %s %d -> %d: %v", rsmT, inRate, outRate, err)
+			}
+			return
+		}
+
+		inAmt, gotOutAmt := rsm.CalcInOutSamplesPerOutAmt(int(outAmt) % fuzzMaxLen)
+		if gotOutAmt < int(outAmt)%fuzzMaxLen {
+			t.Fatalf("%s %d -> %d: asked for %d output samples, got only %d", rsmT, inRate, outRate, int(outAmt)%fuzzMaxLen, gotOutAmt)
+		}
+		if inAmt != rsm.CalcNeedSamplesPerOutAmt(int(outAmt)%fuzzMaxLen) {
+			t.Fatalf("%s %d -> %d: CalcInOutSamplesPerOutAmt and CalcNeedSamplesPerOutAmt disagree", rsmT, inRate, outRate)
+		}
+		out := make([]int16, gotOutAmt)
+		if err = rsm.Resample(fuzzWave(uint32(outAmt), inAmt), out); err != nil {
+			t.Fatalf("%s %d -> %d: failed to resample %d -> %d: %v", rsmT, inRate, outRate, inAmt, gotOutAmt, err)
+		}
+		if ok {
+			checkDuration(t, inRate, outRate, inAmt, gotOutAmt, 0, 0, 0)
+		}
+	})
+}
+
+const (
+	fuzzOpAdd = iota
+	fuzzOpAddLarge
+	fuzzOpGet
+	fuzzOpGetLarge
+	fuzzOpResampleAllInBuf
+	fuzzOpReset
+	fuzzOpsAmt
+)
+
+func FuzzResampleBatch(f *testing.F) {
+	f.Add(uint8(0), uint8(0), uint8(0), []byte{fuzzOpAdd, 10, fuzzOpGet, 5, fuzzOpResampleAllInBuf, 0, fuzzOpGet, 1})
+	f.Add(uint8(0), uint8(2), uint8(1), []byte{fuzzOpAddLarge, 200, fuzzOpGet, 53, fuzzOpGetLarge, 54, fuzzOpGet, 53})
+	f.Add(uint8(1), uint8(7), uint8(0), []byte{fuzzOpResampleAllInBuf, 0, fuzzOpAdd, 1, fuzzOpResampleAllInBuf, 0, fuzzOpGetLarge, 1})
+	f.Add(uint8(2), uint8(8), uint8(1), []byte{fuzzOpAddLarge, 255, fuzzOpGet, 200, fuzzOpReset, 0, fuzzOpAdd, 3, fuzzOpGet, 1})
+	f.Add(uint8(3), uint8(1), uint8(1), []byte{fuzzOpAddLarge, 30, fuzzOpGet, 54, fuzzOpGet, 54, fuzzOpGet, 54})
+
+	f.Fuzz(func(t *testing.T, rsmInd, inRateInd, outRateInd uint8, ops []byte) {
+		rsmT, inRate, outRate := fuzzPick(fuzzRsmTs, rsmInd), fuzzPick(fuzzInRates, inRateInd), fuzzPick(fuzzOutRates, outRateInd)
+		rsmIns, _, err := goresampler.NewResamplerAuto(inRate, outRate, rsmT, nil)
+		if err != nil {
+			return
+		}
+		rsm := goresampler.NewResampleBatch(rsmIns, inRate, outRate)
+
+		inAmt, outAmt, tailsAmt := 0, 0, 0
+		for i := 0; i+1 < len(ops) && inAmt < fuzzMaxLen*4; i += 2 {
+			arg := int(ops[i+1])
+			switch ops[i] % fuzzOpsAmt {
+			case fuzzOpAdd, fuzzOpAddLarge:
+				amt := arg
+				if ops[i]%fuzzOpsAmt == fuzzOpAddLarge {
+					amt *= 61
+				}
+				if err = rsm.AddBatch(fuzzWave(uint32(i), amt)); err != nil {
+					t.Fatalf("failed to add batch: %v", err)
+				}
+				inAmt += amt
+			case fuzzOpGet:
+				out := make([]int16, arg*3+1)
+				err = rsm.GetBatch(out)
+				if err == nil {
+					outAmt += len(out)
+				}
+			case fuzzOpGetLarge:
+				out := make([]int16, arg*3+1)
+				want := len(out)
+				err = rsm.GetLargeBatch(&out)
+				if err == nil {
+					if len(out) != want {
+						t.Fatalf("GetLargeBatch returned %d samples instead of %d", len(out), want)
+					}
+					outAmt += len(out)
+				}
+			case fuzzOpResampleAllInBuf:
+				err = rsm.ResampleAllInBuf()
+				tailsAmt++
+			case fuzzOpReset:
+				rsm.Reset()
+				inAmt, outAmt, tailsAmt = 0, 0, 0
+			}
+			if err != nil && !errors.Is(err, goresampler.ErrNotEnoughSamples) {
+				t.Fatalf("%s %d -> %d: unexpected error: %v", rsmT, inRate, outRate, err)
+			}
+			err = nil
+		}
+
+		unresampledIn, ungetOut := rsm.UnresampledUngetInAmt()
+		if ungetOut != rsm.Len() {
+			t.Fatalf("Len and UnresampledUngetInAmt disagree: %d != %d", rsm.Len(), ungetOut)
+		}
+		checkDuration(t, inRate, outRate, inAmt, outAmt, unresampledIn, ungetOut, tailsAmt)
+	})
+}
+
+func FuzzResampleBatch2Waves(f *testing.F) {
+	f.Add(uint8(0), []byte{fuzzOpAdd, 10, fuzzOpGet, 5, fuzzOpResampleAllInBuf, 0, fuzzOpGetLarge, 1})
+	f.Add(uint8(2), []byte{fuzzOpAddLarge, 200, fuzzOpGet, 53, fuzzOpGetLarge, 54, fuzzOpGet, 53})
+	f.Add(uint8(7), []byte{fuzzOpResampleAllInBuf, 0, fuzzOpAdd, 1, fuzzOpResampleAllInBuf, 0, fuzzOpGet, 0})
+	f.Add(uint8(8), []byte{fuzzOpAddLarge, 255, fuzzOpGet, 200, fuzzOpReset, 0, fuzzOpAdd, 3, fuzzOpGetLarge, 1})
+
+	f.Fuzz(func(t *testing.T, inRateInd uint8, ops []byte) {
+		inRate := fuzzPick(fuzzInRates, inRateInd)
+		rsmIns, _, err := goresampler.NewResamplerAuto2Waves(inRate, 8000, 16000, goresampler.Resampler2WavesSplineT, nil)
+		if err != nil {
+			t.Fatalf("failed to create resampler from %d: %v", inRate, err)
+		}
+		rsm := goresampler.NewResampleBatch2Waves(rsmIns, inRate, 8000, 16000)
+
+		inAmt, outAmt1, outAmt2, tailsAmt := 0, 0, 0, 0
+		for i := 0; i+1 < len(ops) && inAmt < fuzzMaxLen*4; i += 2 {
+			arg := int(ops[i+1])
+			first := arg%2 == 0 // which wave to get
+			switch ops[i] % fuzzOpsAmt {
+			case fuzzOpAdd, fuzzOpAddLarge:
+				amt := arg
+				if ops[i]%fuzzOpsAmt == fuzzOpAddLarge {
+					amt *= 61
+				}
+				if err = rsm.AddBatch(fuzzWave(uint32(i), amt)); err != nil {
+					t.Fatalf("failed to add batch: %v", err)
+				}
+				inAmt += amt
+			case fuzzOpGet:
+				out := make([]int16, arg*3+1)
+				if first {
+					if err = rsm.GetBatchFirstWave(out); err == nil {
+						outAmt1 += len(out)
+					}
+				} else if err = rsm.GetBatchSecondWave(out); err == nil {
+					outAmt2 += len(out)
+				}
+			case fuzzOpGetLarge:
+				out := make([]int16, arg*3+1)
+				want := len(out)
+				if first {
+					if err = rsm.GetLargeBatchFirstWave(&out); err == nil {
+						outAmt1 += len(out)
+					}
+				} else if err = rsm.GetLargeBatchSecondWave(&out); err == nil {
+					outAmt2 += len(out)
+				}
+				if err == nil && len(out) != want {
+					t.Fatalf("GetLargeBatch returned %d samples instead of %d", len(out), want)
+				}
+			case fuzzOpResampleAllInBuf:
+				err = rsm.ResampleAllInBuf()
+				tailsAmt++
+			case fuzzOpReset:
+				rsm.Reset()
+				inAmt, outAmt1, outAmt2, tailsAmt = 0, 0, 0, 0
+			}
+			if err != nil && !errors.Is(err, goresampler.ErrNotEnoughSamples) {
+				t.Fatalf("%d: unexpected error: %v", inRate, err)
+			}
+			err = nil
+		}
+
+		unresampledIn, ungetOut1, ungetOut2 := rsm.UnresampledUngetInAmt()
+		checkDuration(t, inRate, 8000, inAmt, outAmt1, unresampledIn, ungetOut1, tailsAmt)
+		checkDuration(t, inRate, 16000, inAmt, outAmt2, unresampledIn, ungetOut2, tailsAmt)
+	})
+}
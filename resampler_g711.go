@@ -0,0 +1,86 @@
+package goresampler
+
+// G.711 codecs (ITU-T G.711) - 8 bit logarithmic telephony formats
+
+const (
+	muLawBias = 0x84
+	muLawClip = 32635
+)
+
+// muLawEncode returns mu-law byte of x
+func muLawEncode(x int16) byte {
+	pcm := int32(x)
+	sign := (pcm >> 8) & 0x80
+	if sign != 0 {
+		pcm = -pcm
+	}
+	if pcm > muLawClip {
+		pcm = muLawClip
+	}
+	pcm += muLawBias
+
+	exponent := int32(7)
+	for mask := int32(0x4000); pcm&mask == 0 && exponent > 0; mask >>= 1 {
+		exponent--
+	}
+	mantissa := (pcm >> (exponent + 3)) & 0x0F
+	return ^byte(sign | exponent<<4 | mantissa)
+}
+
+// muLawDecode returns sample coded by mu-law byte u
+func muLawDecode(u byte) int16 {
+	u = ^u
+	exponent, mantissa := int32(u>>4)&0x07, int32(u)&0x0F
+	x := ((mantissa<<3)+muLawBias)<<exponent - muLawBias
+	if u&0x80 != 0 {
+		return int16(-x)
+	}
+	return int16(x)
+}
+
+// aLawSegEnds are max abs values (of 13 bit samples) of A-law segments
+var aLawSegEnds = [8]int32{0x1F, 0x3F, 0x7F, 0xFF, 0x1FF, 0x3FF, 0x7FF, 0xFFF}
+
+// aLawEncode returns A-law byte of x
+func aLawEncode(x int16) byte {
+	pcm := int32(x) >> 3 // A-law codes 13 bit samples
+	mask := byte(0xD5)
+	if pcm < 0 {
+		mask = 0x55
+		pcm = -pcm - 1
+	}
+
+	seg := int32(0)
+	for seg < 8 && pcm > aLawSegEnds[seg] {
+		seg++
+	}
+	if seg == 8 {
+		return 0x7F ^ mask
+	}
+	aval := byte(seg << 4)
+	if seg < 2 {
+		aval |= byte(pcm>>1) & 0x0F
+	} else {
+		aval |= byte(pcm>>seg) & 0x0F
+	}
+	return aval ^ mask
+}
+
+// aLawDecode returns sample coded by A-law byte a
+func aLawDecode(a byte) int16 {
+	a ^= 0x55
+	x := int32(a&0x0F) << 4
+	switch seg := int32(a&0x70) >> 4; seg {
+	case 0:
+		x += 8
+	case 1:
+		x += 0x108
+	default:
+		x += 0x108
+		x <<= seg - 1
+	}
+	if a&0x80 != 0 {
+		return int16(x)
+	}
+	return int16(-x)
+}
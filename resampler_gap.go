@@ -0,0 +1,122 @@
+package goresampler
+
+import "fmt"
+
+// gapFadeRateDiv sets duration of fades around gaps: inRate/gapFadeRateDiv samples (10ms)
+const gapFadeRateDiv = 100
+
+// gapState keeps what ResampleBatch needs to conceal gaps in input (see AddGap)
+//
+// amts are in frames (samples of all channels at one moment)
+type gapState struct {
+	fadeAmt    int     // frames to fade concealment out and input after gap in
+	history    []int16 // last added samples (up to fadeAmt frames) to conceal gap with
+	fadeInLeft int     // frames of next input to fade in
+}
+
+func newGapState(inRate int) gapState {
+	fadeAmt := inRate / gapFadeRateDiv
+	if fadeAmt < 1 {
+		fadeAmt = 1
+	}
+	return gapState{fadeAmt: fadeAmt}
+}
+
+// fadeIn returns in with faded in start (copy of in if it was changed) and amt of frames left to fade in
+func (gs gapState) fadeIn(in []int16, chAmt int) ([]int16, int) {
+	if gs.fadeInLeft == 0 || len(in) == 0 {
+		return in, gs.fadeInLeft
+	}
+	faded := append([]int16(nil), in...)
+	fadeInLeft := gs.fadeInLeft
+	for i := 0; i < len(faded)/chAmt && fadeInLeft > 0; i, fadeInLeft = i+1, fadeInLeft-1 {
+		gain := float64(gs.fadeAmt-fadeInLeft) / float64(gs.fadeAmt)
+		for ch := 0; ch < chAmt; ch++ {
+			faded[i*chAmt+ch] = int16(float64(faded[i*chAmt+ch]) * gain)
+		}
+	}
+	return faded, fadeInLeft
+}
+
+// added remembers last samples of added in
+func (gs *gapState) added(in []int16, chAmt int) {
+	histAmt := gs.fadeAmt * chAmt
+	if len(in) >= histAmt {
+		gs.history = append(gs.history[:0], in[len(in)-histAmt:]...)
+		return
+	}
+	if drop := len(gs.history) + len(in) - histAmt; drop > 0 {
+		gs.history = append(gs.history[:0], gs.history[drop:]...)
+	}
+	gs.history = append(gs.history, in...)
+}
+
+// conceal fills gap with last added samples mirrored (so wave is continuous) and faded out to silence
+//
+// partOfFrame - amt of samples of not full frame in the end of history (it can't be repeated)
+func (gs gapState) conceal(gap []int16, chAmt, partOfFrame int) {
+	period := gs.history[:len(gs.history)-partOfFrame]
+	period = period[len(period)%chAmt:] // from start of frame
+	periodAmt, gapAmt := len(period)/chAmt, len(gap)/chAmt
+	fadeAmt := gs.fadeAmt
+	if gapAmt < fadeAmt {
+		fadeAmt = gapAmt
+	}
+	for i := 0; i < fadeAmt && periodAmt != 0; i++ {
+		gain := float64(fadeAmt-i) / float64(fadeAmt+1)
+		for ch := 0; ch < chAmt; ch++ {
+			gap[i*chAmt+ch] = int16(float64(period[(periodAmt-1-i%periodAmt)*chAmt+ch]) * gain)
+		}
+	}
+}
+
+// breakStream resamples all buffered input and resets state of resampler
+// so samples before discontinuity don't smear through filter memory to ones after it
+//
+// gapLen samples to add after it are checked to fit input buffer first - so nothing is changed on err
+func (rsm *ResampleBatch) breakStream(gapLen int) error {
+	if !rsm.in.fits(rsm.in.Len()%rsm.chAmt + gapLen) { // not full frame is left after ResampleAllInBuf
+		return ErrBufferFull
+	}
+	if err := rsm.ResampleAllInBuf(); err != nil {
+		return err
+	}
+	rsm.rsm.Reset()
+	return nil
+}
+
+// AddSilence marks discontinuity in input and fills n input samples (of all channels) with silence
+//
+// all buffered input is resampled first (see ResampleAllInBuf) and state of resampler is reset,
+// so use it for gaps in input instead of adding zeros by AddBatch
+//
+// returns ErrIncorrectOption if n is negative, ErrBufferFull if bounded batch can't fit silence
+// ; batch is not changed on err
+func (rsm *ResampleBatch) AddSilence(n int) error {
+	if n < 0 {
+		return fmt.Errorf("%w: negative gap len %d", ErrIncorrectOption, n)
+	}
+	silence := make([]int16, rsm.layout.convertedAmt(n))
+	if err := rsm.breakStream(len(silence)); err != nil {
+		return err
+	}
+	return rsm.addBatch(silence, 0, false)
+}
+
+// AddGap is AddSilence that conceals lost n input samples (like lost packets) -
+// gap is filled with last added samples faded out to silence and input after gap is faded in
+func (rsm *ResampleBatch) AddGap(n int) error {
+	if n < 0 {
+		return fmt.Errorf("%w: negative gap len %d", ErrIncorrectOption, n)
+	}
+	gap := make([]int16, rsm.layout.convertedAmt(n))
+	if err := rsm.breakStream(len(gap)); err != nil {
+		return err
+	}
+	rsm.gap.conceal(gap, rsm.chAmt, int(rsm.pts.inAdded%int64(rsm.chAmt)))
+	if err := rsm.addBatch(gap, 0, false); err != nil {
+		return err
+	}
+	rsm.gap.fadeInLeft = rsm.gap.fadeAmt
+	return nil
+}
@@ -0,0 +1,139 @@
+package goresampler_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	goresampler "github.com/lehatrutenb/goresampler"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// getAll returns all resampled samples batch has now
+func getAll(t *testing.T, rsm *goresampler.ResampleBatch) []int16 {
+	out := make([]int16, rsm.Len())
+	assert.NoError(t, rsm.GetBatch(out))
+	return out
+}
+
+// after AddSilence resampler must work as new one - nothing from wave before silence smears to wave after it
+func TestResampleBatch_AddSilence(t *testing.T) {
+	for _, chAmt := range []int{1, 2} {
+		for _, rates := range [][2]int{{48000, 16000}, {8000, 16000}, {44100, 16000}} {
+			inRate, outRate := rates[0], rates[1]
+			rsmIns, _, err := goresampler.New(inRate, outRate, goresampler.WithStrictRates(false), goresampler.WithChannels(chAmt))
+			if !assert.NoError(t, err) {
+				continue
+			}
+			rsm := goresampler.NewResampleBatch(rsmIns, inRate, outRate)
+			before, after := goldenInWave(inRate, 1003*chAmt), goldenInWave(inRate/2, 2000*chAmt)
+			assert.NoError(t, rsm.AddBatch(before))
+			assert.NoError(t, rsm.GetBatch(make([]int16, 100*chAmt)))
+			assert.NoError(t, rsm.AddSilence(300*chAmt))
+			getAll(t, &rsm) // wave before silence
+			assert.NoError(t, rsm.AddBatch(after))
+			assert.NoError(t, rsm.ResampleAllInBuf())
+			got := getAll(t, &rsm)
+
+			rsmIns2, _, _ := goresampler.New(inRate, outRate, goresampler.WithStrictRates(false), goresampler.WithChannels(chAmt))
+			rsmNew := goresampler.NewResampleBatch(rsmIns2, inRate, outRate)
+			assert.NoError(t, rsmNew.AddBatch(append(make([]int16, 300*chAmt), after...)))
+			assert.NoError(t, rsmNew.ResampleAllInBuf())
+			assert.Equal(t, getAll(t, &rsmNew), got, "%d -> %d, %d channels", inRate, outRate, chAmt)
+		}
+	}
+}
+
+func maxStep(wave []int16) float64 {
+	res := 0.0
+	for i := 1; i < len(wave); i++ {
+		res = math.Max(res, math.Abs(float64(wave[i])-float64(wave[i-1])))
+	}
+	return res
+}
+
+// concealed gap must not give clicks - wave is continuous around gap
+func TestResampleBatch_AddGap(t *testing.T) {
+	const freq = 300.0
+	rsmIns, _, _ := goresampler.New(48000, 16000)
+	rsm := goresampler.NewResampleBatch(rsmIns, 48000, 16000)
+	wave := sinWaveAt(48000, 48000, freq, 0)
+	sinStep := 10000 * 2 * math.Pi * freq / 16000 // max step of 16000 sin wave
+
+	assert.NoError(t, rsm.AddBatch(wave[:4810])) // cut near max of amplitude
+	assert.NoError(t, rsm.AddGap(960))
+	assert.NoError(t, rsm.AddBatch(wave[4810+960+7:]))
+	assert.NoError(t, rsm.ResampleAllInBuf())
+	assert.Less(t, maxStep(getAll(t, &rsm)), 1.2*sinStep)
+
+	rsm.Reset() // same with silence gives click
+	assert.NoError(t, rsm.AddBatch(wave[:4810]))
+	assert.NoError(t, rsm.AddSilence(960))
+	assert.NoError(t, rsm.AddBatch(wave[4810+960+7:]))
+	assert.NoError(t, rsm.ResampleAllInBuf())
+	assert.Greater(t, maxStep(getAll(t, &rsm)), 2*sinStep)
+}
+
+func TestResampleBatch_AddGapPts(t *testing.T) {
+	rsmIns, _, _ := goresampler.New(44100, 16000, goresampler.WithStrictRates(false))
+	rsm := goresampler.NewResampleBatch(rsmIns, 44100, 16000)
+	assert.NoError(t, rsm.AddBatchAt(make([]int16, 441), time.Second))
+	assert.NoError(t, rsm.AddGap(441*3))
+	assert.NoError(t, rsm.AddBatch(make([]int16, 441)))
+	assert.Len(t, getAll(t, &rsm), 160) // wave before gap
+
+	pts, err := rsm.GetBatchAt(make([]int16, 480)) // gap
+	assert.NoError(t, err)
+	assert.InDelta(t, time.Second+10*time.Millisecond, pts, float64(time.Microsecond))
+	pts, err = rsm.GetBatchAt(make([]int16, 80))
+	assert.NoError(t, err)
+	assert.InDelta(t, time.Second+40*time.Millisecond, pts, float64(time.Microsecond))
+
+	assert.ErrorIs(t, rsm.AddGap(-1), goresampler.ErrIncorrectOption)
+	assert.ErrorIs(t, rsm.AddSilence(-1), goresampler.ErrIncorrectOption)
+	assert.NotErrorIs(t, rsm.AddGap(-1), goresampler.ErrIncorrectInLen)
+}
+
+// gap that doesn't fit bounded batch must not change it - nor resample buffered input
+func TestResampleBatchBounded_AddGapBufferFull(t *testing.T) {
+	for _, tc := range []struct {
+		outSize, gapLen int
+	}{
+		{480, 961}, // buffered input is resampled, but gap doesn't fit input buffer
+		{160, 10},  // buffered input doesn't fit out buffer
+	} {
+		rsmIns, _, _ := goresampler.New(48000, 16000)
+		rsm, err := goresampler.NewResampleBatchBounded(rsmIns, 48000, 16000, 960, tc.outSize)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.NoError(t, rsm.AddBatch(goldenInWave(48000, 900)))
+		before, err := rsm.MarshalBinary()
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.ErrorIs(t, rsm.AddGap(tc.gapLen), goresampler.ErrBufferFull)
+		assert.ErrorIs(t, rsm.AddSilence(tc.gapLen), goresampler.ErrBufferFull)
+		after, err := rsm.MarshalBinary()
+		assert.NoError(t, err)
+		assert.Equal(t, before, after, "out buffer size %d", tc.outSize)
+		unresampled, _ := rsm.UnresampledUngetInAmt()
+		assert.Equal(t, 900, unresampled)
+	}
+}
+
+func ExampleResampleBatch_AddGap() {
+	rsmIns, _, _ := goresampler.New(48000, 16000)
+	rsm := goresampler.NewResampleBatch(rsmIns, 48000, 16000)
+
+	_ = rsm.AddBatch(make([]int16, 960)) // packet 1
+	_ = rsm.AddGap(960)                  // packet 2 is lost
+	_ = rsm.AddBatch(make([]int16, 960)) // packet 3
+
+	_ = rsm.ResampleAllInBuf()
+	fmt.Println(rsm.Len())
+	// Output: 960
+}
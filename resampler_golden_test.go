@@ -0,0 +1,202 @@
+package goresampler_test
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	goresampler "github.com/lehatrutenb/goresampler"
+	testutils "github.com/lehatrutenb/goresampler/internal/test_utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// run go test -run Golden -update to rewrite testdata/golden after intended change of resamplers output
+var updateGolden = flag.Bool("update", false, "rewrite golden files of resamplers output")
+
+const goldenDir = "testdata/golden"
+
+// hashes are got on amd64 - archs that fuse float mul+add (arm64, ppc64, s390x) may get other spline/fft outputs
+type goldenEntry struct {
+	Len    int    `json:"len"`
+	SHA256 string `json:"sha256"`
+}
+
+type goldenFile map[string]goldenEntry
+
+func hashWave(wave []int16) string {
+	buf := make([]byte, len(wave)*2)
+	for i, x := range wave {
+		binary.LittleEndian.PutUint16(buf[i*2:], uint16(x))
+	}
+	sum := sha256.Sum256(buf)
+	return hex.EncodeToString(sum[:])
+}
+
+// goldenInWave returns deterministic wave: 2 tones + quiet noise from own lcg (not to depend on math/rand changes)
+func goldenInWave(rate, amt int) []int16 {
+	res := make([]int16, amt)
+	var seed uint32 = 12345
+	for i := 0; i < amt; i++ {
+		seed = seed*1664525 + 1013904223
+		noise := float64(int32(seed>>16)-(1<<15)) / (1 << 15)
+		x := float64(i) / float64(rate)
+		v := 0.4*math.Sin(2*math.Pi*440*x) + 0.3*math.Sin(2*math.Pi*2750*x+0.5) + 0.05*noise
+		res[i] = int16(math.Round(v * math.MaxInt16))
+	}
+	return res
+}
+
+func checkGolden(t *testing.T, fName string, got goldenFile) {
+	path := filepath.Join(goldenDir, fName)
+	if *updateGolden {
+		buf, err := json.MarshalIndent(got, "", "  ")
+		if !assert.NoError(t, err) {
+			return
+		}
+		if !assert.NoError(t, os.MkdirAll(goldenDir, 0755)) {
+			return
+		}
+		assert.NoError(t, os.WriteFile(path, append(buf, '\n'), 0644))
+		t.Logf("updated %s with %d entries", path, len(got)) // json.Marshal sorts map keys so diffs in review are small
+		return
+	}
+
+	buf, err := os.ReadFile(path)
+	if !assert.NoError(t, err, "failed to read golden file, run go test -run Golden -update to create it") {
+		return
+	}
+	want := goldenFile{}
+	if !assert.NoError(t, json.Unmarshal(buf, &want)) {
+		return
+	}
+
+	for name, w := range want {
+		g, ok := got[name]
+		if !ok {
+			t.Errorf("%s: golden entry not produced anymore", name)
+			continue
+		}
+		if g != w {
+			t.Errorf("%s: output changed: got len %d sha256 %s, want len %d sha256 %s (run with -update if change is expected)", name, g.Len, g.SHA256, w.Len, w.SHA256)
+		}
+	}
+	for name := range got {
+		if _, ok := want[name]; !ok {
+			t.Errorf("%s: no golden entry (run with -update to add it)", name)
+		}
+	}
+}
+
+var goldenRsmTs = []goresampler.ResamplerT{goresampler.ResamplerConstExprT, goresampler.ResamplerSplineT, goresampler.ResamplerFFtT, goresampler.ResamplerBestFitT}
+var goldenInRates = []int{8000, 11000, 11025, 16000, 44000, 44100, 48000}
+var goldenOutRates = []int{8000, 16000}
+
+func TestGolden_ResampleAuto(t *testing.T) {
+	got := goldenFile{}
+	for _, rsmT := range goldenRsmTs {
+		for _, inRate := range goldenInRates {
+			for _, outRate := range goldenOutRates {
+				if testutils.CheckRsmCompAb(rsmT, inRate, outRate) != nil {
+					continue
+				}
+				rsm, _, err := goresampler.NewResamplerAuto(inRate, outRate, rsmT, nil)
+				if !assert.NoError(t, err) {
+					continue
+				}
+				inAmt, outAmt := rsm.CalcInOutSamplesPerOutAmt(outRate) // ~ 1 sec
+				out := make([]int16, outAmt)
+				if !assert.NoError(t, rsm.Resample(goldenInWave(inRate, inAmt), out)) {
+					continue
+				}
+				got[fmt.Sprintf("%s:%d:%d", rsmT, inRate, outRate)] = goldenEntry{len(out), hashWave(out)}
+			}
+		}
+	}
+	checkGolden(t, "resample_auto.json", got)
+}
+
+// not aligned add size + tail to catch changes in buffering too
+func TestGolden_ResampleBatch(t *testing.T) {
+	got := goldenFile{}
+	for _, rsmT := range goldenRsmTs {
+		for _, inRate := range goldenInRates {
+			for _, outRate := range goldenOutRates {
+				if testutils.CheckRsmCompAb(rsmT, inRate, outRate) != nil {
+					continue
+				}
+				rsmIns, _, err := goresampler.NewResamplerAuto(inRate, outRate, rsmT, nil)
+				if !assert.NoError(t, err) {
+					continue
+				}
+				rsm := goresampler.NewResampleBatch(rsmIns, inRate, outRate)
+				in := goldenInWave(inRate, inRate+inRate/3)
+				out := make([]int16, 0)
+				cur := make([]int16, 320)
+				for len(in) > 0 {
+					addAmt := min(len(in), 1001)
+					assert.NoError(t, rsm.AddBatch(in[:addAmt]))
+					in = in[addAmt:]
+					for rsm.GetBatch(cur) == nil {
+						out = append(out, cur...)
+					}
+				}
+				if !assert.NoError(t, rsm.ResampleAllInBuf()) {
+					continue
+				}
+				tail := make([]int16, rsm.Len())
+				assert.NoError(t, rsm.GetBatch(tail))
+				out = append(out, tail...)
+				got[fmt.Sprintf("%s:%d:%d", rsmT, inRate, outRate)] = goldenEntry{len(out), hashWave(out)}
+			}
+		}
+	}
+	checkGolden(t, "resample_batch.json", got)
+}
+
+func TestGolden_ResampleBatch2Waves(t *testing.T) {
+	got := goldenFile{}
+	rsmT := goresampler.Resampler2WavesSplineT
+	for _, inRate := range goldenInRates {
+		if testutils.CheckRsmCompAb(rsmT, inRate, 8000) != nil || testutils.CheckRsmCompAb(rsmT, inRate, 16000) != nil {
+			continue
+		}
+		rsmIns, _, err := goresampler.NewResamplerAuto2Waves(inRate, 8000, 16000, rsmT, nil)
+		if !assert.NoError(t, err) {
+			continue
+		}
+		rsm := goresampler.NewResampleBatch2Waves(rsmIns, inRate, 8000, 16000)
+		in := goldenInWave(inRate, inRate+inRate/3)
+		out1, out2 := make([]int16, 0), make([]int16, 0)
+		cur1, cur2 := make([]int16, 160), make([]int16, 320)
+		for len(in) > 0 {
+			addAmt := min(len(in), 1001)
+			assert.NoError(t, rsm.AddBatch(in[:addAmt]))
+			in = in[addAmt:]
+			for rsm.GetBatchFirstWave(cur1) == nil {
+				out1 = append(out1, cur1...)
+			}
+			for rsm.GetBatchSecondWave(cur2) == nil {
+				out2 = append(out2, cur2...)
+			}
+		}
+		if !assert.NoError(t, rsm.ResampleAllInBuf()) {
+			continue
+		}
+		len1, len2 := rsm.Len()
+		tail1, tail2 := make([]int16, len1), make([]int16, len2)
+		assert.NoError(t, rsm.GetBatchFirstWave(tail1))
+		assert.NoError(t, rsm.GetBatchSecondWave(tail2))
+		out1, out2 = append(out1, tail1...), append(out2, tail2...)
+		got[fmt.Sprintf("%s:%d:%d", rsmT, inRate, 8000)] = goldenEntry{len(out1), hashWave(out1)}
+		got[fmt.Sprintf("%s:%d:%d", rsmT, inRate, 16000)] = goldenEntry{len(out2), hashWave(out2)}
+	}
+	checkGolden(t, "resample_batch2waves.json", got)
+}
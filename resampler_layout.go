@@ -0,0 +1,161 @@
+package goresampler
+
+import (
+	"math"
+)
+
+// ChannelMatrix converts channel layout of interleaved wave: out channel o is sum of in channels i multiplied by ChannelMatrix[o][i]
+//
+// so it has rows for every out channel and every row has gain for every in channel
+type ChannelMatrix [][]float64
+
+// 5.1 channels order is same as in WAV (FL FR FC LFE BL BR), center and back channels are mixed to front with -3dB
+const downmixCenterGain = math.Sqrt2 / 2
+
+// StandardChannelMatrix returns matrix to convert inChAmt channels to outChAmt:
+//
+//	1 -> 2 (mono upmix)         both channels are copies of mono one
+//	2 -> 1 (stereo downmix)     average of channels
+//	6 -> 2 (5.1 fold-down)      L = FL + 0.707 FC + 0.707 BL, R = FR + 0.707 FC + 0.707 BR (LFE is dropped),
+//	                            normalized not to clip
+//	6 -> 1                      5.1 fold-down and stereo downmix
+//	n -> n                      identity matrix
+//
+// for other layouts ErrIncorrectOption is returned - use own matrix with WithChannelMatrix then
+func StandardChannelMatrix(inChAmt, outChAmt int) (ChannelMatrix, error) {
+	if inChAmt <= 0 || outChAmt <= 0 {
+		return nil, ErrIncorrectOption
+	}
+	if inChAmt == outChAmt {
+		m := make(ChannelMatrix, outChAmt)
+		for o := range m {
+			m[o] = make([]float64, inChAmt)
+			m[o][o] = 1
+		}
+		return m, nil
+	}
+
+	norm := 1 / (1 + 2*downmixCenterGain)
+	surround := ChannelMatrix{
+		{norm, 0, downmixCenterGain * norm, 0, downmixCenterGain * norm, 0},
+		{0, norm, downmixCenterGain * norm, 0, 0, downmixCenterGain * norm},
+	}
+	switch [2]int{inChAmt, outChAmt} {
+	case [2]int{1, 2}:
+		return ChannelMatrix{{1}, {1}}, nil
+	case [2]int{2, 1}:
+		return ChannelMatrix{{0.5, 0.5}}, nil
+	case [2]int{6, 2}:
+		return surround, nil
+	case [2]int{6, 1}:
+		mono := make([]float64, 6)
+		for i := range mono {
+			mono[i] = (surround[0][i] + surround[1][i]) / 2
+		}
+		return ChannelMatrix{mono}, nil
+	}
+	return nil, ErrIncorrectOption
+}
+
+// valid returns true if m is not empty and all its rows have same not zero len
+func (m ChannelMatrix) valid() bool {
+	if len(m) == 0 || len(m[0]) == 0 {
+		return false
+	}
+	for _, row := range m {
+		if len(row) != len(m[0]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (m ChannelMatrix) inChAmt() int {
+	return len(m[0])
+}
+
+func (m ChannelMatrix) outChAmt() int {
+	return len(m)
+}
+
+// convertedAmt returns amt of samples n samples of in layout are converted to (nil m doesn't convert)
+func (m ChannelMatrix) convertedAmt(n int) int {
+	if m == nil {
+		return n
+	}
+	return n / m.inChAmt() * m.outChAmt()
+}
+
+// convert returns in with converted layout (saturated to int16 range), len(in) must be multiple of in channels amt
+func (m ChannelMatrix) convert(in []int16) []int16 {
+	inChAmt, outChAmt := m.inChAmt(), m.outChAmt()
+	out := make([]int16, m.convertedAmt(len(in)))
+	for i := 0; i < len(in)/inChAmt; i++ {
+		frame := in[i*inChAmt : (i+1)*inChAmt]
+		for o, row := range m {
+			sum := 0.0
+			for ch, gain := range row {
+				sum += float64(frame[ch]) * gain
+			}
+			out[i*outChAmt+o] = int16(math.Max(math.MinInt16, math.Min(math.MaxInt16, math.Round(sum))))
+		}
+	}
+	return out
+}
+
+// resamplerLayout converts channel layout of input by matrix and resamples it by rsm
+//
+// layout is converted before resampling - so downmix makes resampling cheaper
+// in amts of its funcs are in samples of in channels, out amts - of out channels
+type resamplerLayout struct {
+	rsm    Resampler // resamples matrix.outChAmt() interleaved channels
+	matrix ChannelMatrix
+}
+
+// splitLayout returns resampler without layout conversion and matrix of it (nil if rsm doesn't convert layout)
+func splitLayout(rsm Resampler) (Resampler, ChannelMatrix) {
+	rsmAuto, isAuto := rsm.(ResamplerAuto)
+	if isAuto {
+		rsm = rsmAuto.Resampler
+	}
+	rsmL, ok := rsm.(resamplerLayout)
+	if !ok {
+		if isAuto {
+			return rsmAuto, nil
+		}
+		return rsm, nil
+	}
+	if isAuto {
+		rsmAuto.Resampler = rsmL.rsm
+		return rsmAuto, rsmL.matrix
+	}
+	return rsmL.rsm, rsmL.matrix
+}
+
+func (rsm resamplerLayout) toIn(amt int) int {
+	return amt / rsm.matrix.outChAmt() * rsm.matrix.inChAmt()
+}
+
+func (rsm resamplerLayout) CalcNeedSamplesPerOutAmt(outAmt int) int {
+	return rsm.toIn(rsm.rsm.CalcNeedSamplesPerOutAmt(outAmt))
+}
+
+func (rsm resamplerLayout) calcOutSamplesPerInAmt(inAmt int) int {
+	return rsm.rsm.calcOutSamplesPerInAmt(rsm.matrix.convertedAmt(inAmt))
+}
+
+func (rsm resamplerLayout) CalcInOutSamplesPerOutAmt(outAmt int) (int, int) {
+	in, out := rsm.rsm.CalcInOutSamplesPerOutAmt(outAmt)
+	return rsm.toIn(in), out
+}
+
+func (rsm resamplerLayout) Resample(in, out []int16) error {
+	if len(in)%rsm.matrix.inChAmt() != 0 {
+		return &LengthError{"in", len(in), rsm.CalcNeedSamplesPerOutAmt(len(out))}
+	}
+	return rsm.rsm.Resample(rsm.matrix.convert(in), out)
+}
+
+func (rsm resamplerLayout) Reset() {
+	rsm.rsm.Reset()
+}
@@ -0,0 +1,159 @@
+package goresampler_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	goresampler "github.com/lehatrutenb/goresampler"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// convertLayout converts interleaved in by m like resampler must do
+func convertLayout(in []int16, m goresampler.ChannelMatrix) []int16 {
+	inChAmt, outChAmt := len(m[0]), len(m)
+	out := make([]int16, len(in)/inChAmt*outChAmt)
+	for i := 0; i < len(in)/inChAmt; i++ {
+		for o := range m {
+			sum := 0.0
+			for ch := range m[o] {
+				sum += float64(in[i*inChAmt+ch]) * m[o][ch]
+			}
+			out[i*outChAmt+o] = int16(math.Max(math.MinInt16, math.Min(math.MaxInt16, math.Round(sum))))
+		}
+	}
+	return out
+}
+
+func TestStandardChannelMatrix(t *testing.T) {
+	full := []int16{math.MaxInt16, math.MaxInt16, math.MaxInt16, math.MaxInt16, math.MaxInt16, math.MaxInt16}
+	for _, tt := range []struct {
+		inChAmt, outChAmt int
+		in, want          []int16
+	}{
+		{1, 2, []int16{100, -7}, []int16{100, 100, -7, -7}},
+		{2, 1, []int16{100, 300, -7, -8}, []int16{200, -8}},
+		{6, 2, []int16{1000, 0, 0, 5000, 0, 0}, []int16{414, 0}}, // LFE is dropped
+		{6, 2, []int16{0, 0, 1000, 0, 0, 1000}, []int16{293, 586}},
+		{6, 2, full, []int16{math.MaxInt16, math.MaxInt16}}, // not clipped (LFE is dropped)
+		{6, 1, []int16{1000, 0, 1000, 0, 0, 0}, []int16{500}},
+		{3, 3, []int16{1, 2, 3}, []int16{1, 2, 3}},
+	} {
+		m, err := goresampler.StandardChannelMatrix(tt.inChAmt, tt.outChAmt)
+		if !assert.NoError(t, err) {
+			continue
+		}
+		assert.Equal(t, tt.want, convertLayout(tt.in, m), "%d -> %d", tt.inChAmt, tt.outChAmt)
+	}
+
+	for _, chs := range [][2]int{{3, 2}, {2, 6}, {0, 1}, {1, -1}} {
+		_, err := goresampler.StandardChannelMatrix(chs[0], chs[1])
+		assert.ErrorIs(t, err, goresampler.ErrIncorrectOption)
+	}
+}
+
+// converting layout with resampling must be same as converting it before resampling
+func TestNew_ChannelLayout(t *testing.T) {
+	swap := goresampler.ChannelMatrix{{0, 1}, {1, 0}}
+	for _, tt := range []struct {
+		inRate, outRate   int
+		inChAmt, outChAmt int
+		opts              []goresampler.Option
+	}{
+		{48000, 16000, 2, 1, nil},
+		{44100, 8000, 6, 2, nil},
+		{16000, 8000, 1, 2, nil},
+		{44100, 16000, 2, 1, []goresampler.Option{goresampler.WithQuality(goresampler.QualityBest)}},
+		{22050, 48000, 6, 1, []goresampler.Option{goresampler.WithStrictRates(false)}},
+		{16000, 16000, 2, 2, nil}, // swap channels
+		{8000, 16000, 2, 2, nil},
+	} {
+		m, _ := goresampler.StandardChannelMatrix(tt.inChAmt, tt.outChAmt)
+		if tt.inChAmt == tt.outChAmt {
+			m = swap
+		}
+		opts := append([]goresampler.Option{goresampler.WithChannels(tt.inChAmt), goresampler.WithChannelMatrix(m)}, tt.opts...)
+		rsmIns, info, err := goresampler.New(tt.inRate, tt.outRate, opts...)
+		if !assert.NoError(t, err) {
+			continue
+		}
+		assert.Equal(t, []int{tt.inChAmt, tt.outChAmt}, []int{info.Channels, info.OutChannels})
+
+		in := goldenInWave(tt.inRate, tt.inRate*tt.inChAmt)
+		rsmPlain, _, _ := goresampler.New(tt.inRate, tt.outRate, append(tt.opts, goresampler.WithChannels(tt.outChAmt))...)
+		want := batchResampleAll(t, goresampler.NewResampleBatch(rsmPlain, tt.inRate, tt.outRate), convertLayout(in, m), tt.outChAmt)
+		got := batchResampleAll(t, goresampler.NewResampleBatch(rsmIns, tt.inRate, tt.outRate), in, tt.outChAmt)
+		assert.Equal(t, want, got, "%d -> %d, %d -> %d channels", tt.inRate, tt.outRate, tt.inChAmt, tt.outChAmt)
+
+		rsmIns.Reset()
+		rsmPlain.Reset()
+		inAmt, outAmt := rsmIns.CalcInOutSamplesPerOutAmt(1000)
+		plainInAmt, plainOutAmt := rsmPlain.CalcInOutSamplesPerOutAmt(1000)
+		if !assert.Equal(t, []int{plainInAmt / tt.outChAmt * tt.inChAmt, plainOutAmt}, []int{inAmt, outAmt}) || inAmt > len(in) {
+			continue // fft batch is longer than in
+		}
+		wantOut, gotOut := make([]int16, outAmt), make([]int16, outAmt)
+		assert.NoError(t, rsmPlain.Resample(convertLayout(in[:inAmt], m), wantOut))
+		assert.NoError(t, rsmIns.Resample(in[:inAmt], gotOut))
+		assert.Equal(t, wantOut, gotOut)
+	}
+}
+
+func TestNew_ChannelLayoutErrors(t *testing.T) {
+	for _, opts := range [][]goresampler.Option{
+		{goresampler.WithChannels(2), goresampler.WithOutChannels(3)},
+		{goresampler.WithOutChannels(-1)},
+		{goresampler.WithChannels(2), goresampler.WithChannelMatrix(goresampler.ChannelMatrix{{1}})},
+		{goresampler.WithChannels(2), goresampler.WithChannelMatrix(goresampler.ChannelMatrix{{1, 0}, {1}})},
+		{goresampler.WithChannelMatrix(goresampler.ChannelMatrix{})},
+		{goresampler.WithChannelMatrix(goresampler.ChannelMatrix{{1}, {1}}), goresampler.WithOutChannels(3)},
+	} {
+		_, _, err := goresampler.New(48000, 16000, opts...)
+		assert.ErrorIs(t, err, goresampler.ErrIncorrectOption)
+	}
+
+	rsmIns, _, _ := goresampler.New(48000, 16000, goresampler.WithChannels(6), goresampler.WithOutChannels(2))
+	rsm := goresampler.NewResampleBatch(rsmIns, 48000, 16000)
+	assert.ErrorIs(t, rsm.AddBatch(make([]int16, 7)), goresampler.ErrIncorrectInLen)
+	assert.NoError(t, rsm.AddBatch(make([]int16, 6*30)))
+	assert.NoError(t, rsm.AddSilence(6*30))
+	unresampled, _ := rsm.UnresampledUngetInAmt()
+	assert.Equal(t, 2*30, unresampled, "buffered input is in samples of out channels")
+}
+
+func TestMixer_ChannelLayout(t *testing.T) {
+	m, _ := goresampler.NewMixer(16000, 1)
+	stereo, err := m.AddInput(48000, 1, goresampler.WithChannels(2))
+	if !assert.NoError(t, err) {
+		return
+	}
+	in := goldenInWave(48000, 2*4800)
+	assert.NoError(t, stereo.AddBatch(in))
+	out := make([]int16, 1600)
+	_, err = m.Mix(out)
+	assert.NoError(t, err)
+
+	rsmIns, _, _ := goresampler.New(48000, 16000)
+	rsm := goresampler.NewResampleBatch(rsmIns, 48000, 16000)
+	downmix, _ := goresampler.StandardChannelMatrix(2, 1)
+	assert.NoError(t, rsm.AddBatch(convertLayout(in, downmix)))
+	want := make([]int16, 1600)
+	assert.NoError(t, rsm.GetBatch(want))
+	assert.Equal(t, want, out)
+
+	_, err = m.AddInput(48000, 1, goresampler.WithChannels(3))
+	assert.ErrorIs(t, err, goresampler.ErrIncorrectOption)
+}
+
+func ExampleWithOutChannels() {
+	// 5.1 48000 -> stereo 16000 by one resampler
+	rsmIns, info, err := goresampler.New(48000, 16000, goresampler.WithChannels(6), goresampler.WithOutChannels(2))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	inAmt, outAmt := rsmIns.CalcInOutSamplesPerOutAmt(320)
+	fmt.Println(info.Channels, info.OutChannels, inAmt, outAmt)
+	// Output: 6 2 2880 320
+}
@@ -0,0 +1,181 @@
+package goresampler
+
+import (
+	"math"
+)
+
+// Mixer resamples several waves with different rates to one out rate and mixes them
+//
+// every input has own ResampleBatch and gain ; Mixer is not safe to use from several goroutines
+type Mixer struct {
+	outRate int
+	chAmt   int
+	inputs  []*MixerInput
+	mixBuf  []float64
+}
+
+// MixerInput is input of Mixer - push its wave with AddBatch
+type MixerInput struct {
+	rsm    ResampleBatch
+	gain   float64
+	closed bool
+	wave   []int16 // read by Mix that failed on other input - mixed by next Mix
+	dry    bool    // wave is silence cause input ran dry
+	pulled bool    // wave is read and not yet mixed
+}
+
+// NewMixer returns Mixer that gives waves of outRate with chAmt interleaved channels
+func NewMixer(outRate, chAmt int) (*Mixer, error) {
+	if outRate <= 0 {
+		return nil, &RateError{outRate, outRate, ResamplerBestFitT}
+	}
+	if chAmt <= 0 {
+		return nil, ErrIncorrectOption
+	}
+	return &Mixer{outRate: outRate, chAmt: chAmt}, nil
+}
+
+// AddInput adds input with inRate that is multiplied by gain (1 - not changed) in mix
+//
+// opts configure resampler of input like in New ; input has same channels amt as Mixer by default,
+// other one (set by WithChannels) is converted to Mixer one by StandardChannelMatrix (or WithChannelMatrix)
+func (m *Mixer) AddInput(inRate int, gain float64, opts ...Option) (*MixerInput, error) {
+	opts = append(append([]Option{WithChannels(m.chAmt)}, opts...), WithOutChannels(m.chAmt))
+	rsm, _, err := New(inRate, m.outRate, opts...)
+	if err != nil {
+		return nil, err
+	}
+	in := &MixerInput{rsm: NewResampleBatch(rsm, inRate, m.outRate), gain: gain}
+	m.inputs = append(m.inputs, in)
+	return in, nil
+}
+
+// AddBatch appends wave to input, returns ErrBatchClosed after Close
+func (in *MixerInput) AddBatch(wave []int16) error {
+	if in.closed {
+		return ErrBatchClosed
+	}
+	return in.rsm.AddBatch(wave)
+}
+
+// SetGain changes gain of input for next mixed samples
+func (in *MixerInput) SetGain(gain float64) {
+	in.gain = gain
+}
+
+// Close marks end of input wave: its rest is resampled (ResampleAllInBuf), mixed to next frames
+// and then input is removed from Mixer
+func (in *MixerInput) Close() error {
+	if in.closed {
+		return nil
+	}
+	in.closed = true
+	return in.rsm.ResampleAllInBuf()
+}
+
+// InputsAmt returns amt of inputs in Mixer (closed inputs are counted till all their samples are mixed)
+func (m *Mixer) InputsAmt() int {
+	return len(m.inputs)
+}
+
+// Ready returns true if every input has enough samples to mix n samples (closed ones always have)
+//
+// use it not to mix silence instead of inputs that are late (like when mixing files)
+func (m *Mixer) Ready(n int) bool {
+	for _, in := range m.inputs {
+		if !in.closed && !in.rsm.canGet(n) {
+			return false
+		}
+	}
+	return true
+}
+
+// Mix fills out with sum of inputs multiplied by their gains (saturated to int16 range)
+//
+// input that has not enough samples for out (runs dry) gives silence and keeps its samples for next Mix calls,
+// returns amt of such inputs
+//
+// if some input fails samples already read from other ones are kept and mixed by next Mix call
+// (it must get out of same len)
+func (m *Mixer) Mix(out []int16) (int, error) {
+	if len(out)%m.chAmt != 0 {
+		return 0, &LengthError{"out", len(out), len(out) / m.chAmt * m.chAmt}
+	}
+	for _, in := range m.inputs {
+		if in.pulled && len(in.wave) != len(out) {
+			return 0, &LengthError{"out", len(out), len(in.wave)}
+		}
+	}
+	if cap(m.mixBuf) < len(out) {
+		m.mixBuf = make([]float64, len(out))
+	}
+	mix := m.mixBuf[:len(out)]
+	for i := range mix {
+		mix[i] = 0
+	}
+
+	for _, in := range m.inputs {
+		if err := in.pull(len(out)); err != nil {
+			return 0, err
+		}
+	}
+
+	dryAmt := 0
+	for _, in := range m.inputs {
+		if in.dry {
+			dryAmt++
+		}
+		for i, x := range in.wave {
+			mix[i] += float64(x) * in.gain
+		}
+		in.pulled = false
+	}
+
+	active := m.inputs[:0]
+	for _, in := range m.inputs {
+		if !in.closed || in.rsm.Len() != 0 {
+			active = append(active, in)
+		}
+	}
+	for i := len(active); i < len(m.inputs); i++ {
+		m.inputs[i] = nil // not to keep removed inputs
+	}
+	m.inputs = active
+
+	for i, x := range mix {
+		out[i] = int16(math.Max(math.MinInt16, math.Min(math.MaxInt16, math.Round(x))))
+	}
+	return dryAmt, nil
+}
+
+// pull reads next n samples of input to its wave if it's not read yet
+func (in *MixerInput) pull(n int) error {
+	if in.pulled {
+		return nil
+	}
+	if cap(in.wave) < n {
+		in.wave = make([]int16, n)
+	}
+	in.wave = in.wave[:n]
+	dry, err := in.read(in.wave)
+	if err != nil {
+		return err
+	}
+	in.dry, in.pulled = dry, true
+	return nil
+}
+
+// read fills wave with next samples of input or with silence if there is not enough of them (returns true then)
+// closed input gives rest of its samples padded with silence
+func (in *MixerInput) read(wave []int16) (bool, error) {
+	if in.rsm.canGet(len(wave)) {
+		return false, in.rsm.GetBatch(wave)
+	}
+	for i := range wave {
+		wave[i] = 0
+	}
+	if in.closed {
+		return false, in.rsm.GetBatch(wave[:in.rsm.Len()])
+	}
+	return true, nil
+}
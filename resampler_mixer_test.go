@@ -0,0 +1,199 @@
+package goresampler_test
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	goresampler "github.com/lehatrutenb/goresampler"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mixed wave must be saturated sum of resampled inputs multiplied by gains
+func TestMixer(t *testing.T) {
+	inRates, gains := []int{44100, 48000, 16000}, []float64{0.5, 1, 2}
+	const outRate, frameSize = 48000, 480
+	opts := []goresampler.Option{goresampler.WithStrictRates(false)}
+	m, err := goresampler.NewMixer(outRate, 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	want := make([]float64, outRate)
+	ins := make([]*goresampler.MixerInput, len(inRates))
+	waves := make([][]int16, len(inRates))
+	for i, inRate := range inRates {
+		ins[i], err = m.AddInput(inRate, gains[i], opts...)
+		if !assert.NoError(t, err) {
+			return
+		}
+		waves[i] = goldenInWave(inRate, inRate) // 1 sec
+
+		rsmIns, _, _ := goresampler.New(inRate, outRate, opts...)
+		rsm := goresampler.NewResampleBatch(rsmIns, inRate, outRate)
+		assert.NoError(t, rsm.AddBatch(waves[i]))
+		out := make([]int16, frameSize)
+		for j := 0; rsm.GetBatch(out) == nil && j < len(want); j += frameSize {
+			for k, x := range out {
+				want[j+k] += float64(x) * gains[i]
+			}
+		}
+	}
+
+	var got []int16
+	frame := make([]int16, frameSize)
+	for l := 0; l < 100; l++ { // by 10ms chunks
+		for i, inRate := range inRates {
+			assert.NoError(t, ins[i].AddBatch(waves[i][l*inRate/100:(l+1)*inRate/100]))
+		}
+		for m.Ready(frameSize) {
+			dry, err := m.Mix(frame)
+			assert.NoError(t, err)
+			assert.Equal(t, 0, dry)
+			got = append(got, frame...)
+		}
+	}
+	if !assert.Greater(t, len(got), outRate*9/10) {
+		return
+	}
+	for i, x := range got {
+		assert.Equal(t, int16(math.Max(math.MinInt16, math.Min(math.MaxInt16, math.Round(want[i])))), x, "sample %d", i)
+	}
+}
+
+func TestMixer_DryAndClose(t *testing.T) {
+	m, _ := goresampler.NewMixer(16000, 2)
+	in1, err := m.AddInput(48000, 1)
+	assert.NoError(t, err)
+	in2, err := m.AddInput(16000, 1)
+	assert.NoError(t, err)
+
+	loud := make([]int16, 2*480)
+	for i := range loud {
+		loud[i] = 30000
+	}
+	assert.NoError(t, in1.AddBatch(loud))
+	assert.NoError(t, in1.AddBatch(loud))
+	assert.NoError(t, in1.AddBatch(loud[:2*300])) // 2 batches of 480 frames + 300 frames
+	assert.NoError(t, in2.AddBatch(loud[:2*160]))
+	assert.True(t, m.Ready(2*160))
+
+	out := make([]int16, 2*160)
+	dry, err := m.Mix(out)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, dry)
+	assert.Equal(t, int16(math.MaxInt16), out[len(out)-1], "sum is saturated")
+
+	assert.False(t, m.Ready(2*160))
+	dry, err = m.Mix(out) // in2 runs dry
+	assert.NoError(t, err)
+	assert.Equal(t, 1, dry)
+	assert.Less(t, out[len(out)-1], int16(math.MaxInt16), "only in1 is mixed")
+
+	assert.NoError(t, in1.Close())
+	assert.NoError(t, in1.Close())
+	assert.ErrorIs(t, in1.AddBatch(loud), goresampler.ErrBatchClosed)
+	assert.Equal(t, 2, m.InputsAmt())
+	dry, err = m.Mix(out) // in1 gives its rest, in2 - silence
+	assert.NoError(t, err)
+	assert.Equal(t, 1, dry)
+	assert.Equal(t, 1, m.InputsAmt(), "closed input is removed when it gave all samples")
+
+	in2.SetGain(0)
+	assert.NoError(t, in2.AddBatch(loud[:2*160]))
+	_, err = m.Mix(out)
+	assert.NoError(t, err)
+	assert.Equal(t, make([]int16, len(out)), out)
+
+	_, err = m.Mix(out[:3])
+	assert.ErrorIs(t, err, goresampler.ErrIncorrectInLen)
+	_, err = goresampler.NewMixer(0, 1)
+	assert.ErrorIs(t, err, goresampler.ErrUnexpResRate)
+	_, err = goresampler.NewMixer(16000, 0)
+	assert.ErrorIs(t, err, goresampler.ErrIncorrectOption)
+	_, err = m.AddInput(0, 1)
+	assert.ErrorIs(t, err, goresampler.ErrUnexpResRate)
+}
+
+// failingResampler fails every Resample call after first failAfter ones
+type failingResampler struct {
+	goresampler.Resampler
+	failAfter *int
+}
+
+func (rsm failingResampler) Resample(in, out []int16) error {
+	if *rsm.failAfter == 0 {
+		return errTestResample
+	}
+	*rsm.failAfter--
+	return rsm.Resampler.Resample(in, out)
+}
+
+var errTestResample = errors.New("test resample error")
+
+// failed input must not break list of inputs mixed by next Mix calls
+func TestMixer_InputError(t *testing.T) {
+	m, _ := goresampler.NewMixer(16000, 1)
+	in0, _ := m.AddInput(16000, 1) // gives all its samples to Mix that fails
+	in1, _ := m.AddInput(16000, 1) // ramp - to see which of its samples are mixed
+	in2, _ := m.AddInput(48000, 1)
+	in3, _ := m.AddInput(16000, 1)
+
+	failAfter := 0
+	rsmIns, _, _ := goresampler.New(48000, 16000)
+	in2.SetBatch(goresampler.NewResampleBatch(failingResampler{rsmIns, &failAfter}, 48000, 16000))
+	for i, in := range []*goresampler.MixerInput{in0, in1, in2, in3} {
+		wave := make([]int16, 960)
+		for j := range wave {
+			wave[j] = int16(1000 << i)
+			if in == in1 {
+				wave[j] = int16(10 * j)
+			}
+		}
+		if in == in0 {
+			wave = wave[:160]
+		}
+		assert.NoError(t, in.AddBatch(wave))
+	}
+	assert.NoError(t, in0.Close())
+
+	out := make([]int16, 160)
+	_, err := m.Mix(out)
+	assert.ErrorIs(t, err, errTestResample)
+	assert.Equal(t, 4, m.InputsAmt())
+	_, err = m.Mix(out[:80])
+	assert.ErrorAs(t, err, new(*goresampler.LengthError), "samples read by failed Mix are kept for same len")
+
+	failAfter = 10
+	dry, err := m.Mix(out)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, dry)
+	for j := 100; j < len(out); j++ { // resampler of in2 warms up before
+		assert.InDelta(t, 1000+10*j+4000+8000, out[j], 50, "first samples of inputs read by failed Mix are mixed ; ind %d", j)
+	}
+	assert.Equal(t, 3, m.InputsAmt())
+
+	_, err = m.Mix(out)
+	assert.NoError(t, err)
+	assert.InDelta(t, 10*(160+159)+4000+8000, out[159], 50, "every input is mixed once")
+}
+
+func ExampleMixer() {
+	m, _ := goresampler.NewMixer(48000, 1)
+	music, _ := m.AddInput(44100, 0.3, goresampler.WithStrictRates(false)) // 48000 out is not base conversion
+	mic, _ := m.AddInput(48000, 1)
+	tts, _ := m.AddInput(16000, 0.8, goresampler.WithStrictRates(false))
+
+	_ = music.AddBatch(make([]int16, 882)) // 20ms from every input
+	_ = mic.AddBatch(make([]int16, 960))
+	_ = tts.AddBatch(make([]int16, 320))
+
+	frame := make([]int16, 480)
+	for m.Ready(len(frame)) {
+		dry, _ := m.Mix(frame)
+		fmt.Println(len(frame), dry)
+	}
+	// Output: 480 0
+}
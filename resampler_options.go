@@ -0,0 +1,254 @@
+package goresampler
+
+import (
+	"errors"
+	"math"
+)
+
+var (
+	// ErrIncorrectOption indicates that option passed to New has value out of its range
+	ErrIncorrectOption = errors.New("got incorrect option value")
+	// ErrUnexpSampleFormat indicates that SampleFormat is not one of SampleFormat consts
+	ErrUnexpSampleFormat = errors.New("got unexpected sample format")
+)
+
+// Quality describes what to prefer if resampler type is not set by WithType
+type Quality int
+
+const (
+	QualityFast Quality = iota // ResamplerBestFitT - const expression resamplers (spline where there are no ones)
+	QualityBest                // ResamplerFFtT if it can convert rates (only downsampling), otherwise same as QualityFast
+)
+
+type options struct {
+	rsmT        ResamplerT
+	rsmTSet     bool
+	maxTimeErr  float64
+	quality     Quality
+	channels    int
+	outChannels int // 0 - same as channels
+	matrix      ChannelMatrix
+	format      SampleFormat
+	strictRates bool
+	progress    ProgressFunc
+	calib       *Calibration
+	minSNRDB    float64
+}
+
+func (options) newDefault() options {
+	return options{rsmT: ResamplerBestFitT, maxTimeErr: baseTimeErrRate, quality: QualityFast, channels: 1, format: SampleFormatS16LE, strictRates: true}
+}
+
+// Option configures resampler got from New
+type Option func(*options)
+
+// WithType sets resampler type (ResamplerBestFitT by default), overrides WithQuality
+func WithType(rsmT ResamplerT) Option {
+	return func(o *options) {
+		o.rsmT = rsmT
+		o.rsmTSet = true
+	}
+}
+
+// WithMaxTimeErr sets max relative (0..1) error of output duration per batch (1e-6 by default)
+//
+// if resampler fails to fit it - it's still fine to use, Info.TimeErr tells error it got
+func WithMaxTimeErr(maxErrRate float64) Option {
+	return func(o *options) {
+		o.maxTimeErr = maxErrRate
+	}
+}
+
+// WithQuality chooses resampler type by q if WithType is not set (QualityFast by default)
+func WithQuality(q Quality) Option {
+	return func(o *options) {
+		o.quality = q
+	}
+}
+
+// WithChannels sets amt of interleaved channels in waves (1 by default)
+//
+// every channel is resampled by own resampler ; all lens in Resampler funcs are in samples of all channels
+// (in lens - of in channels, out lens - of out channels if layout is converted by WithOutChannels or WithChannelMatrix)
+func WithChannels(chAmt int) Option {
+	return func(o *options) {
+		o.channels = chAmt
+	}
+}
+
+// WithOutChannels sets amt of interleaved channels in output waves (same as WithChannels by default)
+//
+// layout is converted by StandardChannelMatrix (ErrIncorrectOption is returned from New if there is no such one)
+func WithOutChannels(chAmt int) Option {
+	return func(o *options) {
+		o.outChannels = chAmt
+	}
+}
+
+// WithChannelMatrix sets own matrix to convert channel layout: len(m[o]) must be amt of WithChannels
+// and len(m) - amt of out channels (and same as WithOutChannels if it's set)
+//
+// layout is converted before resampling ; ResampleBatch converts it when input is added -
+// so its amts of buffered input samples (like UnresampledUngetInAmt) are in samples of out channels
+func WithChannelMatrix(m ChannelMatrix) Option {
+	return func(o *options) {
+		o.matrix = m
+	}
+}
+
+// WithSampleFormat sets format of waves passed to ResamplerAuto.ResampleBytes (SampleFormatS16LE by default)
+func WithSampleFormat(f SampleFormat) Option {
+	return func(o *options) {
+		o.format = f
+	}
+}
+
+// WithStrictRates(false) allows rates conversions that are badly tested (or not tested at all)
+// with chosen resampler type - like ResamplerBestFitNotSafeT does (true by default)
+func WithStrictRates(strict bool) Option {
+	return func(o *options) {
+		o.strictRates = strict
+	}
+}
+
+// WithCalibration makes New choose resampler type by c (see Calibration.Choose) if WithType is not set, overrides WithQuality
+//
+// rate pair is measured on first New with it if c has no its entries ; WithCalibration(nil, 0) disables it (default one)
+// - type is chosen by WithQuality then, as it is if c fails to measure rates
+func WithCalibration(c *Calibration, minSNRDB float64) Option {
+	return func(o *options) {
+		o.calib, o.minSNRDB = c, minSNRDB
+	}
+}
+
+// WithProgress sets callback ResampleAll reports its progress to (New ignores it)
+func WithProgress(progress ProgressFunc) Option {
+	return func(o *options) {
+		o.progress = progress
+	}
+}
+
+// Info describes resampler got from New
+type Info struct {
+	Type        ResamplerT // type resampler is built with (set by WithType or chosen by WithQuality)
+	Algorithm   ResamplerT // one of ResamplerConstExprT (also for not changing rates), ResamplerSplineT or ResamplerFFtT - what really resamples
+	BatchInAmt  int        // min amt of input samples (of 1 channel) that are resampled at once
+	BatchOutAmt int        // amt of output samples (of 1 channel) got from BatchInAmt input ones
+	TimeErr     float64    // relative error of output duration of 1 batch
+	TimeErrOk   bool       // false if resampler failed to fit WithMaxTimeErr (but it is still fine to use)
+	Channels    int
+	OutChannels int // amt of channels in output waves (differs from Channels if layout is converted)
+	Format      SampleFormat
+	StrictRates bool
+	Calibrated  bool // Type is chosen by WithCalibration
+	BelowMinSNR bool // Calibrated Type has snr less than WithCalibration minSNRDB (no measured type reaches it - one with best snr is chosen)
+}
+
+// channelMatrix returns matrix to convert layout by (nil if it's not converted) and amt of out channels
+func (o options) channelMatrix() (ChannelMatrix, int, error) {
+	if o.matrix != nil {
+		if !o.matrix.valid() || o.matrix.inChAmt() != o.channels || (o.outChannels != 0 && o.outChannels != o.matrix.outChAmt()) {
+			return nil, 0, ErrIncorrectOption
+		}
+		return o.matrix, o.matrix.outChAmt(), nil
+	}
+	if o.outChannels == 0 || o.outChannels == o.channels {
+		return nil, o.channels, nil
+	}
+	m, err := StandardChannelMatrix(o.channels, o.outChannels)
+	return m, o.outChannels, err
+}
+
+// isBaseConversion returns true if rates are from well tested ones
+func isBaseConversion(inRate, outRate int) bool {
+	return (inRate == 8000 || inRate == 11025 || inRate == 16000 || inRate == 44100 || inRate == 48000) && (outRate == 8000 || outRate == 16000)
+}
+
+// chooseType returns resampler type to build, true if it's chosen by calibration and true if it doesn't reach min snr then
+func (o options) chooseType(inRate, outRate int) (ResamplerT, bool, bool) {
+	if !o.rsmTSet && o.calib != nil && inRate > 0 && outRate > 0 && inRate != outRate {
+		rsmT, err := o.calib.Choose(inRate, outRate, o.minSNRDB, o.strictRates)
+		if err == nil || errors.Is(err, ErrMinSNRNotMet) {
+			return rsmT, true, err != nil
+		}
+	}
+	rsmT := o.rsmT
+	if !o.rsmTSet && o.quality == QualityBest && inRate > outRate && (isBaseConversion(inRate, outRate) || !o.strictRates) {
+		rsmT = ResamplerFFtT
+	}
+	if rsmT == ResamplerBestFitT && !o.strictRates {
+		rsmT = ResamplerBestFitNotSafeT
+	}
+	return rsmT, false, false
+}
+
+func algorithmOf(rsm Resampler) ResamplerT {
+	switch rsm.(type) {
+	case ResamplerSpline:
+		return ResamplerSplineT
+	case *ResamplerFFT:
+		return ResamplerFFtT
+	default:
+		return ResamplerConstExprT
+	}
+}
+
+// timeErrRate returns relative difference of durations of in and out waves
+func timeErrRate(inRate, outRate, inAmt, outAmt int) float64 {
+	if inAmt == 0 {
+		return 0
+	}
+	inDur := float64(inAmt) * float64(outRate)
+	return math.Abs(inDur-float64(outAmt)*float64(inRate)) / inDur
+}
+
+/*
+New returns resampler from inRate to outRate configured by opts
+
+	rsm, info, err := New(44100, 16000, WithQuality(QualityBest), WithChannels(2))
+
+errors:
+
+	*RateError (matches ErrUnexpResRate) if chosen resampler type not implement such rate conversion
+	ErrUnexpResamplerType if WithType got not one of ResamplerT consts
+	ErrIncorrectOption if other options got values out of their range
+*/
+func New(inRate, outRate int, opts ...Option) (ResamplerAuto, Info, error) {
+	o := options{}.newDefault()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.channels < 1 || o.outChannels < 0 || o.maxTimeErr < 0 || o.format.Size() == 0 || (o.quality != QualityFast && o.quality != QualityBest) {
+		return ResamplerAuto{}, Info{}, ErrIncorrectOption
+	}
+	matrix, outChAmt, err := o.channelMatrix()
+	if err != nil {
+		return ResamplerAuto{}, Info{}, err
+	}
+	rsmT, calibrated, belowMinSNR := o.chooseType(inRate, outRate)
+	if inRate <= 0 || outRate <= 0 {
+		return ResamplerAuto{}, Info{}, &RateError{inRate, outRate, rsmT}
+	}
+
+	rsms := make([]Resampler, outChAmt) // constexpr resamplers keep state of wave - so each channel needs own one
+	ok := true
+	for ch := range rsms {
+		var err error
+		if rsms[ch], ok, err = newResampler(inRate, outRate, rsmT, &o.maxTimeErr, o.strictRates); err != nil {
+			return ResamplerAuto{}, Info{}, err
+		}
+	}
+
+	var rsm Resampler = rsms[0]
+	if outChAmt > 1 {
+		rsm = newResamplerChannels(rsms)
+	}
+	if matrix != nil {
+		rsm = resamplerLayout{rsm, matrix}
+	}
+
+	info := Info{Type: rsmT, Algorithm: algorithmOf(rsms[0]), TimeErrOk: ok, Channels: o.channels, OutChannels: outChAmt, Format: o.format, StrictRates: o.strictRates, Calibrated: calibrated, BelowMinSNR: belowMinSNR}
+	info.BatchInAmt, info.BatchOutAmt = rsms[0].CalcInOutSamplesPerOutAmt(1)
+	info.TimeErr = timeErrRate(inRate, outRate, info.BatchInAmt, info.BatchOutAmt)
+	return ResamplerAuto{inRate, outRate, rsm, o.format}, info, nil
+}
@@ -0,0 +1,275 @@
+package goresampler_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	goresampler "github.com/lehatrutenb/goresampler"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew_Info(t *testing.T) {
+	_, info, err := goresampler.New(48000, 16000)
+	if assert.NoError(t, err) {
+		assert.Equal(t, goresampler.Info{Type: goresampler.ResamplerBestFitT, Algorithm: goresampler.ResamplerConstExprT, BatchInAmt: 480, BatchOutAmt: 160, TimeErr: 0,
+			TimeErrOk: true, Channels: 1, OutChannels: 1, Format: goresampler.SampleFormatS16LE, StrictRates: true}, info)
+	}
+
+	_, info, err = goresampler.New(44100, 16000)
+	if assert.NoError(t, err) {
+		assert.Equal(t, goresampler.ResamplerSplineT, info.Algorithm)
+		assert.True(t, info.TimeErrOk)
+		assert.LessOrEqual(t, info.TimeErr, 1e-6)
+	}
+
+	_, info, err = goresampler.New(100003, 8000, goresampler.WithMaxTimeErr(1e-12), goresampler.WithStrictRates(false)) // prime rate - no exact batch less than 1e5
+	if assert.NoError(t, err) {
+		assert.False(t, info.TimeErrOk)
+		assert.Greater(t, info.TimeErr, 1e-12)
+	}
+
+	_, info, err = goresampler.New(16000, 16000, goresampler.WithType(goresampler.ResamplerFFtT))
+	if assert.NoError(t, err) {
+		assert.Equal(t, goresampler.ResamplerConstExprT, info.Algorithm)
+		assert.Equal(t, 1, info.BatchInAmt)
+	}
+}
+
+func TestNew_Quality(t *testing.T) {
+	_, info, err := goresampler.New(44100, 16000, goresampler.WithQuality(goresampler.QualityBest))
+	if assert.NoError(t, err) {
+		assert.Equal(t, goresampler.ResamplerFFtT, info.Type)
+		assert.Equal(t, goresampler.ResamplerFFtT, info.Algorithm)
+	}
+
+	_, info, err = goresampler.New(8000, 16000, goresampler.WithQuality(goresampler.QualityBest)) // fft can't upsample
+	if assert.NoError(t, err) {
+		assert.Equal(t, goresampler.ResamplerBestFitT, info.Type)
+		assert.Equal(t, goresampler.ResamplerConstExprT, info.Algorithm)
+	}
+
+	_, info, err = goresampler.New(48000, 8000, goresampler.WithQuality(goresampler.QualityBest), goresampler.WithType(goresampler.ResamplerSplineT))
+	if assert.NoError(t, err) {
+		assert.Equal(t, goresampler.ResamplerSplineT, info.Algorithm)
+	}
+}
+
+func TestNew_StrictRates(t *testing.T) {
+	_, _, err := goresampler.New(22050, 16000)
+	assert.ErrorIs(t, err, goresampler.ErrUnexpResRate)
+
+	_, info, err := goresampler.New(22050, 16000, goresampler.WithStrictRates(false))
+	if assert.NoError(t, err) {
+		assert.Equal(t, goresampler.ResamplerBestFitNotSafeT, info.Type)
+		assert.Equal(t, goresampler.ResamplerSplineT, info.Algorithm)
+	}
+
+	_, info, err = goresampler.New(22050, 16000, goresampler.WithStrictRates(false), goresampler.WithType(goresampler.ResamplerSplineT))
+	if assert.NoError(t, err) {
+		assert.Equal(t, goresampler.ResamplerSplineT, info.Type)
+	}
+
+	_, _, err = goresampler.New(22050, 16000, goresampler.WithStrictRates(false), goresampler.WithType(goresampler.ResamplerConstExprT))
+	assert.ErrorIs(t, err, goresampler.ErrUnexpResRate)
+}
+
+func TestNew_IncorrectOptions(t *testing.T) {
+	for _, opt := range []goresampler.Option{goresampler.WithChannels(0), goresampler.WithMaxTimeErr(-1), goresampler.WithSampleFormat(42), goresampler.WithQuality(42)} {
+		_, _, err := goresampler.New(48000, 16000, opt)
+		assert.ErrorIs(t, err, goresampler.ErrIncorrectOption)
+	}
+	_, _, err := goresampler.New(48000, 16000, goresampler.WithType(42))
+	assert.ErrorIs(t, err, goresampler.ErrUnexpResamplerType)
+	_, _, err = goresampler.New(0, 16000, goresampler.WithStrictRates(false))
+	assert.ErrorIs(t, err, goresampler.ErrUnexpResRate)
+}
+
+func TestNew_SameAsNewResamplerAuto(t *testing.T) {
+	maxErr := 1e-4
+	for _, rsmT := range []goresampler.ResamplerT{goresampler.ResamplerConstExprT, goresampler.ResamplerSplineT, goresampler.ResamplerFFtT, goresampler.ResamplerBestFitT} {
+		rsmOld, okOld, errOld := goresampler.NewResamplerAuto(44100, 8000, rsmT, &maxErr)
+		rsmNew, info, errNew := goresampler.New(44100, 8000, goresampler.WithType(rsmT), goresampler.WithMaxTimeErr(maxErr))
+		assert.Equal(t, errOld, errNew)
+		if errOld != nil {
+			continue
+		}
+		assert.Equal(t, okOld, info.TimeErrOk)
+
+		inAmt, outAmt := rsmOld.CalcInOutSamplesPerOutAmt(1000)
+		in := goldenInWave(44100, inAmt)
+		outOld, outNew := make([]int16, outAmt), make([]int16, outAmt)
+		assert.NoError(t, rsmOld.Resample(in, outOld))
+		assert.NoError(t, rsmNew.Resample(in, outNew))
+		assert.Equal(t, outOld, outNew, rsmT.String())
+	}
+}
+
+func interleaveWaves(waves ...[]int16) []int16 {
+	res := make([]int16, len(waves[0])*len(waves))
+	for i := range waves[0] {
+		for ch, w := range waves {
+			res[i*len(waves)+ch] = w[i]
+		}
+	}
+	return res
+}
+
+// every channel must be resampled same as mono wave
+func TestNew_Channels(t *testing.T) {
+	for _, rsmT := range []goresampler.ResamplerT{goresampler.ResamplerConstExprT, goresampler.ResamplerSplineT, goresampler.ResamplerFFtT} {
+		rsmMono, _, err := goresampler.New(48000, 16000, goresampler.WithType(rsmT))
+		if !assert.NoError(t, err) {
+			continue
+		}
+		rsmStereo, info, err := goresampler.New(48000, 16000, goresampler.WithType(rsmT), goresampler.WithChannels(2))
+		if !assert.NoError(t, err) {
+			continue
+		}
+		assert.Equal(t, 2, info.Channels)
+
+		inAmt, outAmt := rsmMono.CalcInOutSamplesPerOutAmt(1000)
+		left, right := goldenInWave(48000, inAmt), goldenInWave(24000, inAmt)
+		outLeft, outRight := make([]int16, outAmt), make([]int16, outAmt)
+		assert.NoError(t, rsmMono.Resample(left, outLeft))
+		rsmMono.Reset()
+		assert.NoError(t, rsmMono.Resample(right, outRight))
+
+		stInAmt, stOutAmt := rsmStereo.CalcInOutSamplesPerOutAmt(2 * 1000)
+		if !assert.Equal(t, []int{2 * inAmt, 2 * outAmt}, []int{stInAmt, stOutAmt}) {
+			continue
+		}
+		out := make([]int16, stOutAmt)
+		assert.NoError(t, rsmStereo.Resample(interleaveWaves(left, right), out))
+		assert.Equal(t, interleaveWaves(outLeft, outRight), out, rsmT.String())
+
+		assert.ErrorIs(t, rsmStereo.Resample(make([]int16, stInAmt+1), out), goresampler.ErrIncorrectInLen)
+	}
+}
+
+func TestNew_ChannelsBatchTail(t *testing.T) {
+	rsmMono, _, _ := goresampler.New(44100, 16000)
+	rsmStereo, _, err := goresampler.New(44100, 16000, goresampler.WithChannels(2))
+	if !assert.NoError(t, err) {
+		return
+	}
+	left, right := goldenInWave(44100, 1001), goldenInWave(22050, 1001)
+
+	batchMono := goresampler.NewResampleBatch(rsmMono, 44100, 16000)
+	batchStereo := goresampler.NewResampleBatch(rsmStereo, 44100, 16000)
+	assert.NoError(t, batchStereo.AddBatch(interleaveWaves(left, right)))
+	assert.NoError(t, batchStereo.AddBatch([]int16{1})) // not full frame must stay in buffer
+	assert.NoError(t, batchStereo.ResampleAllInBuf())
+	unresampled, _ := batchStereo.UnresampledUngetInAmt()
+	assert.Equal(t, 1, unresampled)
+
+	outs := make([][]int16, 2)
+	for ch, w := range [][]int16{left, right} {
+		batchMono.Reset()
+		assert.NoError(t, batchMono.AddBatch(w))
+		assert.NoError(t, batchMono.ResampleAllInBuf())
+		outs[ch] = make([]int16, batchMono.Len())
+		assert.NoError(t, batchMono.GetBatch(outs[ch]))
+	}
+	out := make([]int16, batchStereo.Len())
+	assert.NoError(t, batchStereo.GetBatch(out))
+	assert.Equal(t, interleaveWaves(outs...), out)
+}
+
+func TestSampleFormat(t *testing.T) {
+	wave := []int16{0, 1, -1, 12345, -12345, 32767, -32768}
+	for _, f := range []goresampler.SampleFormat{goresampler.SampleFormatS16LE, goresampler.SampleFormatS16BE, goresampler.SampleFormatF32LE} {
+		buf := make([]byte, len(wave)*f.Size())
+		assert.NoError(t, f.Encode(buf, wave), f.String())
+		got := make([]int16, len(wave))
+		assert.NoError(t, f.Decode(got, buf), f.String())
+		assert.Equal(t, wave, got, f.String())
+	}
+
+	buf := make([]byte, 4)
+	assert.NoError(t, goresampler.SampleFormatS16BE.Encode(buf, []int16{0x0102, -2}))
+	assert.Equal(t, []byte{1, 2, 0xff, 0xfe}, buf)
+	assert.ErrorIs(t, goresampler.SampleFormatS16LE.Decode(make([]int16, 1), make([]byte, 3)), goresampler.ErrIncorrectInLen)
+	assert.ErrorIs(t, goresampler.SampleFormat(42).Decode(nil, nil), goresampler.ErrUnexpSampleFormat)
+}
+
+func TestSampleFormat_G711(t *testing.T) {
+	for _, tt := range []struct {
+		f          goresampler.SampleFormat
+		zero       byte
+		maxRelDiff float64
+	}{
+		{goresampler.SampleFormatMuLaw, 0xFF, 1.0 / 16},
+		{goresampler.SampleFormatALaw, 0xD5, 1.0 / 16},
+	} {
+		assert.Equal(t, 1, tt.f.Size())
+		all := make([]byte, 256)
+		for i := range all {
+			all[i] = byte(i)
+		}
+		decoded, encoded := make([]int16, 256), make([]byte, 256)
+		assert.NoError(t, tt.f.Decode(decoded, all))
+		assert.NoError(t, tt.f.Encode(encoded, decoded))
+		again := make([]int16, 256)
+		assert.NoError(t, tt.f.Decode(again, encoded))
+		assert.Equal(t, decoded, again, "decoded values must be coded exactly %s", tt.f)
+
+		zero := make([]byte, 1)
+		assert.NoError(t, tt.f.Encode(zero, []int16{0}))
+		assert.Equal(t, tt.zero, zero[0], tt.f.String())
+
+		wave := goldenInWave(8000, 8000)
+		enc, got := make([]byte, len(wave)), make([]int16, len(wave))
+		assert.NoError(t, tt.f.Encode(enc, wave))
+		assert.NoError(t, tt.f.Decode(got, enc))
+		for i := range wave {
+			assert.LessOrEqual(t, math.Abs(float64(wave[i])-float64(got[i])), math.Max(16, math.Abs(float64(wave[i]))*tt.maxRelDiff), "%s sample %d", tt.f, i)
+		}
+
+		extremes := make([]byte, 2)
+		assert.NoError(t, tt.f.Encode(extremes, []int16{math.MaxInt16, math.MinInt16}))
+		assert.NoError(t, tt.f.Decode(got[:2], extremes))
+		assert.Greater(t, got[0], int16(30000), tt.f.String())
+		assert.Less(t, got[1], int16(-30000), tt.f.String())
+	}
+}
+
+func TestResamplerAuto_ResampleBytes(t *testing.T) {
+	rsm, info, err := goresampler.New(48000, 8000, goresampler.WithSampleFormat(goresampler.SampleFormatF32LE))
+	if !assert.NoError(t, err) {
+		return
+	}
+	inAmt, outAmt := rsm.CalcInOutSamplesPerOutAmt(800)
+	in := goldenInWave(48000, inAmt)
+	out := make([]int16, outAmt)
+	assert.NoError(t, rsm.Resample(in, out))
+
+	rsm.Reset()
+	inB, outB := make([]byte, inAmt*info.Format.Size()), make([]byte, outAmt*info.Format.Size())
+	assert.NoError(t, info.Format.Encode(inB, in))
+	assert.NoError(t, rsm.ResampleBytes(inB, outB))
+	got := make([]int16, outAmt)
+	assert.NoError(t, info.Format.Decode(got, outB))
+	assert.Equal(t, out, got)
+}
+
+func ExampleNew() {
+	rsm, info, err := goresampler.New(48000, 16000, goresampler.WithChannels(2))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("%s: %d -> %d samples per channel\n", info.Algorithm, info.BatchInAmt, info.BatchOutAmt)
+
+	inAmt, outAmt := rsm.CalcInOutSamplesPerOutAmt(320) // 10ms of stereo 16000 wave
+	in, out := make([]int16, inAmt), make([]int16, outAmt)
+	if err = rsm.Resample(in, out); err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(len(in), len(out))
+	// Output:
+	// Const_expression_resampler: 480 -> 160 samples per channel
+	// 960 320
+}
@@ -0,0 +1,195 @@
+package goresampler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Pipe resamples waves got from in with rsm and sends resampled ones to returned channel by frames of frameSize samples
+//
+// when in is closed - all buffered samples are resampled (ResampleAllInBuf) and sent,
+// so last frame can be shorter than frameSize, after that returned channel is closed
+//
+// returned channel is closed as well when ctx is done or on resample error (like ErrBufferFull from bounded rsm)
+// - returned wait func tells them apart: it blocks till pipe goroutine is done and returns error that stopped it
+// (ctx.Err() if ctx is done, nil if all input is resampled and sent), so read all frames before calling it
+//
+// rsm must not be used by caller after Pipe call - it is owned by pipe goroutine
+//
+// frameSize must be positive (and multiple of channels amt for multichannel rsm), otherwise ErrIncorrectOption is returned
+func Pipe(ctx context.Context, in <-chan []int16, rsm ResampleBatch, frameSize int) (<-chan []int16, func() error, error) {
+	if frameSize <= 0 {
+		return nil, nil, ErrIncorrectOption
+	}
+	if frameSize%rsm.chAmt != 0 {
+		return nil, nil, fmt.Errorf("%w: frame size %d is not multiple of channels amt %d", ErrIncorrectOption, frameSize, rsm.chAmt)
+	}
+	out := make(chan []int16)
+	wait := runPipe(func() error {
+		defer close(out)
+		for {
+			var wave []int16
+			var ok bool
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case wave, ok = <-in:
+			}
+
+			if !ok {
+				if err := rsm.ResampleAllInBuf(); err != nil {
+					return err
+				}
+				frames, err := getFrames(rsm.GetBatch, frameSize)
+				if err != nil {
+					return err
+				}
+				if tail := make([]int16, rsm.Len()); len(tail) != 0 {
+					if err := rsm.GetBatch(tail); err != nil {
+						return err
+					}
+					frames = append(frames, tail)
+				}
+				return sendFrames(ctx, out, frames, nil, nil)
+			}
+
+			if err := rsm.AddBatch(wave); err != nil {
+				return err
+			}
+			frames, err := getFrames(rsm.GetBatch, frameSize)
+			if err != nil {
+				return err
+			}
+			if err := sendFrames(ctx, out, frames, nil, nil); err != nil {
+				return err
+			}
+		}
+	})
+	return out, wait, nil
+}
+
+// Pipe2Waves is Pipe for ResampleBatch2Waves: first wave is sent to first returned channel by frames of frameSize1
+// and second wave - to second one by frames of frameSize2
+//
+// both channels must be read (in any order) - pipe goroutine doesn't go on till frames of both waves are sent
+func Pipe2Waves(ctx context.Context, in <-chan []int16, rsm ResampleBatch2Waves, frameSize1, frameSize2 int) (<-chan []int16, <-chan []int16, func() error, error) {
+	if frameSize1 <= 0 || frameSize2 <= 0 {
+		return nil, nil, nil, ErrIncorrectOption
+	}
+	out1, out2 := make(chan []int16), make(chan []int16)
+	wait := runPipe(func() error {
+		defer close(out1)
+		defer close(out2)
+		for {
+			var wave []int16
+			var ok bool
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case wave, ok = <-in:
+			}
+
+			if !ok {
+				if err := rsm.ResampleAllInBuf(); err != nil {
+					return err
+				}
+				frames1, err := getFrames(rsm.GetBatchFirstWave, frameSize1)
+				if err != nil {
+					return err
+				}
+				frames2, err := getFrames(rsm.GetBatchSecondWave, frameSize2)
+				if err != nil {
+					return err
+				}
+				len1, len2 := rsm.Len()
+				if tail := make([]int16, len1); len1 != 0 {
+					if err := rsm.GetBatchFirstWave(tail); err != nil {
+						return err
+					}
+					frames1 = append(frames1, tail)
+				}
+				if tail := make([]int16, len2); len2 != 0 {
+					if err := rsm.GetBatchSecondWave(tail); err != nil {
+						return err
+					}
+					frames2 = append(frames2, tail)
+				}
+				return sendFrames(ctx, out1, frames1, out2, frames2)
+			}
+
+			if err := rsm.AddBatch(wave); err != nil {
+				return err
+			}
+			frames1, err := getFrames(rsm.GetBatchFirstWave, frameSize1)
+			if err != nil {
+				return err
+			}
+			frames2, err := getFrames(rsm.GetBatchSecondWave, frameSize2)
+			if err != nil {
+				return err
+			}
+			if err := sendFrames(ctx, out1, frames1, out2, frames2); err != nil {
+				return err
+			}
+		}
+	})
+	return out1, out2, wait, nil
+}
+
+// runPipe runs pipe in new goroutine and returns func that waits for it and returns its error
+func runPipe(pipe func() error) func() error {
+	done := make(chan struct{})
+	var err error
+	go func() {
+		defer close(done)
+		err = pipe()
+	}()
+	return func() error {
+		<-done
+		return err
+	}
+}
+
+// getFrames gets frames of frameSize while there are enough samples
+func getFrames(getBatch func([]int16) error, frameSize int) ([][]int16, error) {
+	var frames [][]int16
+	for {
+		frame := make([]int16, frameSize)
+		err := getBatch(frame)
+		if errors.Is(err, ErrNotEnoughSamples) {
+			return frames, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		frames = append(frames, frame)
+	}
+}
+
+// sendFrames sends frames1 to out1 and frames2 to out2 (out2 can be nil if frames2 is empty)
+// in order of channels readiness
+//
+// returns ctx.Err() if ctx is done before all frames are sent
+func sendFrames(ctx context.Context, out1 chan<- []int16, frames1 [][]int16, out2 chan<- []int16, frames2 [][]int16) error {
+	for len(frames1) != 0 || len(frames2) != 0 {
+		var c1, c2 chan<- []int16 // nil channels are never ready
+		var f1, f2 []int16
+		if len(frames1) != 0 {
+			c1, f1 = out1, frames1[0]
+		}
+		if len(frames2) != 0 {
+			c2, f2 = out2, frames2[0]
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case c1 <- f1:
+			frames1 = frames1[1:]
+		case c2 <- f2:
+			frames2 = frames2[1:]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package goresampler_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	goresampler "github.com/lehatrutenb/goresampler"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sendByChunks(in []int16, chunkSize int) <-chan []int16 {
+	ch := make(chan []int16)
+	go func() {
+		defer close(ch)
+		for l := 0; l < len(in); l += chunkSize {
+			ch <- in[l:min(l+chunkSize, len(in))]
+		}
+	}()
+	return ch
+}
+
+// batchResampleAll returns all samples that batch gives for in (by batches of frameSize and then tail)
+func batchResampleAll(t *testing.T, rsm goresampler.ResampleBatch, in []int16, frameSize int) []int16 {
+	assert.NoError(t, rsm.AddBatch(in))
+	var out []int16
+	frame := make([]int16, frameSize)
+	for rsm.GetBatch(frame) == nil {
+		out = append(out, frame...)
+	}
+	assert.NoError(t, rsm.ResampleAllInBuf())
+	frame = make([]int16, rsm.Len())
+	assert.NoError(t, rsm.GetBatch(frame))
+	return append(out, frame...)
+}
+
+func TestPipe(t *testing.T) {
+	for _, inRate := range []int{11025, 16000, 44100, 48000} {
+		rsmIns, _, err := goresampler.New(inRate, 8000, goresampler.WithStrictRates(false))
+		if !assert.NoError(t, err) {
+			continue
+		}
+		in := goldenInWave(inRate, inRate+123)
+		want := batchResampleAll(t, goresampler.NewResampleBatch(rsmIns, inRate, 8000), in, 160)
+		rsmIns.Reset()
+
+		var got []int16
+		framesAmt := 0
+		out, wait, err := goresampler.Pipe(context.Background(), sendByChunks(in, inRate/100), goresampler.NewResampleBatch(rsmIns, inRate, 8000), 160)
+		if !assert.NoError(t, err) {
+			continue
+		}
+		for frame := range out {
+			if len(frame) != 160 {
+				assert.Less(t, len(frame), 160)
+				assert.Len(t, got, len(want)-len(frame), "only last frame can be short")
+			}
+			got = append(got, frame...)
+			framesAmt++
+		}
+		assert.NoError(t, wait())
+		assert.Equal(t, want, got, "%d -> 8000", inRate)
+		assert.Equal(t, (len(want)+159)/160, framesAmt)
+	}
+}
+
+func TestPipe_Cancel(t *testing.T) {
+	rsmIns, _, _ := goresampler.New(48000, 16000)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	in := make(chan []int16)
+	out, wait, err := goresampler.Pipe(ctx, in, goresampler.NewResampleBatch(rsmIns, 48000, 16000), 160)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	in <- make([]int16, 960) // 2 frames
+	<-out
+	cancel()
+	for range out { // must be closed even if in is not
+	}
+	assert.ErrorIs(t, wait(), context.Canceled)
+}
+
+// consumer must be able to tell stream stopped by error from finished one
+func TestPipe_Error(t *testing.T) {
+	rsmIns, _, _ := goresampler.New(48000, 16000)
+	_, _, err := goresampler.Pipe(context.Background(), nil, goresampler.NewResampleBatch(rsmIns, 48000, 16000), 0)
+	assert.ErrorIs(t, err, goresampler.ErrIncorrectOption)
+	rsmStereo, _, _ := goresampler.New(48000, 16000, goresampler.WithChannels(2))
+	_, _, err = goresampler.Pipe(context.Background(), nil, goresampler.NewResampleBatch(rsmStereo, 48000, 16000), 321)
+	assert.ErrorIs(t, err, goresampler.ErrIncorrectOption, "frame must have samples of every channel")
+	rsmIns2, _ := goresampler.NewResamplerSpline2Waves(48000, 16000, 8000, nil)
+	_, _, _, err = goresampler.Pipe2Waves(context.Background(), nil, goresampler.NewResampleBatch2Waves(rsmIns2, 48000, 16000, 8000), 320, -1)
+	assert.ErrorIs(t, err, goresampler.ErrIncorrectOption)
+
+	bounded, err := goresampler.NewResampleBatchBounded(rsmIns, 48000, 16000, 960, 0)
+	if !assert.NoError(t, err) {
+		return
+	}
+	in := make(chan []int16, 2)
+	in <- make([]int16, 480)
+	in <- make([]int16, 1000) // doesn't fit in buffer
+	close(in)
+	out, wait, err := goresampler.Pipe(context.Background(), in, bounded, 160)
+	if !assert.NoError(t, err) {
+		return
+	}
+	framesAmt := 0
+	for range out {
+		framesAmt++
+	}
+	assert.Equal(t, 1, framesAmt)
+	assert.ErrorIs(t, wait(), goresampler.ErrBufferFull)
+
+	in2 := make(chan []int16, 1)
+	in2 <- make([]int16, 1000)
+	bounded2, _ := goresampler.NewResampleBatch2WavesBounded(rsmIns2, 48000, 16000, 8000, 960, 0, 0)
+	out1, out2, wait, err := goresampler.Pipe2Waves(context.Background(), in2, bounded2, 320, 160)
+	if !assert.NoError(t, err) {
+		return
+	}
+	for range out1 {
+	}
+	for range out2 {
+	}
+	assert.ErrorIs(t, wait(), goresampler.ErrBufferFull)
+}
+
+func TestPipe2Waves(t *testing.T) {
+	rsmIns, _ := goresampler.NewResamplerSpline2Waves(44100, 16000, 8000, nil)
+	in := goldenInWave(44100, 44100)
+	batch := goresampler.NewResampleBatch2Waves(rsmIns, 44100, 16000, 8000)
+	assert.NoError(t, batch.AddBatch(in))
+	var want1, want2 []int16
+	for frame := make([]int16, 320); batch.GetBatchFirstWave(frame) == nil; {
+		want1 = append(want1, frame...)
+	}
+	for frame := make([]int16, 160); batch.GetBatchSecondWave(frame) == nil; {
+		want2 = append(want2, frame...)
+	}
+	assert.NoError(t, batch.ResampleAllInBuf())
+	len1, len2 := batch.Len()
+	tail1, tail2 := make([]int16, len1), make([]int16, len2)
+	assert.NoError(t, batch.GetBatchFirstWave(tail1))
+	assert.NoError(t, batch.GetBatchSecondWave(tail2))
+	want1, want2 = append(want1, tail1...), append(want2, tail2...)
+
+	rsmIns.Reset()
+	out1, out2, wait, err := goresampler.Pipe2Waves(context.Background(), sendByChunks(in, 441), goresampler.NewResampleBatch2Waves(rsmIns, 44100, 16000, 8000), 320, 160)
+	if !assert.NoError(t, err) {
+		return
+	}
+	var got1, got2 []int16
+	for out1 != nil || out2 != nil {
+		select {
+		case frame, ok := <-out1:
+			if !ok {
+				out1 = nil
+			}
+			got1 = append(got1, frame...)
+		case frame, ok := <-out2:
+			if !ok {
+				out2 = nil
+			}
+			got2 = append(got2, frame...)
+		}
+	}
+	assert.NoError(t, wait())
+	assert.Equal(t, want1, got1)
+	assert.Equal(t, want2, got2)
+}
+
+func ExamplePipe() {
+	rsmIns, _, _ := goresampler.New(48000, 16000)
+	in := make(chan []int16)
+	go func() { // capture goroutine
+		for i := 0; i < 5; i++ {
+			in <- make([]int16, 480) // 10ms of 48000 wave
+		}
+		close(in)
+	}()
+
+	out, wait, _ := goresampler.Pipe(context.Background(), in, goresampler.NewResampleBatch(rsmIns, 48000, 16000), 320) // 20ms frames
+	for frame := range out {
+		fmt.Println(len(frame))
+	}
+	if err := wait(); err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// 320
+	// 320
+	// 160
+}
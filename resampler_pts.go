@@ -0,0 +1,149 @@
+package goresampler
+
+import (
+	"time"
+)
+
+// latencyOf returns group delay of rsm in out samples (0 for resamplers without delay like spline or fft)
+//
+// delays of constexpr resamplers are measured by phase shift of low frequency sin waves (100-1000Hz)
+func latencyOf(rsm Resampler) float64 {
+	if rsmAuto, ok := rsm.(ResamplerAuto); ok {
+		rsm = rsmAuto.Resampler
+	}
+	if rsmCh, ok := rsm.(resamplerChannels); ok {
+		rsm = rsmCh.rsms[0]
+	}
+	switch rsm.(type) {
+	case Resampler16To8L:
+		return 1.0
+	case Resampler8To16L:
+		return 3.0
+	case Resampler48To8L:
+		return 2.8
+	case Resampler48To16L:
+		return 3.59
+	case Resampler11To8L:
+		return 3.91
+	case Resampler11To16L:
+		return 6.1
+	case Resampler44To8L:
+		return 2.96
+	case Resampler44To16L:
+		return 3.92
+	}
+	return 0
+}
+
+// ptsAnchor binds input sample to its presentation timestamp
+type ptsAnchor struct {
+	inPos int64 // ind of input sample (per channel) from stream start
+	pts   time.Duration
+}
+
+// ptsSegment binds out sample to input position it represents - new one starts on every resample call,
+// so timing error of spline and fft batches (their in/out amts are not exactly of rates ratio) doesn't add up
+type ptsSegment struct {
+	outPos int64   // ind of out sample (per channel) from stream start
+	inPos  float64 // input position (per channel) out sample represents
+}
+
+// ptsTracker maps out samples of ResampleBatch to presentation timestamps of input ones
+//
+// all amts it gets are in samples of all channels
+type ptsTracker struct {
+	inRate, outRate, chAmt int
+	latency                float64 // in out samples
+	anchors                []ptsAnchor
+	segments               []ptsSegment
+	inAdded, inUsed        int64
+	outMade, outGot        int64
+}
+
+func newPtsTracker(inRate, outRate, chAmt int, latency float64) ptsTracker {
+	pt := ptsTracker{inRate: inRate, outRate: outRate, chAmt: chAmt, latency: latency}
+	pt.reset()
+	return pt
+}
+
+// add registers added input, if withPts - its first sample is presented at pts
+func (pt *ptsTracker) add(amt int, pts time.Duration, withPts bool) {
+	if withPts || pt.inAdded == 0 { // stream without pts starts at 0
+		if !withPts {
+			pts = 0
+		}
+		inPos := pt.inAdded / int64(pt.chAmt)
+		if l := len(pt.anchors); l != 0 && pt.anchors[l-1].inPos == inPos { // previous one had empty input
+			pt.anchors = pt.anchors[:l-1]
+		}
+		pt.anchors = append(pt.anchors, ptsAnchor{inPos, pts})
+	}
+	pt.inAdded += int64(amt)
+}
+
+// resampled registers that inAmt input samples were resampled to outAmt ones by tails (or main) resampler
+//
+// segment is anchored on really used input and made output samples
+func (pt *ptsTracker) resampled(inAmt, outAmt int, tails bool) {
+	if outAmt != 0 {
+		inPos := float64(pt.inUsed / int64(pt.chAmt))
+		if !tails { // main resampler goes with its delay
+			inPos -= pt.latency * float64(pt.inRate) / float64(pt.outRate)
+		}
+		seg := ptsSegment{pt.outMade / int64(pt.chAmt), inPos}
+		if l := len(pt.segments); pt.segments[l-1].outPos == seg.outPos { // previous one has no out samples
+			pt.segments[l-1] = seg
+		} else {
+			pt.segments = append(pt.segments, seg)
+		}
+	}
+	pt.inUsed += int64(inAmt)
+	pt.outMade += int64(outAmt)
+}
+
+// got registers that amt out samples were got and forgets not needed anymore anchors and segments
+// returns pts of first got sample
+func (pt *ptsTracker) got(amt int) time.Duration {
+	pts := pt.nextPts()
+	pt.outGot += int64(amt)
+	outPos := pt.outGot / int64(pt.chAmt)
+	for len(pt.segments) > 1 && pt.segments[1].outPos <= outPos {
+		pt.segments = pt.segments[1:]
+	}
+	inPos := int64(pt.inPosOf(outPos))
+	for len(pt.anchors) > 1 && pt.anchors[1].inPos <= inPos {
+		pt.anchors = pt.anchors[1:]
+	}
+	return pts
+}
+
+// inPosOf returns input position (per channel) out sample outPos represents
+func (pt ptsTracker) inPosOf(outPos int64) float64 {
+	seg := pt.segments[0]
+	for _, s := range pt.segments[1:] {
+		if s.outPos > outPos {
+			break
+		}
+		seg = s
+	}
+	return seg.inPos + float64(outPos-seg.outPos)*float64(pt.inRate)/float64(pt.outRate)
+}
+
+// nextPts returns pts of next out sample to get
+func (pt ptsTracker) nextPts() time.Duration {
+	inPos := pt.inPosOf(pt.outGot / int64(pt.chAmt))
+	anchor := ptsAnchor{0, 0}      // nothing was added yet
+	for i, a := range pt.anchors { // samples before first anchor (delay) are extrapolated from it
+		if i != 0 && float64(a.inPos) > inPos {
+			break
+		}
+		anchor = a
+	}
+	return anchor.pts + time.Duration((inPos-float64(anchor.inPos))*float64(time.Second)/float64(pt.inRate))
+}
+
+func (pt *ptsTracker) reset() {
+	pt.anchors = pt.anchors[:0]
+	pt.segments = append(pt.segments[:0], ptsSegment{0, -pt.latency * float64(pt.inRate) / float64(pt.outRate)})
+	pt.inAdded, pt.inUsed, pt.outMade, pt.outGot = 0, 0, 0, 0
+}
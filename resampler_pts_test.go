@@ -0,0 +1,158 @@
+package goresampler_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	goresampler "github.com/lehatrutenb/goresampler"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sinWaveAt(rate, amt int, freq float64, start time.Duration) []int16 {
+	res := make([]int16, amt)
+	for i := range res {
+		t := start.Seconds() + float64(i)/float64(rate)
+		res[i] = int16(10000 * math.Sin(2*math.Pi*freq*t))
+	}
+	return res
+}
+
+// resampled sin wave must be same as sin wave taken at pts of out samples
+func TestResampleBatch_GetBatchAt_SinWave(t *testing.T) {
+	const freq = 300.0
+	for _, rates := range [][2]int{{16000, 8000}, {8000, 16000}, {48000, 8000}, {48000, 16000}, {11000, 8000}, {11000, 16000}, {44000, 8000},
+		{44000, 16000}, {44100, 16000}, {22050, 8000}} {
+		inRate, outRate := rates[0], rates[1]
+		rsmIns, _, err := goresampler.New(inRate, outRate, goresampler.WithStrictRates(false))
+		if !assert.NoError(t, err) {
+			continue
+		}
+		rsm := goresampler.NewResampleBatch(rsmIns, inRate, outRate)
+		start := time.Hour + 123*time.Millisecond
+		in := sinWaveAt(inRate, inRate, freq, start)
+		frameSize := outRate / 100
+		for l := 0; l < len(in); l += inRate / 100 {
+			chunkStart := start + time.Duration(l)*time.Second/time.Duration(inRate)
+			assert.NoError(t, rsm.AddBatchAt(in[l:min(l+inRate/100, len(in))], chunkStart))
+		}
+
+		for frame := 0; frame < 50; frame++ {
+			out := make([]int16, frameSize)
+			pts, err := rsm.GetBatchAt(out)
+			if !assert.NoError(t, err) {
+				break
+			}
+			if frame < 5 { // skip filter warm up
+				continue
+			}
+			assert.InDelta(t, 0, maxDiff(out, sinWaveAt(outRate, frameSize, freq, pts)), 150, "%d -> %d frame %d", inRate, outRate, frame)
+		}
+	}
+}
+
+func maxDiff(a, b []int16) float64 {
+	res := 0.0
+	for i := range a {
+		res = math.Max(res, math.Abs(float64(a[i])-float64(b[i])))
+	}
+	return res
+}
+
+func TestResampleBatch_GetBatchAt(t *testing.T) {
+	rsmIns, _, _ := goresampler.New(44100, 16000, goresampler.WithStrictRates(false)) // spline - no delay
+	rsm := goresampler.NewResampleBatch(rsmIns, 44100, 16000)
+	out := make([]int16, 160)
+
+	assert.NoError(t, rsm.AddBatch(make([]int16, 4410)))
+	pts, err := rsm.GetBatchAt(out)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), pts, "without AddBatchAt stream starts at 0")
+	pts, _ = rsm.GetBatchAt(out)
+	assert.Equal(t, 10*time.Millisecond, pts)
+
+	assert.NoError(t, rsm.AddBatchAt(make([]int16, 4410), time.Second)) // gap in capture: input from 100ms goes at 1s
+	for i := 2; i < 10; i++ {
+		pts, _ = rsm.GetBatchAt(out)
+		assert.InDelta(t, time.Duration(i)*10*time.Millisecond, pts, float64(time.Microsecond))
+	}
+	pts, _ = rsm.GetBatchAt(out)
+	assert.InDelta(t, time.Second, pts, float64(time.Microsecond))
+
+	assert.NoError(t, rsm.ResampleAllInBuf())
+	for rsm.Len() >= len(out) {
+		pts, _ = rsm.GetBatchAt(out)
+	}
+	assert.InDelta(t, time.Second+90*time.Millisecond, pts, float64(time.Microsecond))
+
+	rsm.Reset()
+	assert.NoError(t, rsm.AddBatchAt(make([]int16, 441), time.Minute))
+	pts, _ = rsm.GetBatchAt(out)
+	assert.Equal(t, time.Minute, pts)
+}
+
+// tails are made from first not resampled input sample - without delay of constexpr resampler
+func TestResampleBatch_GetBatchAt_Tails(t *testing.T) {
+	rsmIns, _, _ := goresampler.New(48000, 16000)
+	rsm := goresampler.NewResampleBatch(rsmIns, 48000, 16000)
+	assert.NoError(t, rsm.AddBatchAt(make([]int16, 1000), time.Second))
+	out := make([]int16, 320)
+	pts, err := rsm.GetBatchAt(out) // 960 in resampled
+	assert.NoError(t, err)
+	assert.Less(t, pts, time.Second, "constexpr resampler has delay")
+
+	assert.NoError(t, rsm.ResampleAllInBuf())
+	tail := make([]int16, rsm.Len())
+	pts, err = rsm.GetBatchAt(tail)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Second+20*time.Millisecond, pts)
+}
+
+// spline and fft batches have not exact in/out ratio - pts must follow really used input on long streams
+func TestResampleBatch_GetBatchAt_NoDrift(t *testing.T) {
+	const freq = 100.0
+	for _, tt := range []struct {
+		inRate, outRate int
+		rsmT            goresampler.ResamplerT
+	}{{44100, 7999, goresampler.ResamplerSplineT}, {48000, 44101, goresampler.ResamplerSplineT}, {44100, 7999, goresampler.ResamplerFFtT}} {
+		rsmIns, _, err := goresampler.New(tt.inRate, tt.outRate, goresampler.WithType(tt.rsmT), goresampler.WithStrictRates(false))
+		if !assert.NoError(t, err) {
+			continue
+		}
+		rsm := goresampler.NewResampleBatch(rsmIns, tt.inRate, tt.outRate)
+		in := sinWaveAt(tt.inRate, 60*tt.inRate, freq, 0)
+		assert.NoError(t, rsm.AddBatch(in))
+		frame, out := make([]int16, tt.outRate/10), []int16(nil)
+		var pts time.Duration
+		for { // last whole frame
+			curPts, err := rsm.GetBatchAt(frame)
+			if err != nil {
+				break
+			}
+			out, pts = append(out[:0], frame...), curPts
+		}
+		// only beginning of frame is compared - out wave itself jumps on batches bounds
+		assert.InDelta(t, 0, maxDiff(out[:10], sinWaveAt(tt.outRate, 10, freq, pts)), 50,
+			"%d -> %d %v at %v", tt.inRate, tt.outRate, tt.rsmT, pts)
+	}
+}
+
+func ExampleResampleBatch_GetBatchAt() {
+	rsmIns, _, _ := goresampler.New(44100, 16000, goresampler.WithStrictRates(false))
+	rsm := goresampler.NewResampleBatch(rsmIns, 44100, 16000)
+
+	_ = rsm.AddBatchAt(make([]int16, 441), 5*time.Second) // capture timestamp of chunk
+	_ = rsm.AddBatchAt(make([]int16, 441), 5*time.Second+10*time.Millisecond)
+
+	out := make([]int16, 80) // 5ms
+	for i := 0; i < 3; i++ {
+		pts, _ := rsm.GetBatchAt(out)
+		fmt.Println(pts)
+	}
+	// Output:
+	// 5s
+	// 5.005s
+	// 5.01s
+}
@@ -0,0 +1,168 @@
+package goresampler
+
+import (
+	"errors"
+	"slices"
+)
+
+var (
+	// ErrBufferFull indicates that bounded batch buffer can't fit more samples
+	// get (or resample) already buffered ones first
+	ErrBufferFull = errors.New("batch buffer is full")
+)
+
+const minRingBufferSize = 1 << 10
+
+// ringBuffer is fifo of samples over fixed array
+//
+// if maxSize is 0 - array grows (x2) when it's not enough, but never shrinks
+// so memory is bounded by max amt of samples stored at once, not by amt of samples passed through
+type ringBuffer struct {
+	buf     []int16
+	start   int // ind of first sample in buf
+	size    int // amt of stored samples
+	maxSize int
+}
+
+func newRingBuffer(maxSize int) ringBuffer {
+	return ringBuffer{buf: make([]int16, maxSize), maxSize: maxSize}
+}
+
+func (rb *ringBuffer) Len() int {
+	return rb.size
+}
+
+// grow makes buf fit n more samples or returns ErrBufferFull
+func (rb *ringBuffer) grow(n int) error {
+	if rb.size+n <= len(rb.buf) {
+		return nil
+	}
+	if rb.maxSize != 0 && rb.size+n > rb.maxSize {
+		return ErrBufferFull
+	}
+	size := max(2*len(rb.buf), rb.size+n, minRingBufferSize)
+	if rb.maxSize != 0 { // array of bounded rb is dropped by detach
+		size = rb.maxSize
+	}
+	nBuf := make([]int16, size)
+	rb.copyTo(nBuf[:rb.size])
+	rb.buf, rb.start = nBuf, 0
+	return nil
+}
+
+// fits returns true if rb can store size samples at once
+func (rb *ringBuffer) fits(size int) bool {
+	return rb.maxSize == 0 || size <= rb.maxSize
+}
+
+// write appends all in or nothing (if it doesn't fit)
+func (rb *ringBuffer) write(in []int16) error {
+	if len(in) == 0 {
+		return nil
+	}
+	if err := rb.grow(len(in)); err != nil {
+		return err
+	}
+	end := (rb.start + rb.size) % len(rb.buf)
+	n := copy(rb.buf[end:], in)
+	copy(rb.buf, in[n:])
+	rb.size += len(in)
+	return nil
+}
+
+// copyTo copies first len(out) samples to out not removing them
+func (rb *ringBuffer) copyTo(out []int16) {
+	n := copy(out, rb.buf[rb.start:]) // copy stops at len(out)
+	copy(out[n:], rb.buf)
+}
+
+// discard removes first n samples
+func (rb *ringBuffer) discard(n int) {
+	rb.size -= n
+	if rb.size == 0 {
+		rb.start = 0 // to need less linearize calls
+		return
+	}
+	rb.start = (rb.start + n) % len(rb.buf)
+}
+
+// read moves first len(out) samples to out
+func (rb *ringBuffer) read(out []int16) {
+	rb.copyTo(out)
+	rb.discard(len(out))
+}
+
+// linearize moves samples to beginning of buf so samples and free space after them are contiguous
+func (rb *ringBuffer) linearize() {
+	if rb.start == 0 {
+		return
+	}
+	if rb.start+rb.size <= len(rb.buf) {
+		copy(rb.buf, rb.buf[rb.start:rb.start+rb.size])
+	} else { // rotate left by start without extra memory
+		slices.Reverse(rb.buf[:rb.start])
+		slices.Reverse(rb.buf[rb.start:])
+		slices.Reverse(rb.buf)
+	}
+	rb.start = 0
+}
+
+// view returns contiguous first n samples
+//
+// it is valid only until next change of rb (its memory is reused)
+func (rb *ringBuffer) view(n int) []int16 {
+	if rb.start+n > len(rb.buf) {
+		rb.linearize()
+	}
+	return rb.buf[rb.start : rb.start+n]
+}
+
+// detach removes first n samples and returns them as memory rb never touches again
+//
+// smaller of samples and rest of rb is copied: samples to new array or rest to new array (bounded rb keeps its size,
+// not bounded one gets array just fitting rest) ; if nothing is left rb has no array till grow makes it
+func (rb *ringBuffer) detach(n int) []int16 {
+	if n < rb.size-n {
+		res := make([]int16, n)
+		rb.read(res)
+		return res
+	}
+	res := rb.view(n)
+	rb.discard(n)
+	var nBuf []int16
+	switch {
+	case rb.size == 0:
+	case rb.maxSize != 0:
+		nBuf = make([]int16, rb.maxSize)
+	default:
+		nBuf = make([]int16, max(rb.size, minRingBufferSize))
+	}
+	rb.copyTo(nBuf[:rb.size])
+	rb.buf, rb.start = nBuf, 0
+	return res[:n:n]
+}
+
+// reserve returns contiguous free space for n samples right after stored ones
+// they are added to rb only after commit(n)
+func (rb *ringBuffer) reserve(n int) ([]int16, error) {
+	if n == 0 {
+		return nil, nil
+	}
+	if err := rb.grow(n); err != nil {
+		return nil, err
+	}
+	end := (rb.start + rb.size) % len(rb.buf)
+	if end+n > len(rb.buf) {
+		rb.linearize()
+		end = rb.size
+	}
+	return rb.buf[end : end+n], nil
+}
+
+func (rb *ringBuffer) commit(n int) {
+	rb.size += n
+}
+
+func (rb *ringBuffer) reset() {
+	rb.start, rb.size = 0, 0
+}
@@ -0,0 +1,197 @@
+package goresampler_test
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"testing"
+
+	goresampler "github.com/lehatrutenb/goresampler"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// bounded batch must give same output as unbounded one when buffers are enough
+func TestResampleBatchBounded_SameAsUnbounded(t *testing.T) {
+	for _, inRate := range []int{11025, 16000, 44100, 48000} {
+		rsmIns1, _, err := goresampler.New(inRate, 8000, goresampler.WithStrictRates(false))
+		if !assert.NoError(t, err) {
+			continue
+		}
+		rsmIns2, _, _ := goresampler.New(inRate, 8000, goresampler.WithStrictRates(false))
+		rsm := goresampler.NewResampleBatch(rsmIns1, inRate, 8000)
+		rsmB, err := goresampler.NewResampleBatchBounded(rsmIns2, inRate, 8000, 4000, 2000)
+		if !assert.NoError(t, err) {
+			continue
+		}
+
+		in := goldenInWave(inRate, 3*inRate)
+		var out, outB []int16
+		batch := make([]int16, 77)
+		for l := 0; l < len(in); l += 1013 {
+			r := min(l+1013, len(in))
+			assert.NoError(t, rsm.AddBatch(in[l:r]))
+			assert.NoError(t, rsmB.AddBatch(in[l:r]))
+			for rsm.GetBatch(batch) == nil {
+				out = append(out, batch...)
+			}
+			for rsmB.GetBatch(batch) == nil {
+				outB = append(outB, batch...)
+			}
+		}
+		assert.NoError(t, rsm.ResampleAllInBuf())
+		assert.NoError(t, rsmB.ResampleAllInBuf())
+		batch = batch[:rsm.Len()]
+		assert.NoError(t, rsm.GetBatch(batch))
+		out = append(out, batch...)
+		batch = batch[:rsmB.Len()]
+		assert.NoError(t, rsmB.GetBatch(batch))
+		outB = append(outB, batch...)
+
+		assert.Equal(t, out, outB, "%d -> 8000", inRate)
+	}
+}
+
+func TestResampleBatchBounded_BufferFull(t *testing.T) {
+	rsmIns, _, _ := goresampler.New(48000, 16000)
+	rsm, err := goresampler.NewResampleBatchBounded(rsmIns, 48000, 16000, 960, 160)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.NoError(t, rsm.AddBatch(make([]int16, 900)))
+	assert.ErrorIs(t, rsm.AddBatch(make([]int16, 61)), goresampler.ErrBufferFull)
+	unresampled, _ := rsm.UnresampledUngetInAmt()
+	assert.Equal(t, 900, unresampled) // nothing added on err
+
+	out := make([]int16, 160)
+	assert.NoError(t, rsm.GetBatch(out)) // frees place
+	assert.NoError(t, rsm.AddBatch(make([]int16, 480)))
+
+	assert.NoError(t, rsm.GetBatch(out[:1])) // 159 resampled left in out buffer
+	assert.NoError(t, rsm.AddBatch(make([]int16, 60)))
+	assert.ErrorIs(t, rsm.GetBatch(out), goresampler.ErrBufferFull) // 160 more don't fit
+	assert.NoError(t, rsm.GetBatch(out[:159]))
+	unresampled, unget := rsm.UnresampledUngetInAmt()
+	assert.Equal(t, []int{480, 0}, []int{unresampled, unget})
+
+	rsm.Reset()
+	assert.NoError(t, rsm.AddBatch(make([]int16, 960)))
+}
+
+func TestResampleBatch2WavesBounded_BufferFull(t *testing.T) {
+	rsmIns, _ := goresampler.NewResamplerSpline2Waves(48000, 16000, 8000, nil)
+	rsm, err := goresampler.NewResampleBatch2WavesBounded(rsmIns, 48000, 16000, 8000, 960, 320, 80)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.NoError(t, rsm.AddBatch(make([]int16, 960)))
+	assert.ErrorIs(t, rsm.AddBatch(make([]int16, 1)), goresampler.ErrBufferFull)
+	assert.ErrorIs(t, rsm.GetBatchFirstWave(make([]int16, 320)), goresampler.ErrBufferFull) // no place for 160 of second wave
+	assert.NoError(t, rsm.GetBatchFirstWave(make([]int16, 160)))
+	assert.NoError(t, rsm.GetBatchSecondWave(make([]int16, 80)))
+	unresampled, unget1, unget2 := rsm.UnresampledUngetInAmt()
+	assert.Equal(t, []int{480, 0, 0}, []int{unresampled, unget1, unget2})
+}
+
+// bounds less than min batch of resampler would make stream never move
+func TestResampleBatchBounded_TooSmall(t *testing.T) {
+	rsmIns, _, _ := goresampler.New(44100, 16000, goresampler.WithType(goresampler.ResamplerFFtT), goresampler.WithChannels(2))
+	minIn, minOut := rsmIns.CalcInOutSamplesPerOutAmt(1)
+	_, err := goresampler.NewResampleBatchBounded(rsmIns, 44100, 16000, minIn-1, 0)
+	assert.ErrorIs(t, err, goresampler.ErrIncorrectOption)
+	_, err = goresampler.NewResampleBatchBounded(rsmIns, 44100, 16000, 0, minOut-1)
+	assert.ErrorIs(t, err, goresampler.ErrIncorrectOption)
+	_, err = goresampler.NewResampleBatchBounded(rsmIns, 44100, 16000, -1, 0)
+	assert.ErrorIs(t, err, goresampler.ErrIncorrectOption)
+
+	rsm, err := goresampler.NewResampleBatchBounded(rsmIns, 44100, 16000, minIn, minOut)
+	if assert.NoError(t, err) {
+		assert.NoError(t, rsm.AddBatch(make([]int16, minIn)))
+		assert.NoError(t, rsm.GetBatch(make([]int16, 2)))
+	}
+
+	rsmIns2, _ := goresampler.NewResamplerSpline2Waves(48000, 16000, 8000, nil)
+	_, err = goresampler.NewResampleBatch2WavesBounded(rsmIns2, 48000, 16000, 8000, 960, 320, 0)
+	assert.NoError(t, err)
+	_, err = goresampler.NewResampleBatch2WavesBounded(rsmIns2, 48000, 16000, 8000, 1, 320, 80)
+	assert.ErrorIs(t, err, goresampler.ErrIncorrectOption)
+}
+
+// GetLargeBatch gives its memory to caller - later calls must not change it
+func TestResampleBatch_GetLargeBatchKept(t *testing.T) {
+	rsmIns, _, _ := goresampler.New(48000, 16000)
+	rsm, err := goresampler.NewResampleBatchBounded(rsmIns, 48000, 16000, 960, 320)
+	if !assert.NoError(t, err) {
+		return
+	}
+	in := goldenInWave(48000, 9600)
+	kept := make([][]int16, 0)
+	copies := make([][]int16, 0)
+	for l := 0; l < len(in); l += 480 {
+		assert.NoError(t, rsm.AddBatch(in[l:l+480]))
+		out := make([]int16, 100)
+		assert.NoError(t, rsm.GetLargeBatch(&out))
+		kept = append(kept, out)
+		copies = append(copies, append([]int16(nil), out...))
+		assert.NoError(t, rsm.GetBatch(make([]int16, 60)))
+	}
+	assert.Equal(t, copies, kept)
+
+	rsmIns2, _ := goresampler.NewResamplerSpline2Waves(48000, 16000, 8000, nil)
+	rsm2 := goresampler.NewResampleBatch2Waves(rsmIns2, 48000, 16000, 8000)
+	kept, copies = kept[:0], copies[:0]
+	for l := 0; l < len(in); l += 480 {
+		assert.NoError(t, rsm2.AddBatch(in[l:l+480]))
+		out1, out2 := make([]int16, 100), make([]int16, 50)
+		assert.NoError(t, rsm2.GetLargeBatchFirstWave(&out1))
+		assert.NoError(t, rsm2.GetLargeBatchSecondWave(&out2))
+		kept = append(kept, out1, out2)
+		copies = append(copies, append([]int16(nil), out1...), append([]int16(nil), out2...))
+		assert.NoError(t, rsm2.GetBatchFirstWave(make([]int16, 60)))
+		assert.NoError(t, rsm2.GetBatchSecondWave(make([]int16, 30)))
+	}
+	assert.Equal(t, copies, kept)
+}
+
+func ExampleNewResampleBatchBounded() {
+	rsmIns, _, _ := goresampler.New(48000, 16000)
+	rsm, _ := goresampler.NewResampleBatchBounded(rsmIns, 48000, 16000, 960, 0) // at most 20ms of input is buffered
+
+	in := make([]int16, 480)
+	out := make([]int16, 160)
+	for i := 0; i < 3; i++ {
+		if err := rsm.AddBatch(in); errors.Is(err, goresampler.ErrBufferFull) {
+			fmt.Println("buffer is full - get resampled first")
+			_ = rsm.GetBatch(out)
+			_ = rsm.AddBatch(in)
+		}
+	}
+	unresampled, _ := rsm.UnresampledUngetInAmt()
+	fmt.Println(unresampled)
+	// Output:
+	// buffer is full - get resampled first
+	// 960
+}
+
+// out buffer that grew once must not be reallocated whole by every GetLargeBatch
+func TestResampleBatch_GetLargeBatchAllocs(t *testing.T) {
+	rsmIns, _, _ := goresampler.New(48000, 16000)
+	rsm := goresampler.NewResampleBatch(rsmIns, 48000, 16000)
+	assert.NoError(t, rsm.AddBatch(make([]int16, 48000*10)))
+	all := make([]int16, 16000*9) // grows out buffer
+	assert.NoError(t, rsm.GetLargeBatch(&all))
+
+	in := make([]int16, 480)
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	const runs = 100
+	for range runs {
+		assert.NoError(t, rsm.AddBatch(in))
+		out := make([]int16, 100)
+		assert.NoError(t, rsm.GetLargeBatch(&out))
+	}
+	runtime.ReadMemStats(&after)
+	assert.Less(t, (after.TotalAlloc-before.TotalAlloc)/runs, uint64(len(all)), "bytes per run")
+}
@@ -0,0 +1,370 @@
+package goresampler
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+var (
+	// ErrIncorrectSnapshot indicates that data given to UnmarshalBinary is not snapshot
+	// of resampler configured same way as one it is restored to
+	ErrIncorrectSnapshot = errors.New("snapshot doesn't match resampler")
+)
+
+const (
+	snapshotMagic   = "GRSB"
+	snapshotVersion = 2
+)
+
+// snapshotWriter appends values to snapshot
+type snapshotWriter struct {
+	buf []byte
+}
+
+func (w *snapshotWriter) int(x int64) {
+	w.buf = binary.AppendVarint(w.buf, x)
+}
+
+func (w *snapshotWriter) float(x float64) {
+	w.buf = binary.LittleEndian.AppendUint64(w.buf, math.Float64bits(x))
+}
+
+func (w *snapshotWriter) str(s string) {
+	w.int(int64(len(s)))
+	w.buf = append(w.buf, s...)
+}
+
+func (w *snapshotWriter) int16s(xs []int16) {
+	w.int(int64(len(xs)))
+	for _, x := range xs {
+		w.buf = binary.LittleEndian.AppendUint16(w.buf, uint16(x))
+	}
+}
+
+func (w *snapshotWriter) int32s(xs []int32) {
+	w.int(int64(len(xs)))
+	for _, x := range xs {
+		w.buf = binary.LittleEndian.AppendUint32(w.buf, uint32(x))
+	}
+}
+
+// snapshotReader reads values from snapshot - after first error all reads return zero values and err is kept
+//
+// if dry - it only checks snapshot and doesn't change state of resamplers
+type snapshotReader struct {
+	data []byte
+	err  error
+	dry  bool
+}
+
+func (r *snapshotReader) fail(format string, args ...any) {
+	if r.err == nil {
+		r.err = fmt.Errorf("%w: "+format, append([]any{ErrIncorrectSnapshot}, args...)...)
+	}
+}
+
+func (r *snapshotReader) int() int64 {
+	if r.err != nil {
+		return 0
+	}
+	x, n := binary.Varint(r.data)
+	if n <= 0 {
+		r.fail("broken int")
+		return 0
+	}
+	r.data = r.data[n:]
+	return x
+}
+
+func (r *snapshotReader) bytes(n int64) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > int64(len(r.data)) {
+		r.fail("unexpected end of data")
+		return nil
+	}
+	res := r.data[:n]
+	r.data = r.data[n:]
+	return res
+}
+
+func (r *snapshotReader) float() float64 {
+	b := r.bytes(8)
+	if b == nil {
+		return 0
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(b))
+}
+
+func (r *snapshotReader) str() string {
+	return string(r.bytes(r.int()))
+}
+
+// sliceLen reads len of slice of elements of elSize bytes
+func (r *snapshotReader) sliceLen(elSize int64) int64 {
+	n := r.int()
+	if n < 0 || n > int64(len(r.data))/elSize {
+		r.fail("unexpected end of data")
+		return 0
+	}
+	return n
+}
+
+func (r *snapshotReader) int16s() []int16 {
+	res := make([]int16, r.sliceLen(2))
+	b := r.bytes(int64(len(res)) * 2)
+	for i := range res {
+		res[i] = int16(binary.LittleEndian.Uint16(b[i*2:]))
+	}
+	return res
+}
+
+// int32sTo reads int32 slice to dst - its len must be same as len(dst)
+func (r *snapshotReader) int32sTo(dst []int32) {
+	n := r.sliceLen(4)
+	if n != int64(len(dst)) {
+		r.fail("got state of len %d, want %d", n, len(dst))
+		return
+	}
+	b := r.bytes(n * 4)
+	if r.err != nil || r.dry {
+		return
+	}
+	for i := range dst {
+		dst[i] = int32(binary.LittleEndian.Uint32(b[i*4:]))
+	}
+}
+
+// expect reads int and checks that it equals want
+func (r *snapshotReader) expect(name string, want int64) {
+	if got := r.int(); r.err == nil && got != want {
+		r.fail("got %s %d, want %d", name, got, want)
+	}
+}
+
+// stateOf returns slices with state that constexpr resampler keeps between Resample calls (nil for others)
+func stateOf(rsm Resampler) [][]int32 {
+	switch rsm := rsm.(type) {
+	case Resampler16To8L:
+		return [][]int32{rsm.st1}
+	case Resampler8To16L:
+		return [][]int32{rsm.st1}
+	case Resampler48To8L:
+		return [][]int32{rsm.st1.S_48_48, rsm.st1.S_48_32, rsm.st1.S_32_16, rsm.st2}
+	case Resampler48To16L:
+		return [][]int32{rsm.st1.S_48_48, rsm.st1.S_48_32, rsm.st1.S_32_16}
+	case Resampler11To8L:
+		return [][]int32{rsm.st1.S_22_44, rsm.st1.S_44_32, rsm.st1.S_32_16}
+	case Resampler11To16L:
+		return [][]int32{rsm.st1, rsm.st2.S_22_44, rsm.st2.S_44_32, rsm.st2.S_32_16}
+	case Resampler44To8L:
+		return [][]int32{rsm.st1.S_22_22, rsm.st1.S_22_16, rsm.st1.S_16_8, rsm.st2}
+	case Resampler44To16L:
+		return [][]int32{rsm.st1.S_22_22, rsm.st1.S_22_16, rsm.st1.S_16_8}
+	}
+	return nil
+}
+
+// writeResampler writes type, config and state of rsm
+func writeResampler(w *snapshotWriter, rsm Resampler) {
+	w.str(fmt.Sprintf("%T", rsm))
+	switch rsm := rsm.(type) {
+	case ResamplerAuto:
+		w.int(int64(rsm.inRate))
+		w.int(int64(rsm.outRate))
+		writeResampler(w, rsm.Resampler)
+	case resamplerChannels:
+		w.int(int64(len(rsm.rsms)))
+		for _, chRsm := range rsm.rsms {
+			writeResampler(w, chRsm)
+		}
+	case ResamplerSpline:
+		for _, x := range []int{rsm.inRate, rsm.outRate, rsm.batchInAmt, rsm.batchOutAmt} {
+			w.int(int64(x))
+		}
+	case *ResamplerFFT:
+		w.int(int64(rsm.inRate))
+		w.int(int64(rsm.outRate))
+		w.int(int64(len(rsm.batchSzs)))
+		for _, bSz := range rsm.batchSzs {
+			w.int(bSz.sz)
+			w.float(bSz.diff)
+		}
+	default:
+		for _, st := range stateOf(rsm) {
+			w.int32s(st)
+		}
+	}
+}
+
+// readResampler restores config and state of rsm written by writeResampler
+//
+// returns updated rsm as value resamplers can't be changed in place
+func readResampler(r *snapshotReader, rsm Resampler) Resampler {
+	if tp := r.str(); r.err == nil && tp != fmt.Sprintf("%T", rsm) {
+		r.fail("got resampler %s, want %T", tp, rsm)
+	}
+	if r.err != nil {
+		return rsm
+	}
+
+	switch rsm := rsm.(type) {
+	case ResamplerAuto:
+		r.expect("in rate", int64(rsm.inRate))
+		r.expect("out rate", int64(rsm.outRate))
+		rsm.Resampler = readResampler(r, rsm.Resampler)
+		return rsm
+	case resamplerChannels:
+		r.expect("channels amt", int64(len(rsm.rsms)))
+		for ch := range rsm.rsms {
+			chRsm := readResampler(r, rsm.rsms[ch])
+			if !r.dry {
+				rsm.rsms[ch] = chRsm
+			}
+		}
+		return rsm
+	case ResamplerSpline:
+		r.expect("in rate", int64(rsm.inRate))
+		r.expect("out rate", int64(rsm.outRate))
+		batchInAmt, batchOutAmt := r.int(), r.int()
+		if r.err == nil && (batchInAmt <= 0 || batchOutAmt <= 0) {
+			r.fail("got spline batch %d -> %d", batchInAmt, batchOutAmt)
+		}
+		if r.err == nil {
+			rsm.batchInAmt, rsm.batchOutAmt = int(batchInAmt), int(batchOutAmt)
+		}
+		return rsm
+	case *ResamplerFFT:
+		r.expect("in rate", int64(rsm.inRate))
+		r.expect("out rate", int64(rsm.outRate))
+		batchSzs := make([]batchSzWithDiff, r.sliceLen(9)) // at least 1 byte of varint + 8 of float
+		for i := range batchSzs {
+			batchSzs[i] = batchSzWithDiff{r.int(), r.float()}
+		}
+		if r.err == nil && !r.dry {
+			rsm.batchSzs = batchSzs
+		}
+		return rsm
+	default:
+		for _, st := range stateOf(rsm) {
+			r.int32sTo(st)
+		}
+		return rsm
+	}
+}
+
+// MarshalBinary saves buffered samples and state of ResampleBatch (with state of its resamplers)
+//
+// restore it with UnmarshalBinary to ResampleBatch created same way - it continues resampling exactly as this one
+func (rsm *ResampleBatch) MarshalBinary() ([]byte, error) {
+	w := snapshotWriter{[]byte(snapshotMagic)}
+	w.int(snapshotVersion)
+
+	in, out := make([]int16, rsm.in.Len()), make([]int16, rsm.out.Len())
+	rsm.in.copyTo(in)
+	rsm.out.copyTo(out)
+	w.int16s(in)
+	w.int16s(out)
+
+	writeResampler(&w, rsm.rsm)
+	writeResampler(&w, rsm.rsmTails)
+	w.int(int64(rsm.chAmt))
+
+	pt := rsm.pts
+	for _, x := range []int64{int64(pt.inRate), int64(pt.outRate), pt.inAdded, pt.inUsed, pt.outMade, pt.outGot} {
+		w.int(x)
+	}
+	w.float(pt.latency)
+	w.int(int64(len(pt.anchors)))
+	for _, a := range pt.anchors {
+		w.int(a.inPos)
+		w.int(int64(a.pts))
+	}
+	w.int(int64(len(pt.segments)))
+	for _, s := range pt.segments {
+		w.int(s.outPos)
+		w.float(s.inPos)
+	}
+
+	w.int(int64(rsm.gap.fadeAmt))
+	w.int16s(rsm.gap.history)
+	w.int(int64(rsm.gap.fadeInLeft))
+	return w.buf, nil
+}
+
+// UnmarshalBinary restores ResampleBatch from snapshot got by MarshalBinary
+//
+// rsm must be created with same constructor params (rates, resampler type and options) as saved one,
+// otherwise error matching ErrIncorrectSnapshot is returned
+// ErrBufferFull is returned if bounded buffers can't fit saved samples
+// rsm is not changed on error
+func (rsm *ResampleBatch) UnmarshalBinary(data []byte) error {
+	if _, err := rsm.readSnapshot(data, true); err != nil { // check all snapshot first not to break rsm
+		return err
+	}
+	res, err := rsm.readSnapshot(data, false)
+	if err != nil {
+		return err
+	}
+	*rsm = res
+	return nil
+}
+
+// readSnapshot returns rsm restored from data (if dry - only checks data, buffers and resamplers state stay same)
+func (rsm ResampleBatch) readSnapshot(data []byte, dry bool) (ResampleBatch, error) {
+	if len(data) < len(snapshotMagic) || string(data[:len(snapshotMagic)]) != snapshotMagic {
+		return rsm, fmt.Errorf("%w: not a ResampleBatch snapshot", ErrIncorrectSnapshot)
+	}
+	r := snapshotReader{data: data[len(snapshotMagic):], dry: dry}
+	r.expect("version", snapshotVersion)
+	in, out := r.int16s(), r.int16s()
+
+	rsm.rsm = readResampler(&r, rsm.rsm)
+	if rsmTails, ok := readResampler(&r, rsm.rsmTails).(ResamplerSpline); ok {
+		rsm.rsmTails = rsmTails
+	}
+	r.expect("channels amt", int64(rsm.chAmt))
+
+	pt := ptsTracker{chAmt: rsm.chAmt}
+	pt.inRate, pt.outRate = int(r.int()), int(r.int())
+	pt.inAdded, pt.inUsed, pt.outMade, pt.outGot = r.int(), r.int(), r.int(), r.int()
+	pt.latency = r.float()
+	pt.anchors = make([]ptsAnchor, r.sliceLen(2))
+	for i := range pt.anchors {
+		pt.anchors[i] = ptsAnchor{r.int(), time.Duration(r.int())}
+	}
+	pt.segments = make([]ptsSegment, r.sliceLen(9))
+	for i := range pt.segments {
+		pt.segments[i] = ptsSegment{r.int(), r.float()}
+	}
+	if r.err == nil && (pt.inRate != rsm.pts.inRate || pt.outRate != rsm.pts.outRate || len(pt.segments) == 0) {
+		r.fail("broken pts state")
+	}
+	rsm.pts = pt
+
+	r.expect("gap fade amt", int64(rsm.gap.fadeAmt))
+	rsm.gap = gapState{fadeAmt: rsm.gap.fadeAmt, history: r.int16s(), fadeInLeft: int(r.int())}
+	if r.err == nil && len(r.data) != 0 {
+		r.fail("%d extra bytes", len(r.data))
+	}
+	if r.err != nil {
+		return rsm, r.err
+	}
+
+	if dry {
+		if (rsm.in.maxSize != 0 && len(in) > rsm.in.maxSize) || (rsm.out.maxSize != 0 && len(out) > rsm.out.maxSize) {
+			return rsm, ErrBufferFull
+		}
+		return rsm, nil
+	}
+	rsm.in.reset()
+	rsm.out.reset()
+	if err := rsm.in.write(in); err != nil {
+		return rsm, err
+	}
+	return rsm, rsm.out.write(out)
+}
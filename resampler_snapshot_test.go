@@ -0,0 +1,125 @@
+package goresampler_test
+
+import (
+	"encoding"
+	"fmt"
+	"testing"
+	"time"
+
+	goresampler "github.com/lehatrutenb/goresampler"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var _ encoding.BinaryMarshaler = &goresampler.ResampleBatch{}
+var _ encoding.BinaryUnmarshaler = &goresampler.ResampleBatch{}
+
+// continueBatch adds rest of in by chunks and gets all output by frames (and tail), returns output and pts of frames
+func continueBatch(t *testing.T, rsm *goresampler.ResampleBatch, in []int16, chunkSize, frameSize int) ([]int16, []time.Duration) {
+	var out []int16
+	var ptss []time.Duration
+	frame := make([]int16, frameSize)
+	for l := 0; l < len(in); l += chunkSize {
+		assert.NoError(t, rsm.AddBatch(in[l:min(l+chunkSize, len(in))]))
+		for {
+			pts, err := rsm.GetBatchAt(frame)
+			if err != nil {
+				break
+			}
+			out, ptss = append(out, frame...), append(ptss, pts)
+		}
+	}
+	assert.NoError(t, rsm.ResampleAllInBuf())
+	return append(out, getAll(t, rsm)...), ptss
+}
+
+// resampling after restore must be same as without it
+func TestResampleBatch_MarshalBinary(t *testing.T) {
+	tests := []struct {
+		inRate, outRate int
+		opts            []goresampler.Option
+	}{
+		{48000, 8000, nil},
+		{48000, 16000, nil},
+		{11000, 16000, []goresampler.Option{goresampler.WithType(goresampler.ResamplerConstExprT), goresampler.WithChannels(2)}},
+		{44000, 8000, []goresampler.Option{goresampler.WithType(goresampler.ResamplerConstExprT)}},
+		{44100, 16000, []goresampler.Option{goresampler.WithStrictRates(false)}},
+		{48000, 16000, []goresampler.Option{goresampler.WithQuality(goresampler.QualityBest)}},
+		{16000, 16000, nil},
+	}
+	for _, tt := range tests {
+		newBatch := func() goresampler.ResampleBatch {
+			rsmIns, _, err := goresampler.New(tt.inRate, tt.outRate, tt.opts...)
+			assert.NoError(t, err)
+			return goresampler.NewResampleBatch(rsmIns, tt.inRate, tt.outRate)
+		}
+		in := goldenInWave(tt.inRate, tt.inRate)
+		half := len(in) / 2 / 2 * 2
+
+		rsm := newBatch()
+		assert.NoError(t, rsm.AddBatchAt(in[:7], time.Hour))
+		continueBatch(t, &rsm, in[7:half], 333, 160)
+		assert.NoError(t, rsm.AddBatch(in[half:half+1000]))
+		assert.NoError(t, rsm.GetBatch(make([]int16, 10)))
+
+		data, err := rsm.MarshalBinary()
+		if !assert.NoError(t, err) {
+			continue
+		}
+		want, wantPts := continueBatch(t, &rsm, in[half+1000:], 333, 160)
+
+		restored := newBatch()
+		assert.NoError(t, restored.AddBatch(in[:500])) // restore must overwrite all
+		if !assert.NoError(t, restored.UnmarshalBinary(data)) {
+			continue
+		}
+		got, gotPts := continueBatch(t, &restored, in[half+1000:], 333, 160)
+		assert.Equal(t, want, got, "%d -> %d", tt.inRate, tt.outRate)
+		assert.Equal(t, wantPts, gotPts, "%d -> %d", tt.inRate, tt.outRate)
+	}
+}
+
+func TestResampleBatch_UnmarshalBinaryErrors(t *testing.T) {
+	rsmIns, _, _ := goresampler.New(48000, 16000)
+	rsm := goresampler.NewResampleBatch(rsmIns, 48000, 16000)
+	assert.NoError(t, rsm.AddBatch(goldenInWave(48000, 1000)))
+	assert.NoError(t, rsm.GetBatch(make([]int16, 100)))
+	data, _ := rsm.MarshalBinary()
+
+	rsmIns2, _, _ := goresampler.New(48000, 8000)
+	other := goresampler.NewResampleBatch(rsmIns2, 48000, 8000)
+	assert.ErrorIs(t, other.UnmarshalBinary(data), goresampler.ErrIncorrectSnapshot)
+
+	rsmIns3, _, _ := goresampler.New(48000, 16000)
+	target := goresampler.NewResampleBatch(rsmIns3, 48000, 16000)
+	assert.NoError(t, target.AddBatch(goldenInWave(16000, 777)))
+	before, _ := target.MarshalBinary()
+	for l := 0; l < len(data); l++ { // broken snapshot must not panic or change target
+		assert.ErrorIs(t, target.UnmarshalBinary(data[:l]), goresampler.ErrIncorrectSnapshot, "len %d", l)
+		after, _ := target.MarshalBinary()
+		assert.Equal(t, before, after)
+	}
+	assert.ErrorIs(t, target.UnmarshalBinary(append(data, 0)), goresampler.ErrIncorrectSnapshot)
+
+	rsmIns4, _, _ := goresampler.New(48000, 16000)
+	bounded, err := goresampler.NewResampleBatchBounded(rsmIns4, 48000, 16000, 500, 0) // above min batch (480), below buffered input of snapshot
+	assert.NoError(t, err)
+	assert.ErrorIs(t, bounded.UnmarshalBinary(data), goresampler.ErrBufferFull)
+}
+
+func ExampleResampleBatch_MarshalBinary() {
+	rsmIns, _, _ := goresampler.New(48000, 16000)
+	rsm := goresampler.NewResampleBatch(rsmIns, 48000, 16000)
+	_ = rsm.AddBatch(make([]int16, 1000))
+	data, _ := rsm.MarshalBinary() // save checkpoint
+
+	rsmIns, _, _ = goresampler.New(48000, 16000) // after restart
+	restored := goresampler.NewResampleBatch(rsmIns, 48000, 16000)
+	if err := restored.UnmarshalBinary(data); err != nil {
+		fmt.Println(err)
+		return
+	}
+	unresampled, _ := restored.UnresampledUngetInAmt()
+	fmt.Println(unresampled)
+	// Output: 1000
+}
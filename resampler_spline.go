@@ -198,7 +198,29 @@ func (sw *ResamplerSpline) calcSpline() spline {
 	return spline{}.new(sw.in, float64(sw.inRate), sw.bc)
 }
 
+// fillTooShort fills outs with the only input sample (or silence) cause spline needs at least 2 points
+//
+// returns false if in is long enough to build spline
+func fillTooShort(in []int16, outs ...[]int16) bool {
+	if len(in) >= 2 {
+		return false
+	}
+	var v int16
+	if len(in) == 1 {
+		v = in[0]
+	}
+	for _, out := range outs {
+		for i := range out {
+			out[i] = v
+		}
+	}
+	return true
+}
+
 func (sw ResamplerSpline) ResampleAll(in, out []int16) error {
+	if fillTooShort(in, out) {
+		return nil
+	}
 	sw.preResample(in, len(out))
 	sw.resample(sw.calcSpline())
 	sw.postResample(out)
@@ -208,8 +230,11 @@ func (sw ResamplerSpline) ResampleAll(in, out []int16) error {
 func (sw ResamplerSpline) Resample(in, out []int16) error {
 	{
 		cIn, cOut := sw.CalcInOutSamplesPerOutAmt(len(out))
-		if cIn != len(in) || cOut != len(out) {
-			return ErrIncorrectInLen
+		if cIn != len(in) {
+			return &LengthError{"in", len(in), cIn}
+		}
+		if cOut != len(out) {
+			return &LengthError{"out", len(out), cOut}
 		}
 	}
 
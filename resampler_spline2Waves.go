@@ -20,8 +20,28 @@ func NewResamplerSpline2Waves(inRate, outRate1, outRate2 int, maxErrRateP *float
 	return ResamplerSpline2Waves{rsm1, rsm2}, ok1 && ok2
 }
 
+const maxCommonBatchInAmt = 1 << 16 // not to wait for too much input if batches of waves are badly compatible
+
+// commonBatchInAmt returns input amt that is whole amt of batches of both waves (lcm of them)
+//
+// if in is not aligned to it one of waves looses part of output sample (calcOutSamplesPerInAmt rounds down)
+// and that loss stacks with every resample
+func (sw ResamplerSpline2Waves) commonBatchInAmt() int {
+	a, b := sw.rsm1.batchInAmt, sw.rsm2.batchInAmt
+	for b != 0 {
+		a, b = b, a%b
+	}
+	res := sw.rsm1.batchInAmt / a * sw.rsm2.batchInAmt
+	if res > maxCommonBatchInAmt {
+		return 1
+	}
+	return res
+}
+
 func (sw ResamplerSpline2Waves) CalcNeedSamplesPerOutAmt(outAmt1, outAmt2 int) int {
-	return max(sw.rsm1.CalcNeedSamplesPerOutAmt(outAmt1), sw.rsm2.CalcNeedSamplesPerOutAmt(outAmt2))
+	in := max(sw.rsm1.CalcNeedSamplesPerOutAmt(outAmt1), sw.rsm2.CalcNeedSamplesPerOutAmt(outAmt2))
+	bIn := sw.commonBatchInAmt()
+	return ((in + bIn - 1) / bIn) * bIn
 }
 
 // not really need so strict - like inAmt % sw.batchInAmt == 0 , but it's garanted
@@ -30,13 +50,14 @@ func (sw ResamplerSpline2Waves) calcOutSamplesPerInAmt(inAmt int) (int, int) {
 }
 
 func (rsm ResamplerSpline2Waves) CalcInOutSamplesPerOutAmt(outAmt1, outAmt2 int) (int, int, int) {
-	in1 := rsm.rsm1.CalcNeedSamplesPerOutAmt(outAmt1)
-	in2 := rsm.rsm2.CalcNeedSamplesPerOutAmt(outAmt2)
-	in := max(in1, in2)
+	in := rsm.CalcNeedSamplesPerOutAmt(outAmt1, outAmt2)
 	return in, rsm.rsm1.calcOutSamplesPerInAmt(in), rsm.rsm2.calcOutSamplesPerInAmt(in)
 }
 
 func (sw ResamplerSpline2Waves) ResampleAll(in, out1, out2 []int16) error {
+	if fillTooShort(in, out1, out2) {
+		return nil
+	}
 	sw.rsm1.preResample(in, len(out1))
 	sw.rsm2.preResample(in, len(out2))
 	spl := sw.rsm1.calcSpline()
@@ -50,8 +71,14 @@ func (sw ResamplerSpline2Waves) ResampleAll(in, out1, out2 []int16) error {
 func (sw ResamplerSpline2Waves) Resample(in, out1, out2 []int16) error {
 	{
 		cIn, cOut1, cOut2 := sw.CalcInOutSamplesPerOutAmt(len(out1), len(out2))
-		if cIn != len(in) || cOut1 != len(out1) || cOut2 != len(out2) {
-			return ErrIncorrectInLen
+		if cIn != len(in) {
+			return &LengthError{"in", len(in), cIn}
+		}
+		if cOut1 != len(out1) {
+			return &LengthError{"out1", len(out1), cOut1}
+		}
+		if cOut2 != len(out2) {
+			return &LengthError{"out2", len(out2), cOut2}
 		}
 	}
 
@@ -2,6 +2,7 @@ package goresampler_test
 
 import (
 	"errors"
+	"path/filepath"
 	"sync"
 	"testing"
 
@@ -124,7 +125,7 @@ func TestResampleSpline11025To8_SinWave(t *testing.T) {
 		}
 	}()
 	rsm := resamplerSpline{}.New(inRate, outRate, nil)
-	var tObj testutils.TestObj = testutils.TestObj{}.New(testutils.CutWave{}.New(testutils.SinWave{}.New(0, waveDurS, inRate, outRate), 0, rsm.calcNeedSamplesPerOutAmt((int(waveDurS)-10)*outRate)), &rsm, 1, t, testutils.TestOpts{}.NewDefault())
+	var tObj testutils.TestObj = testutils.TestObj{}.New(testutils.CutWave{}.New(testutils.SinWave{}.New(0, waveDurS, inRate, outRate), 0, rsm.calcNeedSamplesPerOutAmt((int(waveDurS)-10)*outRate)), &rsm, 1, t, testutils.TestOpts{}.NewDefault().WithPlots(true))
 	err := tObj.Run()
 	if !assert.NoError(t, err, "failed to run resampler") {
 		t.Error(err)
@@ -133,6 +134,8 @@ func TestResampleSpline11025To8_SinWave(t *testing.T) {
 	if !assert.NoError(t, err, "failed to save test results") {
 		t.Error(err)
 	}
+	plotFiles, _ := filepath.Glob(fmt.Sprintf("%s/%s/rsm_spline/*11025*8000*.png", testutils.SAVE_PATH, testutils.PLOTS_SUFFIX))
+	assert.NotEmpty(t, plotFiles, "plots are drawn by test")
 }
 
 func TestResampleSpline16To8_SinWave(t *testing.T) {
@@ -0,0 +1,238 @@
+package goresampler
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// streamChunkSize is amt of bytes Reader reads from its source at once (rounded to full frames)
+const streamChunkSize = 1 << 14
+
+// maxConsecutiveEmptyReads is amt of (0, nil) reads of source after that Reader returns io.ErrNoProgress (like bufio does)
+const maxConsecutiveEmptyReads = 100
+
+// Reader reads wave stored as bytes in inFormat from src and gives it resampled by ResampleBatch as bytes in outFormat
+//
+// not full sample (frame of all channels) at the end of src gives io.ErrUnexpectedEOF after all other samples are read
+type Reader struct {
+	src       io.Reader
+	rsm       ResampleBatch
+	inFormat  SampleFormat
+	outFormat SampleFormat
+	chunk     []byte
+	raw       []byte // read from src, not yet decoded (not full frame)
+	pending   []byte // encoded output, not yet read
+	samples   []int16
+	err       error // error to return after pending (io.EOF at the end of src)
+	emptyAmt  int   // consecutive reads of src that gave nothing
+}
+
+// NewReader returns Reader of src that resamples it by rsm
+//
+// returns ErrUnexpSampleFormat if formats are not one of SampleFormat consts
+func NewReader(src io.Reader, rsm ResampleBatch, inFormat, outFormat SampleFormat) (*Reader, error) {
+	if inFormat.Size() == 0 || outFormat.Size() == 0 {
+		return nil, ErrUnexpSampleFormat
+	}
+	return &Reader{src: src, rsm: rsm, inFormat: inFormat, outFormat: outFormat}, nil
+}
+
+// Read implements io.Reader ; it reads src till it gets at least one resampled sample
+//
+// at the end of src rest of input is resampled by ResampleAllInBuf
+// ; io.ErrNoProgress is returned if src gives no data and no error many times in a row
+func (r *Reader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.fill()
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// fill reads next chunk of src and resamples it to pending
+func (r *Reader) fill() {
+	if r.chunk == nil {
+		frameSize := r.rsm.inChAmt() * r.inFormat.Size()
+		r.chunk = make([]byte, streamChunkSize/frameSize*frameSize+frameSize)
+	}
+	n, err := r.src.Read(r.chunk)
+	if n == 0 && err == nil {
+		if r.emptyAmt++; r.emptyAmt >= maxConsecutiveEmptyReads {
+			r.err = io.ErrNoProgress
+		}
+		return
+	}
+	r.emptyAmt = 0
+	r.raw = append(r.raw, r.chunk[:n]...)
+
+	if r.err = r.rsm.addBytes(&r.raw, r.inFormat, &r.samples); r.err != nil {
+		return
+	}
+	if r.err = r.rsm.resampleBatches(context.Background(), func() {}); r.err != nil {
+		return
+	}
+	if errors.Is(err, io.EOF) {
+		if r.err = r.rsm.ResampleAllInBuf(); r.err != nil {
+			return
+		}
+		r.err = io.EOF
+		if len(r.raw) != 0 {
+			r.err = io.ErrUnexpectedEOF
+		}
+	} else if err != nil {
+		r.err = err
+	}
+	if r.pending, err = r.rsm.getBytes(r.pending, r.outFormat, &r.samples); err != nil {
+		r.err = err
+	}
+}
+
+// Writer resamples wave written to it as bytes in inFormat by ResampleBatch and writes result to dst as bytes in outFormat
+//
+// call Close at the end of wave to write rest of it
+type Writer struct {
+	dst       io.Writer
+	rsm       ResampleBatch
+	inFormat  SampleFormat
+	outFormat SampleFormat
+	raw       []byte // written, not yet decoded (not full frame)
+	encoded   []byte
+	samples   []int16
+	closed    bool
+}
+
+// NewWriter returns Writer to dst that resamples by rsm
+//
+// returns ErrUnexpSampleFormat if formats are not one of SampleFormat consts
+func NewWriter(dst io.Writer, rsm ResampleBatch, inFormat, outFormat SampleFormat) (*Writer, error) {
+	if inFormat.Size() == 0 || outFormat.Size() == 0 {
+		return nil, ErrUnexpSampleFormat
+	}
+	return &Writer{dst: dst, rsm: rsm, inFormat: inFormat, outFormat: outFormat}, nil
+}
+
+// Write implements io.Writer ; p can end with not full sample - it's kept till next Write
+//
+// resampled samples are written to dst at once (except ones resampler needs more input for)
+//
+// p is consumed (len(p) is returned) even if resampling it or writing to dst fails after that - don't write it again
+func (w *Writer) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, ErrBatchClosed
+	}
+	w.raw = append(w.raw, p...)
+	if err := w.rsm.addBytes(&w.raw, w.inFormat, &w.samples); err != nil { // nothing is added
+		w.raw = w.raw[:len(w.raw)-len(p)]
+		return 0, err
+	}
+	if err := w.rsm.resampleBatches(context.Background(), func() {}); err != nil {
+		return len(p), err
+	}
+	if err := w.flush(); err != nil {
+		return len(p), err
+	}
+	return len(p), nil
+}
+
+// Close resamples rest of written wave (see ResampleAllInBuf) and writes it to dst (dst is not closed)
+//
+// returns io.ErrUnexpectedEOF if not full sample was written
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if err := w.rsm.ResampleAllInBuf(); err != nil {
+		return err
+	}
+	if err := w.flush(); err != nil {
+		return err
+	}
+	if len(w.raw) != 0 {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+// flush writes all resampled samples to dst
+func (w *Writer) flush() error {
+	var err error
+	if w.encoded, err = w.rsm.getBytes(w.encoded[:0], w.outFormat, &w.samples); err != nil {
+		return err
+	}
+	if len(w.encoded) == 0 {
+		return nil
+	}
+	_, err = w.dst.Write(w.encoded)
+	return err
+}
+
+// Convert resamples wave read from src as bytes in inFormat from inRate to outRate and writes it to dst
+// as bytes in outFormat, returns amt of written bytes
+//
+// resampler is configured by opts like in New:
+//
+//	Convert(dst, src, 8000, 16000, SampleFormatMuLaw, SampleFormatS16LE) // G.711 telephony to PCM for ASR
+func Convert(dst io.Writer, src io.Reader, inRate, outRate int, inFormat, outFormat SampleFormat, opts ...Option) (int64, error) {
+	rsmIns, _, err := New(inRate, outRate, opts...)
+	if err != nil {
+		return 0, err
+	}
+	r, err := NewReader(src, NewResampleBatch(rsmIns, inRate, outRate), inFormat, outFormat)
+	if err != nil {
+		return 0, err
+	}
+	return io.Copy(dst, r)
+}
+
+// inChAmt returns amt of channels of input added to rsm
+func (rsm *ResampleBatch) inChAmt() int {
+	if rsm.layout != nil {
+		return rsm.layout.inChAmt()
+	}
+	return rsm.chAmt
+}
+
+// addBytes decodes full frames of raw in format f and adds them, rest of raw (not full frame) is moved to its start
+//
+// samples is buffer to decode to (it's grown if need)
+func (rsm *ResampleBatch) addBytes(raw *[]byte, f SampleFormat, samples *[]int16) error {
+	frameSize := rsm.inChAmt() * f.Size()
+	amt := len(*raw) / frameSize * frameSize / f.Size()
+	if amt == 0 {
+		return nil
+	}
+	if cap(*samples) < amt {
+		*samples = make([]int16, amt)
+	}
+	if err := f.Decode((*samples)[:amt], (*raw)[:amt*f.Size()]); err != nil {
+		return err
+	}
+	if err := rsm.AddBatch((*samples)[:amt]); err != nil {
+		return err
+	}
+	*raw = (*raw)[:copy(*raw, (*raw)[amt*f.Size():])]
+	return nil
+}
+
+// getBytes gets all resampled samples, encodes them in format f and appends to dst
+func (rsm *ResampleBatch) getBytes(dst []byte, f SampleFormat, samples *[]int16) ([]byte, error) {
+	amt := rsm.Len()
+	if amt == 0 {
+		return dst, nil
+	}
+	if cap(*samples) < amt {
+		*samples = make([]int16, amt)
+	}
+	if err := rsm.GetBatch((*samples)[:amt]); err != nil {
+		return dst, err
+	}
+	l := len(dst)
+	dst = append(dst, make([]byte, amt*f.Size())...)
+	return dst, f.Encode(dst[l:], (*samples)[:amt])
+}
@@ -0,0 +1,167 @@
+package goresampler_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	goresampler "github.com/lehatrutenb/goresampler"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func encodeWave(t *testing.T, f goresampler.SampleFormat, wave []int16) []byte {
+	res := make([]byte, len(wave)*f.Size())
+	assert.NoError(t, f.Encode(res, wave))
+	return res
+}
+
+// reading and writing by any chunks must give same as ResampleAll of decoded wave
+func TestReaderWriter(t *testing.T) {
+	for _, tt := range []struct {
+		inRate, outRate     int
+		inFormat, outFormat goresampler.SampleFormat
+		opts                []goresampler.Option
+	}{
+		{8000, 16000, goresampler.SampleFormatMuLaw, goresampler.SampleFormatS16LE, nil},
+		{16000, 8000, goresampler.SampleFormatS16LE, goresampler.SampleFormatALaw, nil},
+		{48000, 16000, goresampler.SampleFormatF32LE, goresampler.SampleFormatS16BE, []goresampler.Option{goresampler.WithChannels(2)}},
+		{44100, 16000, goresampler.SampleFormatS16BE, goresampler.SampleFormatMuLaw, []goresampler.Option{goresampler.WithQuality(goresampler.QualityBest)}},
+		{48000, 8000, goresampler.SampleFormatS16LE, goresampler.SampleFormatS16LE, []goresampler.Option{goresampler.WithChannels(2), goresampler.WithOutChannels(1)}},
+	} {
+		in := goldenInWave(tt.inRate, 3*tt.inRate*2)
+		inB := encodeWave(t, tt.inFormat, in)
+		decoded := make([]int16, len(in))
+		assert.NoError(t, tt.inFormat.Decode(decoded, inB))
+		want, err := goresampler.ResampleAll(context.Background(), decoded, tt.inRate, tt.outRate, tt.opts...)
+		if !assert.NoError(t, err) {
+			continue
+		}
+		wantB := encodeWave(t, tt.outFormat, want)
+
+		newBatch := func() goresampler.ResampleBatch {
+			rsmIns, _, _ := goresampler.New(tt.inRate, tt.outRate, tt.opts...)
+			return goresampler.NewResampleBatch(rsmIns, tt.inRate, tt.outRate)
+		}
+		for _, src := range []io.Reader{bytes.NewReader(inB), iotest.HalfReader(bytes.NewReader(inB)), iotest.DataErrReader(bytes.NewReader(inB))} {
+			r, err := goresampler.NewReader(src, newBatch(), tt.inFormat, tt.outFormat)
+			if !assert.NoError(t, err) {
+				continue
+			}
+			got, err := io.ReadAll(r)
+			assert.NoError(t, err)
+			assert.Equal(t, wantB, got, "reader %d -> %d", tt.inRate, tt.outRate)
+		}
+
+		var dst bytes.Buffer
+		w, err := goresampler.NewWriter(&dst, newBatch(), tt.inFormat, tt.outFormat)
+		if !assert.NoError(t, err) {
+			continue
+		}
+		for l := 0; l < len(inB); l += 1001 { // not full samples
+			n, err := w.Write(inB[l:min(l+1001, len(inB))])
+			assert.NoError(t, err)
+			assert.Equal(t, min(1001, len(inB)-l), n)
+		}
+		assert.NoError(t, w.Close())
+		assert.NoError(t, w.Close())
+		assert.Equal(t, wantB, dst.Bytes(), "writer %d -> %d", tt.inRate, tt.outRate)
+		_, err = w.Write(inB[:2])
+		assert.ErrorIs(t, err, goresampler.ErrBatchClosed)
+	}
+}
+
+// emptyReader never gives data or error
+type emptyReader struct{}
+
+func (emptyReader) Read([]byte) (int, error) {
+	return 0, nil
+}
+
+var errTestWrite = errors.New("test write error")
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errTestWrite
+}
+
+func TestReaderWriter_Errors(t *testing.T) {
+	rsmIns, _, _ := goresampler.New(8000, 16000)
+	_, err := goresampler.NewReader(bytes.NewReader(nil), goresampler.NewResampleBatch(rsmIns, 8000, 16000), 42, goresampler.SampleFormatS16LE)
+	assert.ErrorIs(t, err, goresampler.ErrUnexpSampleFormat)
+	_, err = goresampler.NewWriter(io.Discard, goresampler.NewResampleBatch(rsmIns, 8000, 16000), goresampler.SampleFormatS16LE, 42)
+	assert.ErrorIs(t, err, goresampler.ErrUnexpSampleFormat)
+
+	r, _ := goresampler.NewReader(bytes.NewReader(make([]byte, 161)), goresampler.NewResampleBatch(rsmIns, 8000, 16000), goresampler.SampleFormatS16LE, goresampler.SampleFormatS16LE)
+	got, err := io.ReadAll(r)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.Len(t, got, 2*160, "full samples are read before error")
+
+	r, _ = goresampler.NewReader(iotest.TimeoutReader(bytes.NewReader(make([]byte, 1600))), goresampler.NewResampleBatch(rsmIns, 8000, 16000), goresampler.SampleFormatS16LE, goresampler.SampleFormatS16LE)
+	_, err = io.ReadAll(r)
+	assert.ErrorIs(t, err, iotest.ErrTimeout)
+
+	r, _ = goresampler.NewReader(emptyReader{}, goresampler.NewResampleBatch(rsmIns, 8000, 16000), goresampler.SampleFormatS16LE, goresampler.SampleFormatS16LE)
+	_, err = io.ReadAll(r)
+	assert.ErrorIs(t, err, io.ErrNoProgress)
+
+	w, _ := goresampler.NewWriter(io.Discard, goresampler.NewResampleBatch(rsmIns, 8000, 16000), goresampler.SampleFormatS16LE, goresampler.SampleFormatS16LE)
+	_, err = w.Write(make([]byte, 3))
+	assert.NoError(t, err)
+	assert.ErrorIs(t, w.Close(), io.ErrUnexpectedEOF)
+
+	// input added to batch is consumed even if dst fails
+	w, _ = goresampler.NewWriter(failingWriter{}, goresampler.NewResampleBatch(rsmIns, 8000, 16000), goresampler.SampleFormatS16LE, goresampler.SampleFormatS16LE)
+	n, err := w.Write(make([]byte, 1600))
+	assert.ErrorIs(t, err, errTestWrite)
+	assert.Equal(t, 1600, n)
+
+	bounded, _ := goresampler.NewResampleBatchBounded(rsmIns, 8000, 16000, 100, 0)
+	w, _ = goresampler.NewWriter(io.Discard, bounded, goresampler.SampleFormatS16LE, goresampler.SampleFormatS16LE)
+	n, err = w.Write(make([]byte, 402))
+	assert.ErrorIs(t, err, goresampler.ErrBufferFull)
+	assert.Equal(t, 0, n, "nothing is consumed if input doesn't fit")
+
+	_, err = goresampler.Convert(io.Discard, bytes.NewReader(nil), 8000, 7, goresampler.SampleFormatS16LE, goresampler.SampleFormatS16LE)
+	assert.ErrorIs(t, err, goresampler.ErrUnexpResRate)
+}
+
+// G.711 telephony - to 16000 PCM and back must keep wave
+func TestConvert_G711(t *testing.T) {
+	in := sinWaveAt(8000, 8000, 440, 0)
+	inB := encodeWave(t, goresampler.SampleFormatMuLaw, in)
+
+	var pcm, back bytes.Buffer
+	n, err := goresampler.Convert(&pcm, bytes.NewReader(inB), 8000, 16000, goresampler.SampleFormatMuLaw, goresampler.SampleFormatS16LE)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2*16000), n)
+	_, err = goresampler.Convert(&back, &pcm, 16000, 8000, goresampler.SampleFormatS16LE, goresampler.SampleFormatMuLaw)
+	assert.NoError(t, err)
+	if !assert.Equal(t, len(inB), back.Len()) {
+		return
+	}
+
+	got := make([]int16, len(in))
+	assert.NoError(t, goresampler.SampleFormatMuLaw.Decode(got, back.Bytes()))
+	delay := 3*time.Second/16000 + time.Second/8000 // of 8000 -> 16000 and 16000 -> 8000 resamplers
+	want := sinWaveAt(8000, len(in), 440, -delay)
+	assert.Less(t, maxDiff(want[100:len(in)-100], got[100:len(in)-100]), 700.0) // g711 is coded twice
+}
+
+func ExampleConvert() {
+	g711 := bytes.NewReader(make([]byte, 160)) // 20ms of mu-law 8000 wave
+	var pcm bytes.Buffer
+	n, err := goresampler.Convert(&pcm, g711, 8000, 16000, goresampler.SampleFormatMuLaw, goresampler.SampleFormatS16LE)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(n) // 320 s16le samples of 16000 wave
+	// Output: 640
+}